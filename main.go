@@ -2,8 +2,10 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"net/http"
 
+	"github.com/EDDYCJY/go-gin-example/models"
 	"github.com/EDDYCJY/go-gin-example/pkg/setting"
 	"github.com/EDDYCJY/go-gin-example/routers"
 )
@@ -11,6 +13,14 @@ import (
 func main() {
 	router := routers.InitRouter()
 
+	// 上次运行中断的迁移任务不会再完成
+	if err := models.RecoverDnsMigrations(); err != nil {
+		log.Printf("更新中断的迁移任务失败: %v", err)
+	}
+
+	// 定时检查数据库记录与服务商是否一致
+	models.StartDnsDriftCheck(setting.DnsDriftCheckInterval)
+
 	s := &http.Server{
 		Addr:           fmt.Sprintf(":%d", setting.HTTPPort),
 		Handler:        router,
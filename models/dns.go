@@ -1,7 +1,16 @@
 package models
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+	"github.com/EDDYCJY/go-gin-example/pkg/dns/validate"
 	"github.com/EDDYCJY/go-gin-example/pkg/setting"
 )
 
@@ -10,126 +19,377 @@ type DnsService struct {
 	Manager *dns.DnsManager
 }
 
-// NewDnsService 创建DNS服务实例
+var (
+	dnsManager     *dns.DnsManager
+	dnsManagerOnce sync.Once
+
+	// lineCache 各账号域名的线路列表，创建和修改记录时用于校验线路
+	lineCache = dns.NewLineCache(time.Hour)
+)
+
+// NewDnsService 创建DNS服务实例，各实例共享同一个DNS管理器
 func NewDnsService() *DnsService {
-	manager := dns.NewDnsManager(
-		setting.DnsPodToken,
-		setting.AliyunAccessKeyId,
-		setting.AliyunAccessKeySecret,
-		setting.AliyunRegionId,
-	)
+	dnsManagerOnce.Do(func() {
+		configs := make([]dns.Config, 0, len(setting.DnsProviders))
+		for _, p := range setting.DnsProviders {
+			configs = append(configs, dns.Config{
+				Name:    p.Name,
+				Type:    p.Type,
+				Options: p.Options,
+			})
+		}
+		dnsManager = dns.NewDnsManager(configs, setting.DnsDefaultProvider)
+	})
 	return &DnsService{
-		Manager: manager,
+		Manager: dnsManager,
+	}
+}
+
+// GetAccounts 获取已配置的服务商账号
+func (s *DnsService) GetAccounts() []dns.Account {
+	return s.Manager.Accounts()
+}
+
+// accountName 账号名称，为空时使用默认账号
+func (s *DnsService) accountName(provider string) string {
+	if provider == "" {
+		return s.Manager.DefaultName()
 	}
+	return provider
 }
 
 // GetDomainList 获取域名列表
-func (s *DnsService) GetDomainList() ([]dns.DnsDomain, error) {
-	// 如果配置了DNSPod，优先使用DNSPod
-	if s.Manager.UseDnsPod() {
-		return s.Manager.GetDnsPodDomainList()
+func (s *DnsService) GetDomainList(ctx context.Context, provider string) ([]dns.Domain, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+	domains, err := p.GetDomainList(ctx)
+	return domains, dns.ContextError(ctx, err)
+}
+
+// GetDomainPage 按页获取域名列表，keyword不为空时按域名搜索，同时返回符合条件的域名总数
+//
+// 服务商不支持分页时获取全部域名后在本地过滤和分页
+func (s *DnsService) GetDomainPage(ctx context.Context, keyword string, page, pageSize int, provider string) ([]dns.Domain, int, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+
+	if pager, ok := p.(dns.DomainPager); ok {
+		domains, total, err := pager.GetDomainPage(ctx, keyword, page, pageSize)
+		return domains, total, dns.ContextError(ctx, err)
+	}
+
+	domains, err := p.GetDomainList(ctx)
+	if err != nil {
+		return nil, 0, dns.ContextError(ctx, err)
 	}
-	// 否则尝试使用阿里云DNS
-	return nil, nil // 阿里云域名列表需要单独处理
+	if keyword != "" {
+		keyword = strings.ToLower(keyword)
+		matched := domains[:0]
+		for _, d := range domains {
+			if strings.Contains(strings.ToLower(d.Name), keyword) || strings.Contains(strings.ToLower(d.PunyCode), keyword) {
+				matched = append(matched, d)
+			}
+		}
+		domains = matched
+	}
+
+	total := len(domains)
+	start, end := pageBounds(total, page, pageSize)
+	return domains[start:end], total, nil
 }
 
 // GetRecordList 获取DNS记录列表
-func (s *DnsService) GetRecordList(domain, subDomain string, provider string) (interface{}, error) {
-	if provider == "aliyun" && s.Manager.UseAliyunDns() {
-		return s.Manager.GetAliyunRecordList(domain, subDomain)
+func (s *DnsService) GetRecordList(ctx context.Context, domain, subDomain string, provider string) ([]dns.Record, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, err
 	}
-	// 默认使用DNSPod
-	if s.Manager.UseDnsPod() {
-		return s.Manager.GetDnsPodRecordList(domain, subDomain)
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return nil, err
 	}
-	return nil, nil
+	if subDomain, err = dns.ToASCII(subDomain); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+	records, err := p.GetRecordList(ctx, domain, subDomain)
+	return records, dns.ContextError(ctx, err)
 }
 
-// CreateRecord 创建DNS记录
-func (s *DnsService) CreateRecord(domainID, subDomain, recordType, value, recordLine string, provider string) (interface{}, error) {
-	if provider == "aliyun" && s.Manager.UseAliyunDns() {
-		// 对于阿里云，domainID是域名名称，subDomain是RR值
-		return s.Manager.CreateAliyunRecord(domainID, subDomain, recordType, value, 600) // 默认TTL为600
+// GetRecordPage 按页获取DNS记录列表，page从1开始，同时返回记录总数
+//
+// 服务商不支持分页时获取全部记录后在本地分页
+func (s *DnsService) GetRecordPage(ctx context.Context, domain, subDomain string, page, pageSize int, provider string) ([]dns.Record, int, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, 0, err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return nil, 0, err
+	}
+	if subDomain, err = dns.ToASCII(subDomain); err != nil {
+		return nil, 0, err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+
+	if pager, ok := p.(dns.RecordPager); ok {
+		records, total, err := pager.GetRecordPage(ctx, domain, subDomain, page, pageSize)
+		return records, total, dns.ContextError(ctx, err)
 	}
-	// 默认使用DNSPod
-	if s.Manager.UseDnsPod() {
-		return s.Manager.CreateDnsPodRecord(domainID, subDomain, recordType, value, recordLine)
+
+	records, err := p.GetRecordList(ctx, domain, subDomain)
+	if err != nil {
+		return nil, 0, dns.ContextError(ctx, err)
 	}
-	return nil, nil
+	total := len(records)
+	start, end := pageBounds(total, page, pageSize)
+	return records[start:end], total, nil
 }
 
-// UpdateRecord 更新DNS记录
-func (s *DnsService) UpdateRecord(recordID, domainID, subDomain, recordType, value, recordLine string, provider string) (interface{}, error) {
-	if provider == "aliyun" && s.Manager.UseAliyunDns() {
-		return s.Manager.UpdateAliyunRecord(recordID, subDomain, recordType, value, 600) // 默认TTL为600
+// pageBounds 本地分页时第page页在全部结果中的起止下标
+func pageBounds(total, page, pageSize int) (int, int) {
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
 	}
-	// 默认使用DNSPod
-	if s.Manager.UseDnsPod() {
-		return s.Manager.UpdateDnsPodRecord(recordID, domainID, subDomain, recordType, value, recordLine)
+	end := start + pageSize
+	if end > total {
+		end = total
 	}
-	return nil, nil
+	return start, end
 }
 
-// DeleteRecord 删除DNS记录
-func (s *DnsService) DeleteRecord(recordID, domainID string, provider string) error {
-	if provider == "aliyun" && s.Manager.UseAliyunDns() {
-		return s.Manager.DeleteAliyunRecord(recordID)
+// GetLineList 获取域名可用的线路，结果会缓存一小时
+func (s *DnsService) GetLineList(ctx context.Context, domain string, provider string) ([]dns.Line, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return nil, err
 	}
-	// 默认使用DNSPod
-	if s.Manager.UseDnsPod() {
-		return s.Manager.DeleteDnsPodRecord(recordID, domainID)
+	lister, ok := p.(dns.LineLister)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s类型的账号没有线路", dns.ErrUnsupported, s.Manager.Type(provider))
 	}
-	return nil
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+	lines, err := lineCache.Get(ctx, s.accountName(provider), lister, domain)
+	return lines, dns.ContextError(ctx, err)
 }
 
-// SetRecordStatus 设置记录状态
-func (s *DnsService) SetRecordStatus(recordID, domainID, status string, provider string) error {
-	if provider == "aliyun" && s.Manager.UseAliyunDns() {
-		return s.Manager.SetAliyunRecordStatus(recordID, status)
+// resolveLine 服务商支持线路时校验记录的线路，并将线路名称转换为服务商使用的线路值
+func (s *DnsService) resolveLine(ctx context.Context, p dns.Provider, domain string, record *dns.Record, provider string) error {
+	lister, ok := p.(dns.LineLister)
+	if !ok {
+		return nil
 	}
-	// 默认使用DNSPod
-	if s.Manager.UseDnsPod() {
-		return s.Manager.SetDnsPodRecordStatus(recordID, domainID, status)
+
+	lines, err := lineCache.Get(ctx, s.accountName(provider), lister, domain)
+	if err != nil {
+		return err
 	}
-	return nil
+	record.Line, err = dns.ResolveLine(lines, record.Line)
+	return err
 }
 
-// GetAliyunRecordList 获取阿里云DNS记录列表
-func (s *DnsService) GetAliyunRecordList(domainName, rrKeyWord string) ([]dns.AliyunDnsRecord, error) {
-	if s.Manager.UseAliyunDns() {
-		return s.Manager.GetAliyunRecordList(domainName, rrKeyWord)
+// ValidateRecord 按记录类型和账号的服务商类型校验记录，失败时返回validate.Errors
+func (s *DnsService) ValidateRecord(record dns.Record, provider string) error {
+	return validate.Record(s.Manager.Type(provider), record)
+}
+
+// RecordCheck 记录提交前的检查结果
+type RecordCheck struct {
+	Record    dns.Record         `json:"record"`    // 转换线路后将提交到服务商的记录
+	Conflicts validate.Conflicts `json:"conflicts"` // 与现有记录的冲突，包括只提示的warning
+}
+
+// CheckRecord 校验记录、转换线路并检查与服务商现有记录的冲突，不修改服务商的记录
+//
+// 记录校验失败时返回validate.Errors；冲突通过RecordCheck.Conflicts返回，由调用方决定是否提交
+func (s *DnsService) CheckRecord(ctx context.Context, domain string, record dns.Record, provider string) (*RecordCheck, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return nil, err
+	}
+	if record, err = dns.NormalizeRecord(record); err != nil {
+		return nil, err
+	}
+	if err := s.ValidateRecord(record, provider); err != nil {
+		return nil, err
 	}
-	return nil, nil
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+	return s.checkRecord(ctx, p, domain, record, provider)
+}
+
+// checkRecord 转换线路并获取同名的现有记录检查冲突
+func (s *DnsService) checkRecord(ctx context.Context, p dns.Provider, domain string, record dns.Record, provider string) (*RecordCheck, error) {
+	if err := s.resolveLine(ctx, p, domain, &record, provider); err != nil {
+		return nil, dns.ContextError(ctx, err)
+	}
+
+	existing, err := p.GetRecordList(ctx, domain, record.Name)
+	if err != nil && !errors.Is(err, dns.ErrNotFound) {
+		return nil, dns.ContextError(ctx, err)
+	}
+	return &RecordCheck{
+		Record:    record,
+		Conflicts: validate.CheckConflicts(existing, record),
+	}, nil
+}
+
+// CreateRecord 创建DNS记录，与现有记录冲突时返回validate.Conflicts
+func (s *DnsService) CreateRecord(ctx context.Context, domain string, record dns.Record, provider string) (*dns.Record, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return nil, err
+	}
+	if record, err = dns.NormalizeRecord(record); err != nil {
+		return nil, err
+	}
+	if err := s.ValidateRecord(record, provider); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+	check, err := s.checkRecord(ctx, p, domain, record, provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := check.Conflicts.Err(); err != nil {
+		return nil, err
+	}
+	result, err := p.CreateRecord(ctx, domain, check.Record)
+	if err != nil {
+		return result, dns.ContextError(ctx, err)
+	}
+	return s.fetchRecord(ctx, p, domain, result), nil
+}
+
+// UpdateRecord 更新DNS记录，与现有记录冲突时返回validate.Conflicts
+func (s *DnsService) UpdateRecord(ctx context.Context, domain string, record dns.Record, provider string) (*dns.Record, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return nil, err
+	}
+	if record, err = dns.NormalizeRecord(record); err != nil {
+		return nil, err
+	}
+	if err := s.ValidateRecord(record, provider); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+	check, err := s.checkRecord(ctx, p, domain, record, provider)
+	if err != nil {
+		return nil, err
+	}
+	if err := check.Conflicts.Err(); err != nil {
+		return nil, err
+	}
+	result, err := p.UpdateRecord(ctx, domain, check.Record)
+	if err != nil {
+		return result, dns.ContextError(ctx, err)
+	}
+	return s.fetchRecord(ctx, p, domain, result), nil
 }
 
-// CreateAliyunRecord 创建阿里云DNS记录
-func (s *DnsService) CreateAliyunRecord(domainName, rr, recordType, value string, ttl int64) (*dns.AliyunDnsRecord, error) {
-	if s.Manager.UseAliyunDns() {
-		return s.Manager.CreateAliyunRecord(domainName, rr, recordType, value, ttl)
+// DeleteRecord 删除DNS记录
+func (s *DnsService) DeleteRecord(ctx context.Context, recordID, domain string, provider string) error {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return err
 	}
-	return nil, nil
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+	return dns.ContextError(ctx, p.DeleteRecord(ctx, domain, recordID))
 }
 
-// UpdateAliyunRecord 更新阿里云DNS记录
-func (s *DnsService) UpdateAliyunRecord(recordId, rr, recordType, value string, ttl int64) (*dns.AliyunDnsRecord, error) {
-	if s.Manager.UseAliyunDns() {
-		return s.Manager.UpdateAliyunRecord(recordId, rr, recordType, value, ttl)
+// SetRecordStatus 设置记录状态，服务商支持按ID获取记录时返回设置后的完整记录，否则只有ID和状态
+func (s *DnsService) SetRecordStatus(ctx context.Context, recordID, domain, status string, provider string) (*dns.Record, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, err
 	}
-	return nil, nil
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+	if err := p.SetRecordStatus(ctx, domain, recordID, status); err != nil {
+		return nil, dns.ContextError(ctx, err)
+	}
+	return s.fetchRecord(ctx, p, domain, &dns.Record{ID: recordID, Domain: domain, Status: status}), nil
 }
 
-// DeleteAliyunRecord 删除阿里云DNS记录
-func (s *DnsService) DeleteAliyunRecord(recordId string) error {
-	if s.Manager.UseAliyunDns() {
-		return s.Manager.DeleteAliyunRecord(recordId)
+// GetRecord 按ID获取单条记录
+func (s *DnsService) GetRecord(ctx context.Context, recordID, domain string, provider string) (*dns.Record, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return nil, err
+	}
+	getter, ok := p.(dns.RecordGetter)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s类型的账号不能按ID获取记录", dns.ErrUnsupported, s.Manager.Type(provider))
 	}
-	return nil
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+	record, err := getter.GetRecord(ctx, domain, recordID)
+	return record, dns.ContextError(ctx, err)
 }
 
-// SetAliyunRecordStatus 设置阿里云DNS记录状态
-func (s *DnsService) SetAliyunRecordStatus(recordId, status string) error {
-	if s.Manager.UseAliyunDns() {
-		return s.Manager.SetAliyunRecordStatus(recordId, status)
+// fetchRecord 写入成功后从服务商重新获取记录，返回服务商实际保存的值
+//
+// 服务商不支持按ID获取或获取失败时返回写入接口的结果，写入已经成功，不作为错误返回
+func (s *DnsService) fetchRecord(ctx context.Context, p dns.Provider, domain string, written *dns.Record) *dns.Record {
+	getter, ok := p.(dns.RecordGetter)
+	if !ok || written == nil || written.ID == "" {
+		return written
+	}
+
+	record, err := getter.GetRecord(ctx, domain, written.ID)
+	if err != nil {
+		log.Printf("获取记录 %s 失败，返回写入接口的结果: %v", written.ID, err)
+		return written
+	}
+	if record.Domain == "" {
+		record.Domain = written.Domain
 	}
-	return nil
+	return record
 }
@@ -2,13 +2,19 @@ package models
 
 import (
 	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+	"github.com/EDDYCJY/go-gin-example/pkg/dns/validate"
 )
 
 // DnsDomain 域名信息模型
 type DnsDomain struct {
 	ID         int        `gorm:"primary_key" json:"id"`
-	Name       string     `gorm:"column:name;size:255;not null" json:"name"`
-	Provider   string     `gorm:"column:provider;size:50;not null" json:"provider"` // dns_pod, aliyun
+	Name       string     `gorm:"column:name;size:255;not null" json:"name"`        // 域名的Unicode形式，如 中文.com
+	PunyCode   string     `gorm:"column:punycode;size:255" json:"punycode"`         // 域名的ASCII形式，如 xn--fiq228c.com
+	Provider   string     `gorm:"column:provider;size:50;not null" json:"provider"` // 服务商账号名称，如 dns_pod、aliyun-prod
 	DomainID   string     `gorm:"column:domain_id;size:100" json:"domain_id"`       // 云服务商的域名ID
 	Status     string     `gorm:"column:status;size:20" json:"status"`              // active, inactive
 	Grade      string     `gorm:"column:grade;size:50" json:"grade"`                // 域名等级
@@ -23,14 +29,17 @@ type DnsDomain struct {
 type DnsRecord struct {
 	ID         int        `gorm:"primary_key" json:"id"`
 	DomainID   int        `gorm:"column:domain_id;not null" json:"domain_id"`       // 关联域名ID
-	Name       string     `gorm:"column:name;size:255;not null" json:"name"`        // 记录名称，如 www
+	Name       string     `gorm:"column:name;size:255;not null" json:"name"`        // 记录名称，如 www，国际化域名为Unicode形式
+	PunyCode   string     `gorm:"column:punycode;size:255" json:"punycode"`         // 记录名称的ASCII形式
 	Type       string     `gorm:"column:type;size:10;not null" json:"type"`         // 记录类型，如 A, CNAME, MX
 	Value      string     `gorm:"column:value;size:255;not null" json:"value"`      // 记录值，如IP地址
 	Status     string     `gorm:"column:status;size:20" json:"status"`              // enable, disable
 	Line       string     `gorm:"column:line;size:50" json:"line"`                  // 线路
 	TTL        int        `gorm:"column:ttl;default:600" json:"ttl"`                // TTL值
+	Priority   int        `gorm:"column:priority;default:0" json:"priority"`        // MX优先级
+	Weight     int        `gorm:"column:weight;default:0" json:"weight"`            // 权重，0表示不设置
 	Remark     string     `gorm:"column:remark;type:text" json:"remark"`            // 备注
-	Provider   string     `gorm:"column:provider;size:50;not null" json:"provider"` // dns_pod, aliyun
+	Provider   string     `gorm:"column:provider;size:50;not null" json:"provider"` // 服务商账号名称，如 dns_pod、aliyun-prod
 	RemoteID   string     `gorm:"column:remote_id;size:100" json:"remote_id"`       // 云服务商的记录ID
 	CreatedOn  time.Time  `json:"created_on"`
 	ModifiedOn time.Time  `json:"modified_on"`
@@ -47,8 +56,14 @@ func (DnsRecord) TableName() string {
 	return "dns_records"
 }
 
-// AddDnsDomain 添加域名
+// AddDnsDomain 添加域名，域名规范化后同时保存Unicode和ASCII形式
 func AddDnsDomain(domain *DnsDomain) error {
+	ascii, unicode, err := dns.NormalizeDomain(domain.Name)
+	if err != nil {
+		return err
+	}
+	domain.Name, domain.PunyCode = unicode, ascii
+
 	if err := db.Create(domain).Error; err != nil {
 		return err
 	}
@@ -75,14 +90,38 @@ func GetDnsDomainTotal(maps interface{}) (int, error) {
 	return count, nil
 }
 
-// UpdateDnsDomain 更新域名
+// UpdateDnsDomain 更新域名，修改name时同时更新punycode
 func UpdateDnsDomain(id int, data interface{}) error {
+	if err := normalizeNameData(data); err != nil {
+		return err
+	}
 	if err := db.Model(&DnsDomain{}).Where("id = ?", id).Updates(data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// GetDnsDomain 根据ID获取域名
+func GetDnsDomain(id int) (*DnsDomain, error) {
+	var domain DnsDomain
+	err := db.Where("id = ?", id).First(&domain).Error
+	if err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+// ASCIIName 域名的ASCII形式，用于调用服务商接口
+func (d DnsDomain) ASCIIName() string {
+	if d.PunyCode != "" {
+		return d.PunyCode
+	}
+	if ascii, err := dns.ToASCII(d.Name); err == nil {
+		return ascii
+	}
+	return d.Name
+}
+
 // DeleteDnsDomain 删除域名
 func DeleteDnsDomain(id int) error {
 	if err := db.Where("id = ?", id).Delete(&DnsDomain{}).Error; err != nil {
@@ -98,14 +137,35 @@ func ExistDnsDomainByID(id int) bool {
 	return domain.ID > 0
 }
 
-// AddDnsRecord 添加DNS解析记录
+// AddDnsRecord 添加DNS解析记录，记录名称规范化后同时保存Unicode和ASCII形式
 func AddDnsRecord(record *DnsRecord) error {
+	return addDnsRecord(db, record)
+}
+
+// addDnsRecord 在db或事务中添加DNS解析记录
+func addDnsRecord(db *gorm.DB, record *DnsRecord) error {
+	ascii, unicode, err := dns.NormalizeDomain(record.Name)
+	if err != nil {
+		return err
+	}
+	record.Name, record.PunyCode = unicode, ascii
+
 	if err := db.Create(record).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// GetDnsRecord 根据ID获取DNS解析记录
+func GetDnsRecord(id int) (*DnsRecord, error) {
+	var record DnsRecord
+	err := db.Where("id = ?", id).First(&record).Error
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
 // GetDnsRecordList 获取DNS解析记录列表
 func GetDnsRecordList(pageNum, pageSize int, maps interface{}) ([]DnsRecord, error) {
 	var records []DnsRecord
@@ -126,14 +186,65 @@ func GetDnsRecordTotal(maps interface{}) (int, error) {
 	return count, nil
 }
 
-// UpdateDnsRecord 更新DNS解析记录
+// UpdateDnsRecord 更新DNS解析记录，修改name时同时更新punycode
 func UpdateDnsRecord(id int, data interface{}) error {
+	return updateDnsRecord(db, id, data)
+}
+
+// updateDnsRecord 在db或事务中更新DNS解析记录
+func updateDnsRecord(db *gorm.DB, id int, data interface{}) error {
+	if err := normalizeNameData(data); err != nil {
+		return err
+	}
 	if err := db.Model(&DnsRecord{}).Where("id = ?", id).Updates(data).Error; err != nil {
 		return err
 	}
 	return nil
 }
 
+// Record 转换为服务商的统一记录，用于校验和提交到服务商，记录名称使用ASCII形式
+func (r DnsRecord) Record() dns.Record {
+	name := r.PunyCode
+	if name == "" {
+		if ascii, err := dns.ToASCII(r.Name); err == nil {
+			name = ascii
+		} else {
+			name = r.Name
+		}
+	}
+	return dns.Record{
+		ID:       r.RemoteID,
+		Name:     name,
+		Type:     r.Type,
+		Value:    r.Value,
+		TTL:      int64(r.TTL),
+		Priority: int64(r.Priority),
+		Weight:   int64(r.Weight),
+		Line:     r.Line,
+		Status:   r.Status,
+		Remark:   r.Remark,
+	}
+}
+
+// CheckDnsRecordConflicts 检查记录与同一域名在dns_records表中其他记录的冲突，record.ID不为0时排除记录本身
+func CheckDnsRecordConflicts(record *DnsRecord) (validate.Conflicts, error) {
+	records, err := GetDnsRecordByDomainID(record.DomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make([]dns.Record, 0, len(records))
+	for _, r := range records {
+		if r.ID == record.ID {
+			continue
+		}
+		existing = append(existing, r.Record())
+	}
+	proposed := record.Record()
+	proposed.ID = ""
+	return validate.CheckConflicts(existing, proposed), nil
+}
+
 // DeleteDnsRecord 删除DNS解析记录
 func DeleteDnsRecord(id int) error {
 	if err := db.Where("id = ?", id).Delete(&DnsRecord{}).Error; err != nil {
@@ -159,12 +270,65 @@ func GetDnsRecordByDomainID(domainID int) ([]DnsRecord, error) {
 	return records, nil
 }
 
-// GetDnsDomainByName 根据域名名称获取域名信息
+// GetDnsDomainByName 根据域名名称获取域名信息，name可以是Unicode或ASCII形式
 func GetDnsDomainByName(name string) (*DnsDomain, error) {
+	ascii, unicode, err := dns.NormalizeDomain(name)
+	if err != nil {
+		return nil, err
+	}
+
 	var domain DnsDomain
-	err := db.Where("name = ?", name).First(&domain).Error
+	err = db.Where("name IN (?) OR punycode = ?", []string{unicode, ascii}, ascii).First(&domain).Error
 	if err != nil {
 		return nil, err
 	}
 	return &domain, nil
 }
+
+// GetDnsDomainByRemote 根据服务商账号和服务商的域名ID或域名名称获取域名信息
+func GetDnsDomainByRemote(provider, domain string) (*DnsDomain, error) {
+	ascii, unicode, err := dns.NormalizeDomain(domain)
+	if err != nil {
+		ascii, unicode = domain, domain
+	}
+
+	var dnsDomain DnsDomain
+	err = db.Where("provider = ? AND (domain_id = ? OR name IN (?) OR punycode = ?)", provider, domain, []string{unicode, ascii}, ascii).First(&dnsDomain).Error
+	if err != nil {
+		return nil, err
+	}
+	return &dnsDomain, nil
+}
+
+// DeleteDnsDomainWithRecords 删除域名及其DNS解析记录
+func DeleteDnsDomainWithRecords(id int) error {
+	tx := db.Begin()
+	if err := tx.Where("domain_id = ?", id).Delete(&DnsRecord{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Where("id = ?", id).Delete(&DnsDomain{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit().Error
+}
+
+// normalizeNameData 更新数据中包含name时规范化，并同时更新punycode
+func normalizeNameData(data interface{}) error {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	name, ok := m["name"].(string)
+	if !ok {
+		return nil
+	}
+
+	ascii, unicode, err := dns.NormalizeDomain(name)
+	if err != nil {
+		return err
+	}
+	m["name"], m["punycode"] = unicode, ascii
+	return nil
+}
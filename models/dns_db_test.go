@@ -0,0 +1,78 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+func TestDnsDomainIDN(t *testing.T) {
+	setupTestDB(t)
+
+	domain := DnsDomain{Name: "中文.COM.", Provider: "memory"}
+	if err := AddDnsDomain(&domain); err != nil {
+		t.Fatal(err)
+	}
+	if domain.Name != "中文.com" || domain.PunyCode != "xn--fiq228c.com" || domain.ASCIIName() != "xn--fiq228c.com" {
+		t.Errorf("保存的域名 = %q, %q", domain.Name, domain.PunyCode)
+	}
+
+	// Unicode和ASCII形式都能查到
+	for _, name := range []string{"中文.com", "XN--FIQ228C.COM", "中文。com."} {
+		found, err := GetDnsDomainByName(name)
+		if err != nil || found.ID != domain.ID {
+			t.Errorf("GetDnsDomainByName(%q) = %+v, %v", name, found, err)
+		}
+	}
+	if found, err := GetDnsDomainByRemote("memory", "xn--fiq228c.com"); err != nil || found.ID != domain.ID {
+		t.Errorf("GetDnsDomainByRemote = %+v, %v", found, err)
+	}
+
+	// 修改名称时同时更新punycode
+	if err := UpdateDnsDomain(domain.ID, map[string]interface{}{"name": "Bücher.de"}); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := GetDnsDomain(domain.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "bücher.de" || updated.PunyCode != "xn--bcher-kva.de" {
+		t.Errorf("修改后的域名 = %q, %q", updated.Name, updated.PunyCode)
+	}
+
+	if err := AddDnsDomain(&DnsDomain{Name: "xn--zz.com", Provider: "memory"}); !errors.Is(err, dns.ErrInvalidParam) {
+		t.Errorf("无效的域名返回 %v", err)
+	}
+}
+
+func TestDnsRecordIDN(t *testing.T) {
+	setupTestDB(t)
+
+	record := DnsRecord{DomainID: 1, Name: "中文", Type: "A", Value: "192.0.2.1", Provider: "memory"}
+	if err := AddDnsRecord(&record); err != nil {
+		t.Fatal(err)
+	}
+	if record.Name != "中文" || record.PunyCode != "xn--fiq228c" {
+		t.Errorf("保存的记录名称 = %q, %q", record.Name, record.PunyCode)
+	}
+	// 提交到服务商时使用ASCII形式
+	if got := record.Record().Name; got != "xn--fiq228c" {
+		t.Errorf("Record().Name = %q", got)
+	}
+	// 旧数据没有punycode时按名称转换
+	if got := (DnsRecord{Name: "例子"}).Record().Name; got != "xn--fsqu00a" {
+		t.Errorf("没有punycode时Record().Name = %q", got)
+	}
+
+	if err := UpdateDnsRecord(record.ID, map[string]interface{}{"name": "WWW"}); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := GetDnsRecord(record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "www" || updated.PunyCode != "www" {
+		t.Errorf("修改后的记录名称 = %q, %q", updated.Name, updated.PunyCode)
+	}
+}
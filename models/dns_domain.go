@@ -0,0 +1,210 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// domainManager 获取账号的服务商，服务商不支持域名管理时返回错误
+func (s *DnsService) domainManager(provider string) (dns.DomainManager, error) {
+	p, err := s.Manager.Provider(provider)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := p.(dns.DomainManager)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s类型的账号不能管理域名", dns.ErrUnsupported, s.Manager.Type(provider))
+	}
+	return m, nil
+}
+
+// CreateDomain 在服务商添加域名并保存到dns_domains表，remark不为空时同时设置备注
+func (s *DnsService) CreateDomain(ctx context.Context, name, remark string, provider string) (*dns.Domain, error) {
+	m, err := s.domainManager(provider)
+	if err != nil {
+		return nil, err
+	}
+	if name, err = dns.ToASCII(name); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+
+	domain, err := m.CreateDomain(ctx, name)
+	if err != nil {
+		return nil, dns.ContextError(ctx, err)
+	}
+	if domain.Name == "" {
+		domain.Name = name
+	}
+	if remark != "" {
+		if err := m.SetDomainRemark(ctx, domain.Name, remark); err != nil {
+			return nil, dns.ContextError(ctx, err)
+		}
+		domain.Remark = remark
+	}
+
+	// 数据库中已有同名域名时更新为服务商返回的信息
+	now := time.Now()
+	local, err := GetDnsDomainByRemote(s.accountName(provider), domain.Name)
+	switch {
+	case err == nil:
+		err = UpdateDnsDomain(local.ID, map[string]interface{}{
+			"domain_id":   domain.ID,
+			"status":      dnsDomainStatus(domain.Status),
+			"grade":       domain.Grade,
+			"remark":      domain.Remark,
+			"modified_on": now,
+		})
+	case gorm.IsRecordNotFoundError(err):
+		err = AddDnsDomain(&DnsDomain{
+			Name:       domain.Name,
+			Provider:   s.accountName(provider),
+			DomainID:   domain.ID,
+			Status:     dnsDomainStatus(domain.Status),
+			Grade:      domain.Grade,
+			Remark:     domain.Remark,
+			CreatedOn:  now,
+			ModifiedOn: now,
+		})
+	}
+	if err != nil {
+		return domain, fmt.Errorf("域名已在服务商添加，保存到数据库失败: %v", err)
+	}
+	return domain, nil
+}
+
+// DeleteDomain 在服务商删除域名，并删除dns_domains表中的域名及其解析记录
+func (s *DnsService) DeleteDomain(ctx context.Context, domain string, provider string) error {
+	m, err := s.domainManager(provider)
+	if err != nil {
+		return err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+
+	if err := m.DeleteDomain(ctx, domain); err != nil {
+		return dns.ContextError(ctx, err)
+	}
+
+	local, err := GetDnsDomainByRemote(s.accountName(provider), domain)
+	if gorm.IsRecordNotFoundError(err) {
+		return nil
+	}
+	if err == nil {
+		err = DeleteDnsDomainWithRecords(local.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("域名已在服务商删除，更新数据库失败: %v", err)
+	}
+	return nil
+}
+
+// SetDomainStatus 设置域名状态，同步到dns_domains表
+func (s *DnsService) SetDomainStatus(ctx context.Context, domain, status string, provider string) error {
+	m, err := s.domainManager(provider)
+	if err != nil {
+		return err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+
+	if err := m.SetDomainStatus(ctx, domain, status); err != nil {
+		return dns.ContextError(ctx, err)
+	}
+	return s.updateLocalDomain(domain, provider, map[string]interface{}{
+		"status": dnsDomainStatus(status),
+	})
+}
+
+// SetDomainRemark 设置域名备注，同步到dns_domains表
+func (s *DnsService) SetDomainRemark(ctx context.Context, domain, remark string, provider string) error {
+	m, err := s.domainManager(provider)
+	if err != nil {
+		return err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+
+	if err := m.SetDomainRemark(ctx, domain, remark); err != nil {
+		return dns.ContextError(ctx, err)
+	}
+	return s.updateLocalDomain(domain, provider, map[string]interface{}{
+		"remark": remark,
+	})
+}
+
+// LockDomain 锁定域名days天，返回解锁码
+func (s *DnsService) LockDomain(ctx context.Context, domain string, days int, provider string) (string, error) {
+	m, err := s.domainManager(provider)
+	if err != nil {
+		return "", err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+
+	lockCode, err := m.LockDomain(ctx, domain, days)
+	return lockCode, dns.ContextError(ctx, err)
+}
+
+// UnlockDomain 使用解锁码解锁域名
+func (s *DnsService) UnlockDomain(ctx context.Context, domain, lockCode string, provider string) error {
+	m, err := s.domainManager(provider)
+	if err != nil {
+		return err
+	}
+	if domain, err = dns.DomainParam(domain); err != nil {
+		return err
+	}
+
+	ctx, cancel := s.Manager.WithTimeout(ctx, provider)
+	defer cancel()
+
+	return dns.ContextError(ctx, m.UnlockDomain(ctx, domain, lockCode))
+}
+
+// updateLocalDomain 更新dns_domains表中对应的域名，数据库中没有该域名时忽略
+func (s *DnsService) updateLocalDomain(domain, provider string, data map[string]interface{}) error {
+	local, err := GetDnsDomainByRemote(s.accountName(provider), domain)
+	if gorm.IsRecordNotFoundError(err) {
+		return nil
+	}
+	if err == nil {
+		data["modified_on"] = time.Now()
+		err = UpdateDnsDomain(local.ID, data)
+	}
+	if err != nil {
+		return fmt.Errorf("服务商已修改，更新数据库失败: %v", err)
+	}
+	return nil
+}
+
+// dnsDomainStatus 服务商的enable/disable对应dns_domains表的active/inactive
+func dnsDomainStatus(status string) string {
+	if status == dns.RecordStatusDisable {
+		return "inactive"
+	}
+	return "active"
+}
@@ -0,0 +1,370 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+	"github.com/EDDYCJY/go-gin-example/pkg/dns/validate"
+)
+
+// FieldDiff 一个字段在数据库和服务商中的不同取值
+type FieldDiff struct {
+	Field    string      `json:"field"`
+	Expected interface{} `json:"expected"` // dns_records表中的值
+	Actual   interface{} `json:"actual"`   // 服务商的值
+}
+
+// RecordDrift 数据库和服务商中都有、但字段不一致的记录
+type RecordDrift struct {
+	RecordID int         `json:"record_id"` // dns_records表中的ID
+	RemoteID string      `json:"remote_id"`
+	Name     string      `json:"name"`
+	Type     string      `json:"type"`
+	Diffs    []FieldDiff `json:"diffs"`
+}
+
+// DriftReport 一个域名的数据库记录与服务商记录的差异
+type DriftReport struct {
+	DomainID  int           `json:"domain_id"`
+	Domain    string        `json:"domain"`
+	Provider  string        `json:"provider"`
+	InSync    bool          `json:"in_sync"`
+	Added     []dns.Record  `json:"added"`   // 服务商有、数据库没有的记录
+	Removed   []DnsRecord   `json:"removed"` // 数据库有、服务商没有的记录
+	Changed   []RecordDrift `json:"changed"`
+	CheckedOn time.Time     `json:"checked_on"`
+}
+
+// DnsDrift 每个域名最近一次差异检查的结果
+type DnsDrift struct {
+	ID        int          `gorm:"primary_key" json:"id"`
+	DomainID  int          `gorm:"column:domain_id;not null;unique_index" json:"domain_id"`
+	InSync    bool         `gorm:"column:in_sync" json:"in_sync"`
+	Added     int          `gorm:"column:added" json:"added"`
+	Removed   int          `gorm:"column:removed" json:"removed"`
+	Changed   int          `gorm:"column:changed" json:"changed"`
+	Report    string       `gorm:"column:report;type:mediumtext" json:"-"` // DriftReport的JSON
+	Error     string       `gorm:"column:error;type:text" json:"error"`    // 检查失败的原因，成功时为空
+	CheckedOn time.Time    `json:"checked_on"`
+	Result    *DriftReport `gorm:"-" json:"report"`
+}
+
+// TableName 指定DnsDrift表名
+func (DnsDrift) TableName() string {
+	return "dns_drifts"
+}
+
+// AfterFind 解析保存的差异报告
+func (d *DnsDrift) AfterFind() error {
+	if d.Report == "" {
+		return nil
+	}
+	d.Result = &DriftReport{}
+	return json.Unmarshal([]byte(d.Report), d.Result)
+}
+
+// GetDnsDrift 获取域名最近一次差异检查的结果
+func GetDnsDrift(domainID int) (*DnsDrift, error) {
+	var drift DnsDrift
+	err := db.Where("domain_id = ?", domainID).First(&drift).Error
+	if err != nil {
+		return nil, err
+	}
+	return &drift, nil
+}
+
+// GetDnsDriftList 获取差异检查结果列表
+func GetDnsDriftList(pageNum, pageSize int, maps interface{}) ([]DnsDrift, error) {
+	var drifts []DnsDrift
+	err := db.Where(maps).Offset(pageNum).Limit(pageSize).Find(&drifts).Error
+	if err != nil {
+		return nil, err
+	}
+	return drifts, nil
+}
+
+// GetDnsDriftTotal 获取差异检查结果总数
+func GetDnsDriftTotal(maps interface{}) (int, error) {
+	var count int
+	err := db.Model(&DnsDrift{}).Where(maps).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// saveDnsDrift 保存域名的检查结果，report为nil时只更新错误原因和检查时间，保留上次的差异报告
+func saveDnsDrift(domainID int, report *DriftReport, checkErr error) error {
+	drift := DnsDrift{DomainID: domainID, CheckedOn: time.Now()}
+	if checkErr != nil {
+		drift.Error = checkErr.Error()
+	}
+	data := map[string]interface{}{
+		"error":      drift.Error,
+		"checked_on": drift.CheckedOn,
+	}
+	if report != nil {
+		encoded, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		drift.InSync = report.InSync
+		drift.Added = len(report.Added)
+		drift.Removed = len(report.Removed)
+		drift.Changed = len(report.Changed)
+		drift.Report = string(encoded)
+		drift.CheckedOn = report.CheckedOn
+		data["in_sync"] = drift.InSync
+		data["added"] = drift.Added
+		data["removed"] = drift.Removed
+		data["changed"] = drift.Changed
+		data["report"] = drift.Report
+		data["checked_on"] = drift.CheckedOn
+	}
+
+	existing, err := GetDnsDrift(domainID)
+	if gorm.IsRecordNotFoundError(err) {
+		return db.Create(&drift).Error
+	}
+	if err != nil {
+		return err
+	}
+	return db.Model(&DnsDrift{}).Where("id = ?", existing.ID).Updates(data).Error
+}
+
+// CheckDrift 比较域名在dns_records表中的记录与服务商的记录，并保存为该域名最近一次的检查结果
+func (s *DnsService) CheckDrift(ctx context.Context, domainID int) (*DriftReport, error) {
+	domain, err := GetDnsDomain(domainID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.checkDrift(ctx, domain)
+	if saveErr := saveDnsDrift(domain.ID, report, err); saveErr != nil {
+		log.Printf("保存域名 %s 的差异检查结果失败: %v", domain.Name, saveErr)
+	}
+	return report, err
+}
+
+// CheckAllDrift 检查dns_records表中全部域名，返回有差异或检查失败的域名数
+func (s *DnsService) CheckAllDrift(ctx context.Context) (drifted, failed int, err error) {
+	var domains []DnsDomain
+	if err := db.Find(&domains).Error; err != nil {
+		return 0, 0, err
+	}
+	for i := range domains {
+		report, err := s.CheckDrift(ctx, domains[i].ID)
+		switch {
+		case err != nil:
+			failed++
+			log.Printf("检查域名 %s 的差异失败: %v", domains[i].Name, err)
+		case !report.InSync:
+			drifted++
+		}
+	}
+	return drifted, failed, nil
+}
+
+// StartDnsDriftCheck 在后台按interval定时检查全部域名的差异，interval不大于0时不启动
+func StartDnsDriftCheck(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			drifted, failed, err := NewDnsService().CheckAllDrift(context.Background())
+			if err != nil {
+				log.Printf("定时差异检查失败: %v", err)
+				continue
+			}
+			log.Printf("定时差异检查完成，%d个域名有差异，%d个域名检查失败", drifted, failed)
+		}
+	}()
+}
+
+// checkDrift 获取服务商的记录并与数据库中的记录比较
+func (s *DnsService) checkDrift(ctx context.Context, domain *DnsDomain) (*DriftReport, error) {
+	live, err := s.GetRecordList(ctx, domain.ASCIIName(), "", domain.Provider)
+	if err != nil {
+		return nil, err
+	}
+	locals, err := GetDnsRecordByDomainID(domain.ID)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.domainLines(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	report := diffDnsRecords(locals, live, lines)
+	report.DomainID = domain.ID
+	report.Domain = domain.Name
+	report.Provider = domain.Provider
+	return report, nil
+}
+
+// recordMatch 数据库记录和与之对应的服务商记录
+type recordMatch struct {
+	Local  DnsRecord
+	Remote dns.Record
+}
+
+// matchDnsRecords 将数据库记录与服务商记录一一对应，返回对应的记录、只在数据库中的记录和只在服务商的记录
+//
+// 有remote_id的记录按记录ID匹配；没有remote_id的记录(手工添加)按名称、类型和值匹配剩余的服务商记录。
+// 由服务商管理的SOA和根域名NS记录不会出现在只在服务商的记录中
+func matchDnsRecords(locals []DnsRecord, live []dns.Record) (matches []recordMatch, localOnly []DnsRecord, remoteOnly []dns.Record) {
+	byID := make(map[string]int, len(live))
+	for i, r := range live {
+		if r.ID != "" {
+			byID[r.ID] = i
+		}
+	}
+	matched := make([]bool, len(live))
+
+	var unlinked []DnsRecord
+	for _, local := range locals {
+		if local.RemoteID == "" {
+			unlinked = append(unlinked, local)
+			continue
+		}
+		i, ok := byID[local.RemoteID]
+		if !ok || matched[i] {
+			localOnly = append(localOnly, local)
+			continue
+		}
+		matched[i] = true
+		matches = append(matches, recordMatch{Local: local, Remote: live[i]})
+	}
+
+	for _, local := range unlinked {
+		expected := local.Record()
+		found := false
+		for i, r := range live {
+			if matched[i] || !sameRecordName(expected.Name, r.Name) || !strings.EqualFold(expected.Type, r.Type) ||
+				!validate.SameValue(strings.ToUpper(r.Type), dns.Record{Value: expected.Value}, dns.Record{Value: r.Value}) {
+				continue
+			}
+			matched[i] = true
+			found = true
+			matches = append(matches, recordMatch{Local: local, Remote: r})
+			break
+		}
+		if !found {
+			localOnly = append(localOnly, local)
+		}
+	}
+
+	for i, r := range live {
+		if !matched[i] && !providerManaged(r) {
+			remoteOnly = append(remoteOnly, r)
+		}
+	}
+	return matches, localOnly, remoteOnly
+}
+
+// domainLines 获取域名可用的线路，用于比较线路名称和线路代码，服务商不支持线路时返回nil
+func (s *DnsService) domainLines(ctx context.Context, domain *DnsDomain) ([]dns.Line, error) {
+	lines, err := s.GetLineList(ctx, domain.ASCIIName(), domain.Provider)
+	if errors.Is(err, dns.ErrUnsupported) {
+		return nil, nil
+	}
+	return lines, err
+}
+
+// diffDnsRecords 比较数据库记录和服务商记录，lines为域名的线路列表
+func diffDnsRecords(locals []DnsRecord, live []dns.Record, lines []dns.Line) *DriftReport {
+	report := &DriftReport{
+		Added:     []dns.Record{},
+		Removed:   []DnsRecord{},
+		Changed:   []RecordDrift{},
+		CheckedOn: time.Now(),
+	}
+
+	matches, localOnly, remoteOnly := matchDnsRecords(locals, live)
+	report.Removed = append(report.Removed, localOnly...)
+	report.Added = append(report.Added, remoteOnly...)
+	for _, m := range matches {
+		diffs := recordDiffs(m.Local, m.Remote, lines)
+		if len(diffs) == 0 {
+			continue
+		}
+		report.Changed = append(report.Changed, RecordDrift{
+			RecordID: m.Local.ID,
+			RemoteID: m.Remote.ID,
+			Name:     m.Local.Name,
+			Type:     m.Local.Type,
+			Diffs:    diffs,
+		})
+	}
+
+	report.InSync = len(report.Added) == 0 && len(report.Removed) == 0 && len(report.Changed) == 0
+	return report
+}
+
+// recordDiffs 比较同一条记录在数据库和服务商中的各字段
+//
+// 数据库中优先级和权重为0表示不设置，状态为空表示启用，这些情况不比较。
+// 数据库中保存线路名称，阿里云等服务商返回线路代码，按lines转换为同一线路后比较
+func recordDiffs(local DnsRecord, remote dns.Record, lines []dns.Line) []FieldDiff {
+	expected := local.Record()
+	var diffs []FieldDiff
+	add := func(field string, changed bool, expected, actual interface{}) {
+		if changed {
+			diffs = append(diffs, FieldDiff{Field: field, Expected: expected, Actual: actual})
+		}
+	}
+
+	recordType := strings.ToUpper(remote.Type)
+	add("name", !sameRecordName(expected.Name, remote.Name), local.Name, remote.Name)
+	add("type", !strings.EqualFold(expected.Type, remote.Type), expected.Type, remote.Type)
+	add("value", !validate.SameValue(recordType, dns.Record{Value: expected.Value}, dns.Record{Value: remote.Value}), expected.Value, remote.Value)
+	add("line", !sameLine(lines, expected.Line, remote.Line), expected.Line, remote.Line)
+	add("ttl", expected.TTL != remote.TTL, expected.TTL, remote.TTL)
+	add("priority", expected.Priority != 0 && expected.Priority != remote.Priority, expected.Priority, remote.Priority)
+	add("weight", expected.Weight != 0 && expected.Weight != remote.Weight, expected.Weight, remote.Weight)
+	add("status", expected.Status != "" && expected.Status != remote.Status, expected.Status, remote.Status)
+	add("remark", expected.Remark != remote.Remark, expected.Remark, remote.Remark)
+	return diffs
+}
+
+// sameLine 判断线路是否相同，线路名称和代码对应同一线路时视为相同，如 电信 和 telecom
+func sameLine(lines []dns.Line, a, b string) bool {
+	if validate.SameLine(a, b) {
+		return true
+	}
+	codeA, errA := dns.ResolveLine(lines, a)
+	codeB, errB := dns.ResolveLine(lines, b)
+	return errA == nil && errB == nil && codeA == codeB
+}
+
+// sameRecordName 判断主机记录是否相同，不区分大小写和Unicode/ASCII形式，空字符串表示@
+func sameRecordName(a, b string) bool {
+	normalize := func(name string) string {
+		ascii, err := dns.ToASCII(name)
+		if err != nil {
+			ascii = strings.ToLower(name)
+		}
+		if ascii == "" {
+			return "@"
+		}
+		return ascii
+	}
+	return normalize(a) == normalize(b)
+}
+
+// providerManaged 是否为服务商管理的记录，即SOA和根域名的NS记录，同步和迁移时不删除也不创建
+func providerManaged(record dns.Record) bool {
+	recordType := strings.ToUpper(record.Type)
+	return recordType == "SOA" || recordType == "NS" && sameRecordName(record.Name, "@")
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// aliyunLines 阿里云的线路列表，接口中使用线路代码，数据库中保存线路名称
+var aliyunLines = []dns.Line{
+	{Code: "default", Name: "默认"},
+	{Code: "telecom", Name: "电信"},
+	{Code: "unicom", Name: "联通"},
+	{Code: "oversea", Name: "境外"},
+}
+
+func TestRecordDiffsLine(t *testing.T) {
+	tests := []struct {
+		local, remote string
+		lines         []dns.Line
+		changed       bool
+	}{
+		{"电信", "telecom", aliyunLines, false},
+		{"电信", "TELECOM", aliyunLines, false},
+		{"telecom", "telecom", aliyunLines, false},
+		{"默认", "default", aliyunLines, false},
+		{"", "default", aliyunLines, false},
+		{"电信", "unicom", aliyunLines, true},
+		{"境外", "default", aliyunLines, true},
+		{"电信", "telecom", nil, true}, // 没有线路列表时无法对应
+		{"电信", "电信", nil, false},     // DNSPod返回线路名称
+		{"移动", "mobile", aliyunLines, true},
+	}
+	for _, tt := range tests {
+		local := DnsRecord{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Line: tt.local}
+		remote := dns.Record{ID: "1", Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Line: tt.remote}
+		diffs := recordDiffs(local, remote, tt.lines)
+		if changed := len(diffs) > 0; changed != tt.changed {
+			t.Errorf("线路 %q 与 %q 的差异 = %+v, 期望有差异 %v", tt.local, tt.remote, diffs, tt.changed)
+		}
+		if len(diffs) > 0 && (len(diffs) != 1 || diffs[0].Field != "line") {
+			t.Errorf("线路 %q 与 %q 的差异 = %+v, 期望只有line", tt.local, tt.remote, diffs)
+		}
+	}
+}
+
+func TestDiffDnsRecordsAliyunLines(t *testing.T) {
+	locals := []DnsRecord{
+		{ID: 1, Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Line: "默认", RemoteID: "r1"},
+		{ID: 2, Name: "www", Type: "A", Value: "192.0.2.2", TTL: 600, Line: "电信", RemoteID: "r2"},
+		{ID: 3, Name: "api", Type: "A", Value: "192.0.2.3", TTL: 600, Line: "联通", RemoteID: "r3"},
+	}
+	live := []dns.Record{
+		{ID: "r1", Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Line: "default"},
+		{ID: "r2", Name: "www", Type: "A", Value: "192.0.2.2", TTL: 600, Line: "telecom"},
+		{ID: "r3", Name: "api", Type: "A", Value: "192.0.2.3", TTL: 600, Line: "telecom"},
+	}
+
+	report := diffDnsRecords(locals, live, aliyunLines)
+	if report.InSync || len(report.Changed) != 1 || report.Changed[0].RecordID != 3 {
+		t.Fatalf("差异 = %+v", report.Changed)
+	}
+	diff := report.Changed[0].Diffs[0]
+	if diff.Field != "line" || diff.Expected != "联通" || diff.Actual != "telecom" {
+		t.Errorf("api的线路差异 = %+v", diff)
+	}
+
+	// 生成同步计划时线路名称与代码对应的记录不需要修改
+	ops := planOperations(locals, live, aliyunLines)
+	if len(ops) != 1 || ops[0].Action != PlanActionUpdate || ops[0].RecordID != 3 {
+		t.Errorf("同步计划 = %+v", ops)
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	setupTestDB(t)
+	s, p := newTestDnsService(t, "example.com")
+	ctx := context.Background()
+
+	www, err := p.CreateRecord(ctx, "example.com", dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Line: "电信"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain := DnsDomain{Name: "example.com", Provider: "memory"}
+	if err := AddDnsDomain(&domain); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ImportDomain(ctx, domain.ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := s.CheckDrift(ctx, domain.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.InSync {
+		t.Errorf("导入后的差异 = %+v", report)
+	}
+
+	if _, err := p.UpdateRecord(ctx, "example.com", dns.Record{ID: www.ID, Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Line: "联通"}); err != nil {
+		t.Fatal(err)
+	}
+	if report, err = s.CheckDrift(ctx, domain.ID); err != nil {
+		t.Fatal(err)
+	}
+	if report.InSync || len(report.Changed) != 1 || report.Changed[0].Diffs[0].Field != "line" {
+		t.Errorf("修改线路后的差异 = %+v", report.Changed)
+	}
+
+	// 检查结果保存到dns_drifts表
+	saved, err := GetDnsDrift(domain.ID)
+	if err != nil || saved.InSync {
+		t.Errorf("保存的检查结果 = %+v, %v", saved, err)
+	}
+}
@@ -0,0 +1,286 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// ImportResult 导入一个域名的解析记录的结果
+type ImportResult struct {
+	DomainID    int    `json:"domain_id"` // dns_domains表中的ID
+	Domain      string `json:"domain"`
+	Provider    string `json:"provider"`
+	Total       int    `json:"total"`     // 服务商的记录数
+	Created     int    `json:"created"`   // 新增到数据库的记录数
+	Updated     int    `json:"updated"`   // 与服务商不一致而更新的记录数
+	Unchanged   int    `json:"unchanged"` // 与服务商一致的记录数
+	Skipped     int    `json:"skipped"`   // 服务商没有返回记录ID而跳过的记录数
+	Orphaned    int    `json:"orphaned"`  // 数据库中有remote_id、服务商已没有的记录数
+	OrphanedIDs []int  `json:"orphaned_ids,omitempty"`
+	Pruned      bool   `json:"pruned"`   // 是否已删除孤立记录
+	Unlinked    int    `json:"unlinked"` // 数据库中没有remote_id的记录数，不参与导入
+	Error       string `json:"error,omitempty"`
+}
+
+// ImportAllResult 导入账号下全部域名的结果
+type ImportAllResult struct {
+	Provider        string         `json:"provider"`
+	DomainsCreated  int            `json:"domains_created"`
+	DomainsUpdated  int            `json:"domains_updated"`
+	DomainsOrphaned []string       `json:"domains_orphaned"` // dns_domains表中有、服务商已没有的域名
+	Domains         []ImportResult `json:"domains"`
+	Failed          int            `json:"failed"` // 导入失败的域名数
+}
+
+// ImportDomain 从服务商获取域名的全部解析记录，写入dns_records表
+//
+// 按服务商账号和remote_id匹配已有的记录：没有的新增，不一致的更新为服务商的值。
+// 数据库中有remote_id但服务商已没有的记录为孤立记录，prune为true时删除，否则只统计
+func (s *DnsService) ImportDomain(ctx context.Context, domainID int, prune bool) (*ImportResult, error) {
+	domain, err := GetDnsDomain(domainID)
+	if err != nil {
+		return nil, err
+	}
+	return s.importDomain(ctx, domain, prune)
+}
+
+// ImportAllDomains 从服务商获取账号下的全部域名和解析记录，写入dns_domains和dns_records表
+//
+// 数据库中没有的域名会新增，已有的更新域名ID、状态、等级和备注。单个域名导入失败时
+// 记录错误并继续导入其他域名
+func (s *DnsService) ImportAllDomains(ctx context.Context, prune bool, provider string) (*ImportAllResult, error) {
+	domains, err := s.GetDomainList(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	account := s.accountName(provider)
+	result := &ImportAllResult{
+		Provider:        account,
+		DomainsOrphaned: []string{},
+		Domains:         make([]ImportResult, 0, len(domains)),
+	}
+
+	live := make(map[int]bool, len(domains))
+	for _, d := range domains {
+		local, created, err := syncDnsDomain(account, d)
+		if err != nil {
+			result.Failed++
+			result.Domains = append(result.Domains, ImportResult{
+				Domain:   d.Name,
+				Provider: account,
+				Error:    err.Error(),
+			})
+			continue
+		}
+		live[local.ID] = true
+		if created {
+			result.DomainsCreated++
+		} else {
+			result.DomainsUpdated++
+		}
+
+		imported, err := s.importDomain(ctx, local, prune)
+		if err != nil {
+			result.Failed++
+			imported = &ImportResult{
+				DomainID: local.ID,
+				Domain:   local.Name,
+				Provider: account,
+				Error:    err.Error(),
+			}
+		}
+		result.Domains = append(result.Domains, *imported)
+	}
+
+	var locals []DnsDomain
+	if err := db.Where("provider = ?", account).Find(&locals).Error; err != nil {
+		return result, err
+	}
+	for _, d := range locals {
+		if !live[d.ID] {
+			result.DomainsOrphaned = append(result.DomainsOrphaned, d.Name)
+		}
+	}
+	return result, nil
+}
+
+// importDomain 导入一个域名的解析记录，同一域名的修改在一个事务中完成
+func (s *DnsService) importDomain(ctx context.Context, domain *DnsDomain, prune bool) (*ImportResult, error) {
+	records, err := s.GetRecordList(ctx, domain.ASCIIName(), "", domain.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := GetDnsRecordByDomainID(domain.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{
+		DomainID: domain.ID,
+		Domain:   domain.Name,
+		Provider: domain.Provider,
+		Total:    len(records),
+		Pruned:   prune,
+	}
+
+	byRemoteID := make(map[string]DnsRecord, len(existing))
+	for _, r := range existing {
+		switch {
+		case r.RemoteID == "":
+			result.Unlinked++
+		case r.Provider == domain.Provider:
+			byRemoteID[r.RemoteID] = r
+		}
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool, len(records))
+	tx := db.Begin()
+	for _, record := range records {
+		if record.ID == "" {
+			result.Skipped++
+			continue
+		}
+		seen[record.ID] = true
+
+		imported, err := importedDnsRecord(domain, record)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+
+		local, ok := byRemoteID[record.ID]
+		if !ok {
+			imported.CreatedOn, imported.ModifiedOn = now, now
+			if err := tx.Create(&imported).Error; err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("保存记录 %s 失败: %v", record.ID, err)
+			}
+			result.Created++
+			continue
+		}
+
+		changes := dnsRecordChanges(local, imported)
+		if len(changes) == 0 {
+			result.Unchanged++
+			continue
+		}
+		changes["modified_on"] = now
+		if err := tx.Model(&DnsRecord{}).Where("id = ?", local.ID).Updates(changes).Error; err != nil {
+			tx.Rollback()
+			return nil, fmt.Errorf("更新记录 %s 失败: %v", record.ID, err)
+		}
+		result.Updated++
+	}
+
+	for remoteID, r := range byRemoteID {
+		if seen[remoteID] {
+			continue
+		}
+		result.Orphaned++
+		result.OrphanedIDs = append(result.OrphanedIDs, r.ID)
+		if prune {
+			if err := tx.Where("id = ?", r.ID).Delete(&DnsRecord{}).Error; err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("删除孤立记录 %d 失败: %v", r.ID, err)
+			}
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// syncDnsDomain 按服务商返回的域名新增或更新dns_domains表中的域名，返回数据库中的域名和是否新增
+func syncDnsDomain(account string, d dns.Domain) (*DnsDomain, bool, error) {
+	now := time.Now()
+	local, err := GetDnsDomainByRemote(account, d.Name)
+	if err == nil {
+		err = UpdateDnsDomain(local.ID, map[string]interface{}{
+			"domain_id":   d.ID,
+			"status":      dnsDomainStatus(d.Status),
+			"grade":       d.Grade,
+			"remark":      d.Remark,
+			"modified_on": now,
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		return local, false, nil
+	}
+	if !gorm.IsRecordNotFoundError(err) {
+		return nil, false, err
+	}
+
+	local = &DnsDomain{
+		Name:       d.Name,
+		Provider:   account,
+		DomainID:   d.ID,
+		Status:     dnsDomainStatus(d.Status),
+		Grade:      d.Grade,
+		Remark:     d.Remark,
+		CreatedOn:  now,
+		ModifiedOn: now,
+	}
+	if err := AddDnsDomain(local); err != nil {
+		return nil, false, err
+	}
+	return local, true, nil
+}
+
+// importedDnsRecord 将服务商的记录转换为dns_records表的记录
+func importedDnsRecord(domain *DnsDomain, record dns.Record) (DnsRecord, error) {
+	ascii, unicode, err := dns.NormalizeDomain(record.Name)
+	if err != nil {
+		return DnsRecord{}, err
+	}
+	if ascii == "" {
+		ascii, unicode = "@", "@"
+	}
+	return DnsRecord{
+		DomainID: domain.ID,
+		Name:     unicode,
+		PunyCode: ascii,
+		Type:     record.Type,
+		Value:    record.Value,
+		Status:   record.Status,
+		Line:     record.Line,
+		TTL:      int(record.TTL),
+		Priority: int(record.Priority),
+		Weight:   int(record.Weight),
+		Remark:   record.Remark,
+		Provider: domain.Provider,
+		RemoteID: record.ID,
+	}, nil
+}
+
+// dnsRecordChanges 比较数据库中的记录与服务商的记录，返回需要更新的字段
+func dnsRecordChanges(local, remote DnsRecord) map[string]interface{} {
+	changes := make(map[string]interface{})
+	set := func(column string, changed bool, value interface{}) {
+		if changed {
+			changes[column] = value
+		}
+	}
+	set("domain_id", local.DomainID != remote.DomainID, remote.DomainID)
+	set("name", local.Name != remote.Name, remote.Name)
+	set("punycode", local.PunyCode != remote.PunyCode, remote.PunyCode)
+	set("type", local.Type != remote.Type, remote.Type)
+	set("value", local.Value != remote.Value, remote.Value)
+	set("status", local.Status != remote.Status, remote.Status)
+	set("line", local.Line != remote.Line, remote.Line)
+	set("ttl", local.TTL != remote.TTL, remote.TTL)
+	set("priority", local.Priority != remote.Priority, remote.Priority)
+	set("weight", local.Weight != remote.Weight, remote.Weight)
+	set("remark", local.Remark != remote.Remark, remote.Remark)
+	return changes
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+func TestImportDomain(t *testing.T) {
+	setupTestDB(t)
+	s, p := newTestDnsService(t, "example.com")
+	ctx := context.Background()
+
+	www, err := p.CreateRecord(ctx, "example.com", dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Remark: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mail, err := p.CreateRecord(ctx, "example.com", dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 10, TTL: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	domain := DnsDomain{Name: "example.com", Provider: "memory"}
+	if err := AddDnsDomain(&domain); err != nil {
+		t.Fatal(err)
+	}
+	// 没有remote_id的记录不参与导入
+	if err := AddDnsRecord(&DnsRecord{DomainID: domain.ID, Name: "manual", Type: "A", Value: "192.0.2.9", Provider: "memory"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 首次导入：2条预置NS记录和新建的2条记录
+	result, err := s.ImportDomain(ctx, domain.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Total != 4 || result.Created != 4 || result.Updated != 0 || result.Unlinked != 1 {
+		t.Errorf("首次导入 = %+v", result)
+	}
+	records, err := GetDnsRecordByDomainID(domain.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported := findImported(records, www.ID)
+	if imported == nil || imported.Name != "www" || imported.Value != "192.0.2.1" || imported.Remark != "web" || imported.Provider != "memory" {
+		t.Fatalf("导入的www记录 = %+v", imported)
+	}
+
+	// 再次导入时按remote_id匹配，不重复新增
+	if result, err = s.ImportDomain(ctx, domain.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 0 || result.Unchanged != 4 {
+		t.Errorf("再次导入 = %+v", result)
+	}
+
+	// 服务商修改和删除记录后导入：更新不一致的记录，删除的记录为孤立记录
+	if _, err := p.UpdateRecord(ctx, "example.com", dns.Record{ID: www.ID, Name: "www", Type: "A", Value: "192.0.2.2", TTL: 300}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.DeleteRecord(ctx, "example.com", mail.ID); err != nil {
+		t.Fatal(err)
+	}
+	if result, err = s.ImportDomain(ctx, domain.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if result.Updated != 1 || result.Unchanged != 2 || result.Orphaned != 1 || result.Pruned {
+		t.Errorf("服务商修改后导入 = %+v", result)
+	}
+	records, _ = GetDnsRecordByDomainID(domain.ID)
+	if r := findImported(records, www.ID); r == nil || r.Value != "192.0.2.2" || r.TTL != 300 || r.ID != imported.ID {
+		t.Errorf("更新后的www记录 = %+v", r)
+	}
+	if findImported(records, mail.ID) == nil {
+		t.Error("prune为false时不应删除孤立记录")
+	}
+
+	// prune为true时删除孤立记录
+	if result, err = s.ImportDomain(ctx, domain.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if result.Orphaned != 1 || !result.Pruned || len(result.OrphanedIDs) != 1 {
+		t.Errorf("删除孤立记录 = %+v", result)
+	}
+	records, _ = GetDnsRecordByDomainID(domain.ID)
+	if findImported(records, mail.ID) != nil || len(records) != 4 {
+		t.Errorf("删除孤立记录后 = %+v", records)
+	}
+
+	// 服务商出错时不修改数据库
+	p.FailOn["GetRecordList"] = true
+	if _, err := s.ImportDomain(ctx, domain.ID, true); err == nil {
+		t.Error("服务商出错时应返回错误")
+	}
+}
+
+func TestImportAllDomains(t *testing.T) {
+	setupTestDB(t)
+	s, p := newTestDnsService(t, "example.com,xn--fiq228c.com") // 服务商返回ASCII形式的域名
+	ctx := context.Background()
+
+	if _, err := p.CreateRecord(ctx, "example.com", dns.Record{Name: "www", Type: "A", Value: "192.0.2.1"}); err != nil {
+		t.Fatal(err)
+	}
+	// 数据库中已有的域名按名称匹配并更新，服务商已没有的域名只统计
+	existing := DnsDomain{Name: "example.com", Provider: "memory", Remark: "old"}
+	if err := AddDnsDomain(&existing); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddDnsDomain(&DnsDomain{Name: "gone.com", Provider: "memory"}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := s.ImportAllDomains(ctx, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Provider != "memory" || result.DomainsCreated != 1 || result.DomainsUpdated != 1 || result.Failed != 0 {
+		t.Errorf("导入全部域名 = %+v", result)
+	}
+	if len(result.DomainsOrphaned) != 1 || result.DomainsOrphaned[0] != "gone.com" {
+		t.Errorf("孤立域名 = %v", result.DomainsOrphaned)
+	}
+	if len(result.Domains) != 2 || result.Domains[0].Created != 3 || result.Domains[1].Created != 2 {
+		t.Errorf("各域名的导入结果 = %+v", result.Domains)
+	}
+
+	idn, err := GetDnsDomainByName("xn--fiq228c.com")
+	if err != nil || idn.Name != "中文.com" || idn.DomainID == "" {
+		t.Errorf("新增的域名 = %+v, %v", idn, err)
+	}
+	updated, err := GetDnsDomain(existing.ID)
+	if err != nil || updated.DomainID == "" || updated.Grade != "DP_Free" || updated.Remark != "" {
+		t.Errorf("更新的域名 = %+v, %v", updated, err)
+	}
+}
+
+// findImported 按服务商记录ID查找导入的记录
+func findImported(records []DnsRecord, remoteID string) *DnsRecord {
+	for i := range records {
+		if records[i].RemoteID == remoteID {
+			return &records[i]
+		}
+	}
+	return nil
+}
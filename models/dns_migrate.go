@@ -0,0 +1,529 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+	"github.com/EDDYCJY/go-gin-example/pkg/dns/validate"
+)
+
+// 迁移任务的状态
+const (
+	MigrationStatusRunning   = "running"
+	MigrationStatusSucceeded = "succeeded" // 全部记录已迁移并校验
+	MigrationStatusPartial   = "partial"   // 有记录无法迁移、创建失败或校验不一致
+	MigrationStatusFailed    = "failed"    // 任务出错，如无法获取源服务商的记录
+)
+
+// 每条记录的迁移结果
+const (
+	MigrationItemPlanned     = "planned"     // 试运行，执行时将创建
+	MigrationItemCreated     = "created"     // 已在目标服务商创建
+	MigrationItemExisting    = "existing"    // 目标服务商已有相同的记录
+	MigrationItemSkipped     = "skipped"     // 根域名的NS、SOA等由服务商管理的记录
+	MigrationItemUnsupported = "unsupported" // 目标服务商无法表示的记录
+	MigrationItemFailed      = "failed"      // 创建失败
+)
+
+// MigrationOptions 迁移参数，Source和Target为服务商账号名称
+type MigrationOptions struct {
+	Domain       string `json:"domain"`
+	Source       string `json:"source"`
+	Target       string `json:"target"`
+	DryRun       bool   `json:"dry_run"`
+	UpdateDomain bool   `json:"update_domain"` // 全部迁移成功后将dns_domains和dns_records改为目标账号
+}
+
+// MigrationItem 一条记录的迁移结果
+type MigrationItem struct {
+	Source   dns.Record  `json:"source"`
+	Target   *dns.Record `json:"target,omitempty"`    // 转换后将提交到目标服务商的记录
+	RemoteID string      `json:"remote_id,omitempty"` // 目标服务商中的记录ID
+	Status   string      `json:"status"`
+	Verified bool        `json:"verified"`
+	Notes    []string    `json:"notes,omitempty"` // 类型、TTL、线路等的调整
+	Error    string      `json:"error,omitempty"`
+}
+
+// MigrationReport 迁移结果
+type MigrationReport struct {
+	Domain        string          `json:"domain"`
+	Source        string          `json:"source"`
+	Target        string          `json:"target"`
+	SourceType    string          `json:"source_type"`
+	TargetType    string          `json:"target_type"`
+	DryRun        bool            `json:"dry_run"`
+	Total         int             `json:"total"`
+	Planned       int             `json:"planned"`
+	Created       int             `json:"created"`
+	Existing      int             `json:"existing"`
+	Skipped       int             `json:"skipped"`
+	Unsupported   int             `json:"unsupported"`
+	Failed        int             `json:"failed"`
+	Verified      int             `json:"verified"`
+	DomainCreated bool            `json:"domain_created"` // 是否在目标服务商添加了域名
+	DomainUpdated bool            `json:"domain_updated"` // 是否已更新dns_domains表
+	Warnings      []string        `json:"warnings"`
+	Items         []MigrationItem `json:"items"`
+}
+
+// DnsMigration 迁移任务
+type DnsMigration struct {
+	ID           int              `gorm:"primary_key" json:"id"`
+	Domain       string           `gorm:"column:domain;size:255;not null" json:"domain"`
+	Source       string           `gorm:"column:source;size:50;not null" json:"source"`
+	Target       string           `gorm:"column:target;size:50;not null" json:"target"`
+	UpdateDomain bool             `gorm:"column:update_domain" json:"update_domain"`
+	Status       string           `gorm:"column:status;size:20" json:"status"`
+	Report       string           `gorm:"column:report;type:mediumtext" json:"-"`
+	Error        string           `gorm:"column:error;type:text" json:"error"`
+	CreatedOn    time.Time        `json:"created_on"`
+	FinishedOn   *time.Time       `json:"finished_on"`
+	Result       *MigrationReport `gorm:"-" json:"report"`
+}
+
+// TableName 指定DnsMigration表名
+func (DnsMigration) TableName() string {
+	return "dns_migrations"
+}
+
+// AfterFind 解析保存的迁移结果
+func (m *DnsMigration) AfterFind() error {
+	if m.Report == "" {
+		return nil
+	}
+	m.Result = &MigrationReport{}
+	return json.Unmarshal([]byte(m.Report), m.Result)
+}
+
+// GetDnsMigration 根据ID获取迁移任务
+func GetDnsMigration(id int) (*DnsMigration, error) {
+	var migration DnsMigration
+	err := db.Where("id = ?", id).First(&migration).Error
+	if err != nil {
+		return nil, err
+	}
+	return &migration, nil
+}
+
+// migrationMu 保证检查正在执行的任务和创建新任务之间没有其他任务创建
+var migrationMu sync.Mutex
+
+// StartMigration 创建迁移任务并在后台执行，通过GetDnsMigration查询进度和结果
+//
+// 同一域名迁移到同一目标账号的任务正在执行时返回dns.ErrConflict
+func (s *DnsService) StartMigration(opts MigrationOptions) (*DnsMigration, error) {
+	if err := s.checkMigration(&opts); err != nil {
+		return nil, err
+	}
+
+	migrationMu.Lock()
+	defer migrationMu.Unlock()
+
+	var running DnsMigration
+	err := db.Where("domain = ? AND target = ? AND status = ?", opts.Domain, opts.Target, MigrationStatusRunning).First(&running).Error
+	if err == nil {
+		return nil, fmt.Errorf("%w: 域名 %s 迁移到 %s 的任务 %d 正在执行", dns.ErrConflict, opts.Domain, opts.Target, running.ID)
+	}
+	if !gorm.IsRecordNotFoundError(err) {
+		return nil, err
+	}
+
+	migration := &DnsMigration{
+		Domain:       opts.Domain,
+		Source:       opts.Source,
+		Target:       opts.Target,
+		UpdateDomain: opts.UpdateDomain,
+		Status:       MigrationStatusRunning,
+		CreatedOn:    time.Now(),
+	}
+	if err := db.Create(migration).Error; err != nil {
+		return nil, err
+	}
+
+	go func() {
+		report, err := s.Migrate(context.Background(), opts)
+		if err := finishDnsMigration(migration.ID, report, err); err != nil {
+			log.Printf("保存迁移任务 %d 的结果失败: %v", migration.ID, err)
+		}
+	}()
+	return migration, nil
+}
+
+// RecoverDnsMigrations 将服务启动前未完成的迁移任务标记为失败，服务启动时调用
+//
+// 迁移在后台goroutine中执行，服务重启后状态仍为running的任务已经中断，不会再完成
+func RecoverDnsMigrations() error {
+	return db.Model(&DnsMigration{}).Where("status = ?", MigrationStatusRunning).Updates(map[string]interface{}{
+		"status":      MigrationStatusFailed,
+		"error":       "服务重启，迁移中断，可以重新执行迁移",
+		"finished_on": time.Now(),
+	}).Error
+}
+
+// finishDnsMigration 保存迁移任务的结果
+func finishDnsMigration(id int, report *MigrationReport, migrateErr error) error {
+	now := time.Now()
+	data := map[string]interface{}{
+		"status":      MigrationStatusFailed,
+		"finished_on": now,
+	}
+	if migrateErr != nil {
+		data["error"] = migrateErr.Error()
+	}
+	if report != nil {
+		encoded, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		data["report"] = string(encoded)
+		if migrateErr == nil {
+			data["status"] = MigrationStatusSucceeded
+			if report.Unsupported > 0 || report.Failed > 0 || report.Verified < report.Created+report.Existing {
+				data["status"] = MigrationStatusPartial
+			}
+		}
+	}
+	return db.Model(&DnsMigration{}).Where("id = ?", id).Updates(data).Error
+}
+
+// checkMigration 检查迁移参数，账号名称为空时使用默认账号，域名转换为ASCII形式
+func (s *DnsService) checkMigration(opts *MigrationOptions) error {
+	var err error
+	if opts.Domain, err = dns.ToASCII(opts.Domain); err != nil {
+		return err
+	}
+	if opts.Domain == "" || opts.Domain == "@" {
+		return fmt.Errorf("%w: 域名不能为空", dns.ErrInvalidParam)
+	}
+	opts.Source, opts.Target = s.accountName(opts.Source), s.accountName(opts.Target)
+	if opts.Source == opts.Target {
+		return fmt.Errorf("%w: 源账号和目标账号不能相同", dns.ErrInvalidParam)
+	}
+	if _, err := s.Manager.Provider(opts.Source); err != nil {
+		return err
+	}
+	if _, err := s.Manager.Provider(opts.Target); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Migrate 将域名的全部记录从源账号迁移到目标账号
+//
+// 记录的类型、TTL、MX优先级和权重按目标服务商的限制转换，线路按名称对应到目标服务商的线路，
+// 无法表示的记录在报告中列出，不会创建。目标服务商没有该域名时先添加域名；目标服务商已有的相同记录不重复创建。
+// 创建后重新获取目标服务商的记录逐条校验。DryRun为true时只转换和检查，不修改目标服务商
+func (s *DnsService) Migrate(ctx context.Context, opts MigrationOptions) (*MigrationReport, error) {
+	if err := s.checkMigration(&opts); err != nil {
+		return nil, err
+	}
+
+	report := &MigrationReport{
+		Domain:     opts.Domain,
+		Source:     opts.Source,
+		Target:     opts.Target,
+		SourceType: s.Manager.Type(opts.Source),
+		TargetType: s.Manager.Type(opts.Target),
+		DryRun:     opts.DryRun,
+		Warnings:   []string{},
+		Items:      []MigrationItem{},
+	}
+
+	records, err := s.GetRecordList(ctx, opts.Domain, "", opts.Source)
+	if err != nil {
+		return nil, fmt.Errorf("获取源服务商的记录失败: %w", err)
+	}
+	sourceLines, err := s.GetLineList(ctx, opts.Domain, opts.Source)
+	if err != nil && !errors.Is(err, dns.ErrUnsupported) {
+		return nil, fmt.Errorf("获取源服务商的线路失败: %w", err)
+	}
+
+	targetDomain, err := s.migrationTarget(ctx, opts, report)
+	if err != nil {
+		return nil, err
+	}
+
+	// 目标服务商的线路和现有记录，试运行且目标服务商还没有该域名时无法获取
+	var targetLines []dns.Line
+	linesKnown := false
+	var existing []dns.Record
+	if targetDomain != nil {
+		targetLines, err = s.GetLineList(ctx, opts.Domain, opts.Target)
+		switch {
+		case err == nil:
+			linesKnown = true
+		case errors.Is(err, dns.ErrUnsupported):
+			targetLines, linesKnown = nil, true
+		default:
+			return nil, fmt.Errorf("获取目标服务商的线路失败: %w", err)
+		}
+		if existing, err = s.GetRecordList(ctx, opts.Domain, "", opts.Target); err != nil && !errors.Is(err, dns.ErrNotFound) {
+			return nil, fmt.Errorf("获取目标服务商的记录失败: %w", err)
+		}
+	} else {
+		report.Warnings = append(report.Warnings, "目标服务商还没有该域名，执行时将添加；线路未按目标服务商校验")
+	}
+
+	report.Total = len(records)
+	for _, record := range records {
+		item := s.migrateRecord(ctx, opts, report, record, sourceLines, targetLines, linesKnown, existing)
+		report.Items = append(report.Items, item)
+	}
+
+	if !opts.DryRun {
+		s.verifyMigration(ctx, opts, report, targetLines)
+	}
+	for _, item := range report.Items {
+		switch item.Status {
+		case MigrationItemPlanned:
+			report.Planned++
+		case MigrationItemCreated:
+			report.Created++
+		case MigrationItemExisting:
+			report.Existing++
+		case MigrationItemSkipped:
+			report.Skipped++
+		case MigrationItemUnsupported:
+			report.Unsupported++
+		case MigrationItemFailed:
+			report.Failed++
+		}
+		if item.Verified {
+			report.Verified++
+		}
+	}
+
+	if opts.UpdateDomain && !opts.DryRun {
+		if report.Unsupported > 0 || report.Failed > 0 || report.Verified < report.Created+report.Existing {
+			report.Warnings = append(report.Warnings, "有记录未迁移或校验不一致，没有更新dns_domains表")
+		} else if err := updateMigratedDomain(opts, targetDomain, report); err != nil {
+			report.Warnings = append(report.Warnings, "更新dns_domains表失败: "+err.Error())
+		}
+	}
+	return report, nil
+}
+
+// migrationTarget 查找目标服务商中的域名，没有时添加；试运行时没有返回nil
+func (s *DnsService) migrationTarget(ctx context.Context, opts MigrationOptions, report *MigrationReport) (*dns.Domain, error) {
+	domains, err := s.GetDomainList(ctx, opts.Target)
+	if err != nil {
+		return nil, fmt.Errorf("获取目标服务商的域名列表失败: %w", err)
+	}
+	for i := range domains {
+		name := domains[i].PunyCode
+		if name == "" {
+			name = domains[i].Name
+		}
+		if ascii, err := dns.ToASCII(name); err == nil && ascii == opts.Domain {
+			return &domains[i], nil
+		}
+	}
+	if opts.DryRun {
+		return nil, nil
+	}
+
+	m, err := s.domainManager(opts.Target)
+	if err != nil {
+		return nil, fmt.Errorf("目标服务商没有该域名，需要先添加: %w", err)
+	}
+	ctx, cancel := s.Manager.WithTimeout(ctx, opts.Target)
+	defer cancel()
+	domain, err := m.CreateDomain(ctx, opts.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("在目标服务商添加域名失败: %w", dns.ContextError(ctx, err))
+	}
+	report.DomainCreated = true
+	return domain, nil
+}
+
+// migrateRecord 转换一条记录并在目标服务商创建
+func (s *DnsService) migrateRecord(ctx context.Context, opts MigrationOptions, report *MigrationReport, record dns.Record,
+	sourceLines, targetLines []dns.Line, linesKnown bool, existing []dns.Record) MigrationItem {
+	item := MigrationItem{Source: record}
+
+	if providerManaged(record) {
+		item.Status = MigrationItemSkipped
+		item.Notes = append(item.Notes, "根域名的"+strings.ToUpper(record.Type)+"记录由目标服务商管理")
+		return item
+	}
+
+	translated, notes, err := validate.Translate(report.SourceType, report.TargetType, record)
+	item.Notes = append(item.Notes, notes...)
+	if err == nil {
+		var note string
+		translated.Line, note, err = migrateLine(sourceLines, targetLines, linesKnown, record.Line)
+		if note != "" {
+			item.Notes = append(item.Notes, note)
+		}
+	}
+	if err == nil {
+		err = validate.Record(report.TargetType, translated)
+	}
+	if err != nil {
+		item.Status, item.Error = MigrationItemUnsupported, err.Error()
+		return item
+	}
+	item.Target = &translated
+
+	for i := range existing {
+		if sameMigratedRecord(targetLines, translated, existing[i]) {
+			item.Status, item.RemoteID = MigrationItemExisting, existing[i].ID
+			return item
+		}
+	}
+	if opts.DryRun {
+		item.Status = MigrationItemPlanned
+		return item
+	}
+
+	created, err := s.CreateRecord(ctx, opts.Domain, translated, opts.Target)
+	if err != nil {
+		item.Status, item.Error = MigrationItemFailed, err.Error()
+		return item
+	}
+	item.Status, item.RemoteID = MigrationItemCreated, created.ID
+	if translated.Status == dns.RecordStatusDisable && created.Status != dns.RecordStatusDisable {
+		if _, err := s.SetRecordStatus(ctx, created.ID, opts.Domain, dns.RecordStatusDisable, opts.Target); err != nil {
+			item.Error = "记录已创建，暂停失败: " + err.Error()
+		}
+	}
+	return item
+}
+
+// verifyMigration 重新获取目标服务商的记录，校验已创建和已有的记录
+func (s *DnsService) verifyMigration(ctx context.Context, opts MigrationOptions, report *MigrationReport, targetLines []dns.Line) {
+	live, err := s.GetRecordList(ctx, opts.Domain, "", opts.Target)
+	if err != nil {
+		report.Warnings = append(report.Warnings, "获取目标服务商的记录失败，未校验: "+err.Error())
+		return
+	}
+
+	for i := range report.Items {
+		item := &report.Items[i]
+		if item.Status != MigrationItemCreated && item.Status != MigrationItemExisting {
+			continue
+		}
+		for _, r := range live {
+			if (item.RemoteID == "" || r.ID == item.RemoteID) && sameMigratedRecord(targetLines, *item.Target, r) &&
+				r.TTL == item.Target.TTL && (item.Target.Status == "" || r.Status == item.Target.Status) {
+				item.Verified = true
+				break
+			}
+		}
+		if !item.Verified && item.Error == "" {
+			item.Error = "校验失败: 目标服务商中没有找到内容、TTL和状态都一致的记录"
+		}
+	}
+}
+
+// migrateLine 将源服务商的线路按名称对应到目标服务商的线路代码
+//
+// 默认线路总是可以迁移；目标服务商不支持线路时，非默认线路无法迁移
+func migrateLine(sourceLines, targetLines []dns.Line, linesKnown bool, line string) (string, string, error) {
+	if validate.SameLine(line, "") {
+		return "", "", nil
+	}
+
+	name := line
+	for _, l := range sourceLines {
+		if l.Name == line || strings.EqualFold(l.Code, line) {
+			name = l.Name
+			break
+		}
+	}
+	if !linesKnown {
+		return name, "线路 " + name + " 未按目标服务商校验", nil
+	}
+	if targetLines == nil {
+		return "", "", fmt.Errorf("%w: 目标服务商不支持线路，无法迁移线路 %s", dns.ErrUnsupported, name)
+	}
+	code, err := dns.ResolveLine(targetLines, name)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: 目标服务商没有线路 %s", dns.ErrUnsupported, name)
+	}
+	if code != line {
+		return code, "线路 " + line + " 转换为 " + code, nil
+	}
+	return code, "", nil
+}
+
+// sameMigratedRecord 判断转换后的记录与目标服务商的记录是否相同，线路按目标服务商的线路比较
+func sameMigratedRecord(targetLines []dns.Line, want, got dns.Record) bool {
+	recordType := strings.ToUpper(want.Type)
+	if !sameRecordName(want.Name, got.Name) || !strings.EqualFold(want.Type, got.Type) || !validate.SameValue(recordType, want, got) {
+		return false
+	}
+	if validate.SameLine(want.Line, got.Line) {
+		return true
+	}
+	a, errA := dns.ResolveLine(targetLines, want.Line)
+	b, errB := dns.ResolveLine(targetLines, got.Line)
+	return errA == nil && errB == nil && a == b
+}
+
+// updateMigratedDomain 将dns_domains表中的域名及其记录改为目标账号，记录的remote_id改为目标服务商的记录ID
+//
+// 数据库中没有该域名时只在报告中提示
+func updateMigratedDomain(opts MigrationOptions, target *dns.Domain, report *MigrationReport) error {
+	local, err := GetDnsDomainByRemote(opts.Source, opts.Domain)
+	if gorm.IsRecordNotFoundError(err) {
+		report.Warnings = append(report.Warnings, "dns_domains表中没有源账号的该域名，无需更新")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	records, err := GetDnsRecordByDomainID(local.ID)
+	if err != nil {
+		return err
+	}
+
+	remoteIDs := make(map[string]string, len(report.Items))
+	for _, item := range report.Items {
+		if item.Source.ID != "" && item.RemoteID != "" {
+			remoteIDs[item.Source.ID] = item.RemoteID
+		}
+	}
+
+	now := time.Now()
+	tx := db.Begin()
+	err = tx.Model(&DnsDomain{}).Where("id = ?", local.ID).Updates(map[string]interface{}{
+		"provider":    opts.Target,
+		"domain_id":   target.ID,
+		"modified_on": now,
+	}).Error
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, r := range records {
+		if r.Provider != opts.Source {
+			continue
+		}
+		// 没有对应的目标记录(如根域名的NS)时清空remote_id，由导入或计划重新关联
+		err := tx.Model(&DnsRecord{}).Where("id = ?", r.ID).Updates(map[string]interface{}{
+			"provider":    opts.Target,
+			"remote_id":   remoteIDs[r.RemoteID],
+			"modified_on": now,
+		}).Error
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+	report.DomainUpdated = true
+	return nil
+}
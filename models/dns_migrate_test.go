@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// newTestMigrationService 使用source和target两个内存服务商账号的DNS服务，两个账号都有example.com。
+// source的每次调用延迟latency毫秒，使迁移任务在检查并发时仍在执行
+func newTestMigrationService(t *testing.T, latency string) *DnsService {
+	t.Helper()
+	manager := dns.NewDnsManager([]dns.Config{
+		{Name: "source", Type: dns.ProviderMemory, Options: map[string]string{"DOMAINS": "example.com", "LATENCY": latency}},
+		{Name: "target", Type: dns.ProviderMemory, Options: map[string]string{"DOMAINS": "example.com"}},
+	}, "source")
+	return &DnsService{Manager: manager}
+}
+
+// waitDnsMigration 等待后台执行的迁移任务结束
+func waitDnsMigration(t *testing.T, id int) *DnsMigration {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		migration, err := GetDnsMigration(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if migration.Status != MigrationStatusRunning {
+			return migration
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("迁移任务 %d 未结束", id)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStartMigrationConflict(t *testing.T) {
+	setupTestDB(t)
+	s := newTestMigrationService(t, "0")
+
+	running := DnsMigration{Domain: "example.com", Source: "source", Target: "target", Status: MigrationStatusRunning, CreatedOn: time.Now()}
+	if err := db.Create(&running).Error; err != nil {
+		t.Fatal(err)
+	}
+	opts := MigrationOptions{Domain: "example.com", Source: "source", Target: "target"}
+	if _, err := s.StartMigration(opts); !errors.Is(err, dns.ErrConflict) {
+		t.Fatalf("同一域名和目标账号的任务正在执行时 err = %v, 期望 ErrConflict", err)
+	}
+
+	// 正在执行的任务结束后可以再次迁移
+	if err := finishDnsMigration(running.ID, nil, errors.New("中断")); err != nil {
+		t.Fatal(err)
+	}
+	migration, err := s.StartMigration(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration = waitDnsMigration(t, migration.ID); migration.Status != MigrationStatusSucceeded || migration.Result == nil {
+		t.Errorf("迁移任务 = %+v", migration)
+	}
+}
+
+func TestStartMigrationConcurrent(t *testing.T) {
+	setupTestDB(t)
+	s := newTestMigrationService(t, "100")
+
+	// 同时提交的任务只有一个能创建
+	opts := MigrationOptions{Domain: "example.com", Source: "source", Target: "target"}
+	results := make(chan error, 5)
+	started := make(chan int, 5)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			migration, err := s.StartMigration(opts)
+			if err == nil {
+				started <- migration.ID
+			}
+			results <- err
+		}()
+	}
+	conflicts := 0
+	for i := 0; i < cap(results); i++ {
+		if err := <-results; errors.Is(err, dns.ErrConflict) {
+			conflicts++
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(started) != 1 || conflicts != cap(results)-1 {
+		t.Fatalf("创建了 %d 个任务, %d 个冲突", len(started), conflicts)
+	}
+	waitDnsMigration(t, <-started)
+}
+
+func TestRecoverDnsMigrations(t *testing.T) {
+	setupTestDB(t)
+
+	finished := time.Now()
+	migrations := []DnsMigration{
+		{Domain: "example.com", Source: "source", Target: "target", Status: MigrationStatusRunning, CreatedOn: time.Now()},
+		{Domain: "example.org", Source: "source", Target: "target", Status: MigrationStatusSucceeded, CreatedOn: time.Now(), FinishedOn: &finished},
+	}
+	for i := range migrations {
+		if err := db.Create(&migrations[i]).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := RecoverDnsMigrations(); err != nil {
+		t.Fatal(err)
+	}
+	interrupted, err := GetDnsMigration(migrations[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if interrupted.Status != MigrationStatusFailed || interrupted.Error == "" || interrupted.FinishedOn == nil {
+		t.Errorf("中断的任务 = %+v, 期望标记为失败", interrupted)
+	}
+	succeeded, err := GetDnsMigration(migrations[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if succeeded.Status != MigrationStatusSucceeded || succeeded.Error != "" {
+		t.Errorf("已完成的任务 = %+v, 不应修改", succeeded)
+	}
+}
@@ -0,0 +1,377 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// 计划的状态
+const (
+	PlanStatusPending  = "pending"  // 未执行
+	PlanStatusApplying = "applying" // 正在执行
+	PlanStatusApplied  = "applied"  // 全部操作成功
+	PlanStatusFailed   = "failed"   // 有操作失败，之后的操作未执行
+)
+
+// 计划中的操作
+const (
+	PlanActionCreate = "create" // 在服务商创建数据库中的记录，写回remote_id
+	PlanActionUpdate = "update" // 将服务商的记录修改为数据库中的值
+	PlanActionStatus = "status" // 只有状态不一致，设置服务商记录的状态
+	PlanActionDelete = "delete" // 删除服务商有、数据库没有的记录
+	PlanActionLink   = "link"   // 记录一致但数据库中没有remote_id，只写回remote_id
+)
+
+// PlanOperation 计划中的一个操作
+type PlanOperation struct {
+	Action   string      `json:"action"`
+	RecordID int         `json:"record_id,omitempty"` // dns_records表中的ID，删除操作为0
+	RemoteID string      `json:"remote_id,omitempty"` // 服务商的记录ID，创建操作为空
+	Record   dns.Record  `json:"record"`              // 创建和修改为数据库中的记录，删除为服务商的记录
+	Diffs    []FieldDiff `json:"diffs,omitempty"`
+}
+
+// PlanResult 计划中一个操作的执行结果
+type PlanResult struct {
+	Index    int    `json:"index"` // 操作在计划中的序号
+	Action   string `json:"action"`
+	RecordID int    `json:"record_id,omitempty"`
+	RemoteID string `json:"remote_id,omitempty"` // 创建后为服务商返回的记录ID
+	Success  bool   `json:"success"`
+	Error    string `json:"error,omitempty"`
+}
+
+// DnsPlan 将服务商的记录同步为dns_records表中记录的计划，生成后不再修改操作
+type DnsPlan struct {
+	ID         int             `gorm:"primary_key" json:"id"`
+	DomainID   int             `gorm:"column:domain_id;not null;index" json:"domain_id"`
+	Provider   string          `gorm:"column:provider;size:50;not null" json:"provider"`
+	Status     string          `gorm:"column:status;size:20" json:"status"`
+	Creates    int             `gorm:"column:creates" json:"creates"`
+	Updates    int             `gorm:"column:updates" json:"updates"` // 包括只修改状态的操作
+	Deletes    int             `gorm:"column:deletes" json:"deletes"`
+	Links      int             `gorm:"column:links" json:"links"`
+	LiveHash   string          `gorm:"column:live_hash;size:64" json:"live_hash"`   // 生成计划时服务商记录的摘要
+	LocalHash  string          `gorm:"column:local_hash;size:64" json:"local_hash"` // 生成计划时数据库记录的摘要
+	Operations string          `gorm:"column:operations;type:mediumtext" json:"-"`
+	Results    string          `gorm:"column:results;type:mediumtext" json:"-"`
+	Error      string          `gorm:"column:error;type:text" json:"error"`
+	CreatedOn  time.Time       `json:"created_on"`
+	AppliedOn  *time.Time      `json:"applied_on"`
+	Ops        []PlanOperation `gorm:"-" json:"operations"`
+	OpResults  []PlanResult    `gorm:"-" json:"results"`
+}
+
+// TableName 指定DnsPlan表名
+func (DnsPlan) TableName() string {
+	return "dns_plans"
+}
+
+// AfterFind 解析保存的操作和执行结果
+func (p *DnsPlan) AfterFind() error {
+	p.Ops = []PlanOperation{}
+	if p.Operations != "" {
+		if err := json.Unmarshal([]byte(p.Operations), &p.Ops); err != nil {
+			return err
+		}
+	}
+	p.OpResults = []PlanResult{}
+	if p.Results != "" {
+		if err := json.Unmarshal([]byte(p.Results), &p.OpResults); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDnsPlan 根据ID获取计划
+func GetDnsPlan(id int) (*DnsPlan, error) {
+	var plan DnsPlan
+	err := db.Where("id = ?", id).First(&plan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// PlanDomain 比较域名在dns_records表中的记录与服务商的记录，生成并保存同步计划
+//
+// 计划中先删除、再修改、最后创建，避免新记录与待删除的记录冲突
+func (s *DnsService) PlanDomain(ctx context.Context, domainID int) (*DnsPlan, error) {
+	domain, err := GetDnsDomain(domainID)
+	if err != nil {
+		return nil, err
+	}
+	live, locals, err := s.planState(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.domainLines(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &DnsPlan{
+		DomainID:  domain.ID,
+		Provider:  domain.Provider,
+		Status:    PlanStatusPending,
+		LiveHash:  liveRecordsHash(live),
+		LocalHash: localRecordsHash(locals),
+		CreatedOn: time.Now(),
+		Ops:       planOperations(locals, live, lines),
+		OpResults: []PlanResult{},
+	}
+	for _, op := range plan.Ops {
+		switch op.Action {
+		case PlanActionCreate:
+			plan.Creates++
+		case PlanActionUpdate, PlanActionStatus:
+			plan.Updates++
+		case PlanActionDelete:
+			plan.Deletes++
+		case PlanActionLink:
+			plan.Links++
+		}
+	}
+
+	encoded, err := json.Marshal(plan.Ops)
+	if err != nil {
+		return nil, err
+	}
+	plan.Operations = string(encoded)
+	if err := db.Create(plan).Error; err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ApplyPlan 执行保存的计划，每个计划只能执行一次
+//
+// 执行前重新获取服务商和数据库的记录，与生成计划时不一致时返回dns.ErrConflict，需要重新生成计划。
+// 操作按顺序执行，遇到失败时停止，已执行的操作不回滚。创建成功后将服务商的记录ID写回dns_records表
+func (s *DnsService) ApplyPlan(ctx context.Context, domainID, planID int) (*DnsPlan, error) {
+	plan, err := GetDnsPlan(planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.DomainID != domainID {
+		return nil, fmt.Errorf("%w: 计划 %d 不属于域名 %d", dns.ErrInvalidParam, planID, domainID)
+	}
+	if plan.Status != PlanStatusPending {
+		return nil, fmt.Errorf("%w: 计划 %d 的状态为%s，只能执行一次", dns.ErrConflict, planID, plan.Status)
+	}
+
+	// 先占用计划，避免同一计划被并发执行
+	claim := db.Model(&DnsPlan{}).Where("id = ? AND status = ?", plan.ID, PlanStatusPending).
+		Update("status", PlanStatusApplying)
+	if claim.Error != nil {
+		return nil, claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		return nil, fmt.Errorf("%w: 计划 %d 正在执行或已执行", dns.ErrConflict, planID)
+	}
+
+	domain, err := GetDnsDomain(domainID)
+	if err == nil {
+		err = s.checkPlanState(ctx, domain, plan)
+	}
+	if err != nil {
+		db.Model(&DnsPlan{}).Where("id = ?", plan.ID).Update("status", PlanStatusPending)
+		return nil, err
+	}
+
+	status, applyErr := PlanStatusApplied, ""
+	results := make([]PlanResult, 0, len(plan.Ops))
+	for i, op := range plan.Ops {
+		result := s.applyOperation(ctx, domain, op)
+		result.Index = i
+		results = append(results, result)
+		if !result.Success {
+			status, applyErr = PlanStatusFailed, result.Error
+			break
+		}
+	}
+
+	encoded, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	err = db.Model(&DnsPlan{}).Where("id = ?", plan.ID).Updates(map[string]interface{}{
+		"status":     status,
+		"results":    string(encoded),
+		"error":      applyErr,
+		"applied_on": now,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("计划已执行，保存执行结果失败: %v", err)
+	}
+
+	plan.Status, plan.Results, plan.OpResults = status, string(encoded), results
+	plan.Error, plan.AppliedOn = applyErr, &now
+	return plan, nil
+}
+
+// planState 获取服务商和数据库中的记录
+func (s *DnsService) planState(ctx context.Context, domain *DnsDomain) ([]dns.Record, []DnsRecord, error) {
+	live, err := s.GetRecordList(ctx, domain.ASCIIName(), "", domain.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+	locals, err := GetDnsRecordByDomainID(domain.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return live, locals, nil
+}
+
+// checkPlanState 检查服务商和数据库的记录在生成计划后是否有修改
+func (s *DnsService) checkPlanState(ctx context.Context, domain *DnsDomain, plan *DnsPlan) error {
+	live, locals, err := s.planState(ctx, domain)
+	if err != nil {
+		return err
+	}
+	if liveRecordsHash(live) != plan.LiveHash {
+		return fmt.Errorf("%w: 服务商的记录在生成计划后已修改，请重新生成计划", dns.ErrConflict)
+	}
+	if localRecordsHash(locals) != plan.LocalHash {
+		return fmt.Errorf("%w: 数据库的记录在生成计划后已修改，请重新生成计划", dns.ErrConflict)
+	}
+	return nil
+}
+
+// applyOperation 执行一个操作，成功后将服务商的记录ID写回dns_records表
+func (s *DnsService) applyOperation(ctx context.Context, domain *DnsDomain, op PlanOperation) PlanResult {
+	result := PlanResult{Action: op.Action, RecordID: op.RecordID, RemoteID: op.RemoteID}
+	name := domain.ASCIIName()
+
+	var err error
+	switch op.Action {
+	case PlanActionCreate:
+		var created *dns.Record
+		created, err = s.CreateRecord(ctx, name, op.Record, domain.Provider)
+		if err == nil {
+			result.RemoteID = created.ID
+			if op.Record.Status == dns.RecordStatusDisable && created.Status != dns.RecordStatusDisable {
+				_, err = s.SetRecordStatus(ctx, created.ID, name, op.Record.Status, domain.Provider)
+			}
+		}
+	case PlanActionUpdate:
+		// RemarkSet不随计划保存，按差异恢复，备注改为空时才会清除
+		op.Record.RemarkSet = hasFieldDiff(op.Diffs, "remark")
+		// PowerDNS、RFC2136等服务商的记录ID包含记录值，修改后使用返回的新ID
+		var updated *dns.Record
+		updated, err = s.UpdateRecord(ctx, name, op.Record, domain.Provider)
+		if err == nil {
+			result.RemoteID = updated.ID
+			if hasFieldDiff(op.Diffs, "status") {
+				_, err = s.SetRecordStatus(ctx, updated.ID, name, op.Record.Status, domain.Provider)
+			}
+		}
+	case PlanActionStatus:
+		_, err = s.SetRecordStatus(ctx, op.RemoteID, name, op.Record.Status, domain.Provider)
+	case PlanActionDelete:
+		err = s.DeleteRecord(ctx, op.RemoteID, name, domain.Provider)
+	}
+
+	if err == nil && op.RecordID != 0 && result.RemoteID != "" {
+		err = UpdateDnsRecord(op.RecordID, map[string]interface{}{
+			"remote_id":   result.RemoteID,
+			"modified_on": time.Now(),
+		})
+		if err != nil {
+			err = fmt.Errorf("服务商已修改，写回remote_id失败: %v", err)
+		}
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Success = true
+	return result
+}
+
+// planOperations 计算使服务商的记录与数据库一致所需的操作，按删除、修改、创建排序，lines为域名的线路列表
+func planOperations(locals []DnsRecord, live []dns.Record, lines []dns.Line) []PlanOperation {
+	matches, localOnly, remoteOnly := matchDnsRecords(locals, live)
+
+	ops := make([]PlanOperation, 0, len(localOnly)+len(remoteOnly)+len(matches))
+	for _, r := range remoteOnly {
+		ops = append(ops, PlanOperation{Action: PlanActionDelete, RemoteID: r.ID, Record: r})
+	}
+
+	for _, m := range matches {
+		desired := m.Local.Record()
+		desired.ID = m.Remote.ID
+		op := PlanOperation{RecordID: m.Local.ID, RemoteID: m.Remote.ID, Record: desired}
+
+		op.Diffs = recordDiffs(m.Local, m.Remote, lines)
+		op.Record.RemarkSet = hasFieldDiff(op.Diffs, "remark")
+		switch {
+		case len(op.Diffs) == 0 && m.Local.RemoteID == "":
+			op.Action = PlanActionLink
+		case len(op.Diffs) == 0:
+			continue
+		case len(op.Diffs) == 1 && op.Diffs[0].Field == "status":
+			op.Action = PlanActionStatus
+		default:
+			op.Action = PlanActionUpdate
+		}
+		ops = append(ops, op)
+	}
+
+	for _, local := range localOnly {
+		desired := local.Record()
+		desired.ID = ""
+		ops = append(ops, PlanOperation{Action: PlanActionCreate, RecordID: local.ID, Record: desired})
+	}
+	return ops
+}
+
+// hasFieldDiff 是否有指定字段的差异
+func hasFieldDiff(diffs []FieldDiff, field string) bool {
+	for _, d := range diffs {
+		if d.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// liveRecordsHash 服务商记录的摘要，与记录的返回顺序无关
+func liveRecordsHash(records []dns.Record) string {
+	lines := make([]string, 0, len(records))
+	for _, r := range records {
+		lines = append(lines, fmt.Sprintf("%s|%s|%s|%s|%s|%d|%d|%d|%s|%s",
+			r.ID, r.Name, r.Type, r.Value, r.Line, r.TTL, r.Priority, r.Weight, r.Status, r.Remark))
+	}
+	return hashLines(lines)
+}
+
+// localRecordsHash 数据库记录的摘要，与记录的查询顺序无关
+func localRecordsHash(records []DnsRecord) string {
+	lines := make([]string, 0, len(records))
+	for _, r := range records {
+		lines = append(lines, fmt.Sprintf("%d|%s|%s|%s|%s|%s|%s|%d|%d|%d|%s|%s|%s",
+			r.ID, r.RemoteID, r.Name, r.PunyCode, r.Type, r.Value, r.Line, r.TTL, r.Priority, r.Weight, r.Status, r.Remark, r.Provider))
+	}
+	return hashLines(lines)
+}
+
+// hashLines 排序后计算SHA-256
+func hashLines(lines []string) string {
+	sort.Strings(lines)
+	h := sha256.New()
+	for _, line := range lines {
+		h.Write([]byte(line))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
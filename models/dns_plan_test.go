@@ -0,0 +1,161 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+func TestPlanOperations(t *testing.T) {
+	locals := []DnsRecord{
+		{ID: 1, Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, RemoteID: "r1"},
+		{ID: 2, Name: "api", Type: "A", Value: "192.0.2.2", TTL: 600, RemoteID: "r2"},
+		{ID: 3, Name: "blog", Type: "CNAME", Value: "www.example.com", TTL: 600},
+		{ID: 4, Name: "mail", Type: "A", Value: "192.0.2.4", TTL: 600},
+	}
+	live := []dns.Record{
+		{ID: "soa", Name: "@", Type: "SOA", Value: "ns1.example.net. admin.example.com. 1 7200 3600 1209600 300", TTL: 3600},
+		{ID: "ns1", Name: "@", Type: "NS", Value: "ns1.example.net", TTL: 3600},
+		{ID: "ns2", Name: "", Type: "ns", Value: "ns2.example.net", TTL: 3600},
+		{ID: "r1", Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600},
+		{ID: "r2", Name: "api", Type: "A", Value: "192.0.2.20", TTL: 600},
+		{ID: "r3", Name: "blog", Type: "CNAME", Value: "www.example.com.", TTL: 600},
+		{ID: "r5", Name: "old", Type: "A", Value: "192.0.2.5", TTL: 600},
+		{ID: "r6", Name: "dev", Type: "NS", Value: "ns1.example.org", TTL: 600},
+	}
+
+	ops := planOperations(locals, live, nil)
+	got := make(map[string]string, len(ops))
+	for _, op := range ops {
+		key := op.RemoteID
+		if op.Action == PlanActionCreate {
+			key = op.Record.Name
+		}
+		got[key] = op.Action
+	}
+	want := map[string]string{
+		"r2":   PlanActionUpdate,
+		"r3":   PlanActionLink,
+		"mail": PlanActionCreate,
+		"r5":   PlanActionDelete,
+		"r6":   PlanActionDelete, // 子域名的NS记录是委派，不由服务商管理
+	}
+	if len(got) != len(want) {
+		t.Errorf("操作 = %v, 期望 %v", got, want)
+	}
+	for key, action := range want {
+		if got[key] != action {
+			t.Errorf("%s 的操作 = %q, 期望 %q", key, got[key], action)
+		}
+	}
+	for _, id := range []string{"soa", "ns1", "ns2"} {
+		if _, ok := got[id]; ok {
+			t.Errorf("服务商管理的记录 %s 不应出现在计划中", id)
+		}
+	}
+}
+
+func TestDiffDnsRecordsIgnoresProviderManaged(t *testing.T) {
+	locals := []DnsRecord{{ID: 1, Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, RemoteID: "r1"}}
+	live := []dns.Record{
+		{ID: "soa", Name: "@", Type: "SOA", Value: "ns1.example.net. admin.example.com. 1 7200 3600 1209600 300", TTL: 3600},
+		{ID: "ns1", Name: "@", Type: "NS", Value: "ns1.example.net", TTL: 3600},
+		{ID: "r1", Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600},
+	}
+	if report := diffDnsRecords(locals, live, nil); !report.InSync || len(report.Added) != 0 {
+		t.Errorf("差异报告 = %+v, SOA和根域名NS不应计入added", report)
+	}
+}
+
+func TestProviderManaged(t *testing.T) {
+	tests := []struct {
+		record dns.Record
+		want   bool
+	}{
+		{dns.Record{Name: "@", Type: "SOA"}, true},
+		{dns.Record{Name: "@", Type: "NS"}, true},
+		{dns.Record{Name: "", Type: "ns"}, true},
+		{dns.Record{Name: "dev", Type: "NS"}, false},
+		{dns.Record{Name: "@", Type: "A"}, false},
+	}
+	for _, tt := range tests {
+		if got := providerManaged(tt.record); got != tt.want {
+			t.Errorf("providerManaged(%+v) = %v, 期望 %v", tt.record, got, tt.want)
+		}
+	}
+}
+
+func TestPlanOperationsRemark(t *testing.T) {
+	locals := []DnsRecord{{ID: 1, Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, RemoteID: "r1"}}
+	live := []dns.Record{{ID: "r1", Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Remark: "old"}}
+
+	ops := planOperations(locals, live, nil)
+	if len(ops) != 1 || ops[0].Action != PlanActionUpdate || !ops[0].Record.RemarkSet || ops[0].Record.Remark != "" {
+		t.Errorf("清除备注的计划 = %+v", ops)
+	}
+}
+
+func TestApplyPlanRekeyedRecord(t *testing.T) {
+	setupTestDB(t)
+	s, p := newTestDnsServiceOf(t, testProviderRekey, "example.com")
+	ctx := context.Background()
+
+	www, err := p.CreateRecord(ctx, "example.com", dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Remark: "web"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	domain := DnsDomain{Name: "example.com", Provider: "memory"}
+	if err := AddDnsDomain(&domain); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.ImportDomain(ctx, domain.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	records, err := GetDnsRecordByDomainID(domain.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := findImported(records, www.ID)
+
+	// 数据库中修改值、暂停并清除备注，计划执行后服务商的记录ID改变
+	err = UpdateDnsRecord(local.ID, map[string]interface{}{"value": "192.0.2.2", "status": dns.RecordStatusDisable, "remark": ""})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan, err := s.PlanDomain(ctx, domain.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Updates != 1 || len(plan.Ops) != 1 {
+		t.Fatalf("计划 = %+v", plan.Ops)
+	}
+	if plan, err = s.ApplyPlan(ctx, domain.ID, plan.ID); err != nil {
+		t.Fatal(err)
+	}
+	if plan.Status != PlanStatusApplied {
+		t.Fatalf("执行结果 = %s %+v", plan.Status, plan.OpResults)
+	}
+
+	live, err := p.GetRecordList(ctx, "example.com", "www")
+	if err != nil || len(live) != 1 {
+		t.Fatalf("服务商的记录 = %+v, %v", live, err)
+	}
+	remote := live[0]
+	if remote.ID == www.ID || remote.Value != "192.0.2.2" || remote.Status != dns.RecordStatusDisable || remote.Remark != "" {
+		t.Errorf("服务商的记录 = %+v", remote)
+	}
+	if plan.OpResults[0].RemoteID != remote.ID {
+		t.Errorf("执行结果的remote_id = %q, 期望 %q", plan.OpResults[0].RemoteID, remote.ID)
+	}
+	updated, err := GetDnsRecord(local.ID)
+	if err != nil || updated.RemoteID != remote.ID {
+		t.Errorf("写回的remote_id = %+v, %v, 期望 %s", updated, err, remote.ID)
+	}
+
+	// 新的记录ID已写回，再次检查没有差异
+	report, err := s.CheckDrift(ctx, domain.ID)
+	if err != nil || !report.InSync {
+		t.Errorf("执行计划后的差异 = %+v, %v", report, err)
+	}
+}
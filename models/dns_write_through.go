@@ -0,0 +1,218 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// 写透模式：dns_records表的修改同时提交到域名所属的服务商账号。
+// 数据库的修改和服务商的调用在同一个事务中，服务商调用失败时回滚数据库；
+// 服务商已修改而后续步骤失败时，撤销服务商的修改(删除新建的记录或恢复修改前的值)。
+// 服务商接口使用域名的ASCII形式，阿里云等服务商的记录接口不接受dns_domains表中保存的域名ID
+
+// AddDnsRecordThrough 添加DNS解析记录并在服务商创建，保存服务商返回的记录ID
+//
+// 记录的provider使用域名所属的账号，状态为disable时创建后暂停
+func (s *DnsService) AddDnsRecordThrough(ctx context.Context, record *DnsRecord) error {
+	domain, err := GetDnsDomain(record.DomainID)
+	if err != nil {
+		return err
+	}
+	record.Provider = domain.Provider
+
+	tx := db.Begin()
+	if err := addDnsRecord(tx, record); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	desired := record.Record()
+	desired.ID = ""
+	created, err := s.CreateRecord(ctx, domain.ASCIIName(), desired, domain.Provider)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if record.Status == dns.RecordStatusDisable && created.Status != dns.RecordStatusDisable {
+		if _, err := s.SetRecordStatus(ctx, created.ID, domain.ASCIIName(), record.Status, domain.Provider); err != nil {
+			tx.Rollback()
+			s.undoCreate(ctx, domain, created.ID)
+			return fmt.Errorf("记录已在服务商创建，设置状态失败: %w", err)
+		}
+	}
+
+	record.RemoteID = created.ID
+	if err := tx.Model(&DnsRecord{}).Where("id = ?", record.ID).Update("remote_id", created.ID).Error; err != nil {
+		tx.Rollback()
+		s.undoCreate(ctx, domain, created.ID)
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		s.undoCreate(ctx, domain, created.ID)
+		return err
+	}
+	return nil
+}
+
+// UpdateDnsRecordThrough 更新DNS解析记录并修改服务商的记录，返回更新后的记录
+//
+// 记录还没有remote_id时在服务商创建。写透模式下不能修改记录所属的域名
+func (s *DnsService) UpdateDnsRecordThrough(ctx context.Context, id int, data map[string]interface{}) (*DnsRecord, error) {
+	existing, err := GetDnsRecord(id)
+	if err != nil {
+		return nil, err
+	}
+	if domainID, ok := data["domain_id"].(int); ok && domainID != existing.DomainID {
+		return nil, fmt.Errorf("%w: 写透模式下不能修改记录所属的域名", dns.ErrInvalidParam)
+	}
+	domain, err := GetDnsDomain(existing.DomainID)
+	if err != nil {
+		return nil, err
+	}
+	data["provider"] = domain.Provider
+
+	tx := db.Begin()
+	if err := updateDnsRecord(tx, id, data); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	var updated DnsRecord
+	if err := tx.Where("id = ?", id).First(&updated).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	desired := updated.Record()
+	// undo 撤销服务商的修改：新创建的记录删除，修改的记录恢复为修改前的值
+	undo := func(restoreStatus bool) {
+		if existing.RemoteID == "" {
+			s.undoCreate(ctx, domain, desired.ID)
+		} else {
+			s.undoUpdate(ctx, domain, desired.ID, existing, restoreStatus)
+		}
+	}
+
+	if updated.RemoteID == "" {
+		created, err := s.CreateRecord(ctx, domain.ASCIIName(), desired, domain.Provider)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		updated.RemoteID = created.ID
+		if err := tx.Model(&DnsRecord{}).Where("id = ?", id).Update("remote_id", created.ID).Error; err != nil {
+			tx.Rollback()
+			s.undoCreate(ctx, domain, created.ID)
+			return nil, err
+		}
+		desired.ID = created.ID
+	} else {
+		result, err := s.UpdateRecord(ctx, domain.ASCIIName(), desired, domain.Provider)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		// RFC 2136、PowerDNS等服务商的记录ID随记录的值变化，新的ID与修改在同一事务中保存
+		desired.ID = result.ID
+		if result.ID != updated.RemoteID {
+			updated.RemoteID = result.ID
+			if err := tx.Model(&DnsRecord{}).Where("id = ?", id).Update("remote_id", result.ID).Error; err != nil {
+				tx.Rollback()
+				undo(false)
+				return nil, err
+			}
+		}
+	}
+
+	// 修改了状态，或新创建的记录需要暂停时设置服务商记录的状态
+	status, ok := data["status"].(string)
+	statusChanged := ok && status != existing.Status
+	if statusChanged || existing.RemoteID == "" && updated.Status == dns.RecordStatusDisable {
+		if _, err := s.SetRecordStatus(ctx, desired.ID, domain.ASCIIName(), updated.Status, domain.Provider); err != nil {
+			tx.Rollback()
+			undo(false)
+			return nil, fmt.Errorf("服务商的记录已修改，设置状态失败: %w", err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		undo(statusChanged)
+		return nil, fmt.Errorf("服务商的记录已修改，保存到数据库失败: %v", err)
+	}
+	return &updated, nil
+}
+
+// DeleteDnsRecordThrough 删除DNS解析记录并删除服务商的记录，服务商已没有该记录时只删除数据库
+func (s *DnsService) DeleteDnsRecordThrough(ctx context.Context, id int) error {
+	existing, err := GetDnsRecord(id)
+	if err != nil {
+		return err
+	}
+	domain, err := GetDnsDomain(existing.DomainID)
+	if err != nil {
+		return err
+	}
+
+	tx := db.Begin()
+	if err := tx.Where("id = ?", id).Delete(&DnsRecord{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if existing.RemoteID != "" {
+		err := s.DeleteRecord(ctx, existing.RemoteID, domain.ASCIIName(), domain.Provider)
+		if err != nil && !errors.Is(err, dns.ErrNotFound) {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("记录已在服务商删除，更新数据库失败: %v", err)
+	}
+	return nil
+}
+
+// undoCreate 数据库回滚后删除已在服务商创建的记录，失败时只记录日志
+func (s *DnsService) undoCreate(ctx context.Context, domain *DnsDomain, recordID string) {
+	ctx, cancel := s.undoContext(ctx, domain)
+	defer cancel()
+	if err := s.DeleteRecord(ctx, recordID, domain.ASCIIName(), domain.Provider); err != nil {
+		log.Printf("数据库已回滚，删除服务商记录 %s 失败，需要手工删除: %v", recordID, err)
+	}
+}
+
+// undoUpdate 数据库回滚后将服务商的记录recordID恢复为previous的值，restoreStatus为true时同时恢复状态，失败时只记录日志
+//
+// 恢复后记录ID改变时(如PowerDNS)，将新的ID写回数据库中的remote_id
+func (s *DnsService) undoUpdate(ctx context.Context, domain *DnsDomain, recordID string, previous *DnsRecord, restoreStatus bool) {
+	ctx, cancel := s.undoContext(ctx, domain)
+	defer cancel()
+
+	record := previous.Record()
+	record.ID = recordID
+	record.RemarkSet = true // 修改前没有备注时清除修改中设置的备注
+	restored, err := s.UpdateRecord(ctx, domain.ASCIIName(), record, domain.Provider)
+	if err == nil && restoreStatus {
+		status := previous.Status
+		if status == "" {
+			status = dns.RecordStatusEnable
+		}
+		_, err = s.SetRecordStatus(ctx, restored.ID, domain.ASCIIName(), status, domain.Provider)
+	}
+	if err != nil {
+		log.Printf("数据库已回滚，恢复服务商记录 %s 失败，需要手工恢复为 %+v: %v", recordID, record, err)
+		return
+	}
+	if restored.ID != previous.RemoteID {
+		if err := db.Model(&DnsRecord{}).Where("id = ?", previous.ID).Update("remote_id", restored.ID).Error; err != nil {
+			log.Printf("服务商记录已恢复，记录ID变为 %s，写回记录 %d 的remote_id失败: %v", restored.ID, previous.ID, err)
+		}
+	}
+}
+
+// undoContext 撤销操作使用的context，请求已取消或超时时仍然执行，超时时间按账号配置
+func (s *DnsService) undoContext(ctx context.Context, domain *DnsDomain) (context.Context, context.CancelFunc) {
+	return s.Manager.WithTimeout(context.WithoutCancel(ctx), domain.Provider)
+}
@@ -0,0 +1,120 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// addTestRecordThrough 添加example.com域名和一条写透的www记录
+func addTestRecordThrough(t *testing.T, s *DnsService) *DnsRecord {
+	t.Helper()
+	domain := DnsDomain{Name: "example.com", Provider: "memory"}
+	if err := AddDnsDomain(&domain); err != nil {
+		t.Fatal(err)
+	}
+	record := DnsRecord{DomainID: domain.ID, Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Status: dns.RecordStatusEnable}
+	if err := s.AddDnsRecordThrough(context.Background(), &record); err != nil {
+		t.Fatal(err)
+	}
+	return &record
+}
+
+// remoteRecord 返回服务商中唯一的www记录
+func remoteRecord(t *testing.T, p *dns.MemoryProvider) dns.Record {
+	t.Helper()
+	live, err := p.GetRecordList(context.Background(), "example.com", "www")
+	if err != nil || len(live) != 1 {
+		t.Fatalf("服务商的记录 = %+v, %v", live, err)
+	}
+	return live[0]
+}
+
+func TestUpdateDnsRecordThroughRekeyed(t *testing.T) {
+	setupTestDB(t)
+	s, p := newTestDnsServiceOf(t, testProviderRekey, "example.com")
+	record := addTestRecordThrough(t, s)
+
+	updated, err := s.UpdateDnsRecordThrough(context.Background(), record.ID, map[string]interface{}{"value": "192.0.2.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := remoteRecord(t, p)
+	if remote.ID == record.RemoteID || remote.Value != "192.0.2.2" {
+		t.Errorf("服务商的记录 = %+v, 修改前的ID %s", remote, record.RemoteID)
+	}
+	if updated.RemoteID != remote.ID {
+		t.Errorf("返回的remote_id = %q, 期望 %q", updated.RemoteID, remote.ID)
+	}
+	saved, err := GetDnsRecord(record.ID)
+	if err != nil || saved.RemoteID != remote.ID || saved.Value != "192.0.2.2" {
+		t.Errorf("数据库中的记录 = %+v, %v, 期望remote_id %s", saved, err, remote.ID)
+	}
+
+	// 使用新的ID再次修改
+	if _, err := s.UpdateDnsRecordThrough(context.Background(), record.ID, map[string]interface{}{"value": "192.0.2.3"}); err != nil {
+		t.Fatal(err)
+	}
+	if remote := remoteRecord(t, p); remote.Value != "192.0.2.3" {
+		t.Errorf("再次修改后服务商的记录 = %+v", remote)
+	}
+}
+
+func TestUpdateDnsRecordThroughStatusFailed(t *testing.T) {
+	for _, typ := range []string{dns.ProviderMemory, testProviderRekey} {
+		t.Run(typ, func(t *testing.T) {
+			setupTestDB(t)
+			s, p := newTestDnsServiceOf(t, typ, "example.com")
+			record := addTestRecordThrough(t, s)
+
+			p.FailOn["SetRecordStatus"] = true
+			data := map[string]interface{}{"value": "192.0.2.2", "status": dns.RecordStatusDisable}
+			if _, err := s.UpdateDnsRecordThrough(context.Background(), record.ID, data); err == nil {
+				t.Fatal("设置状态失败时应返回错误")
+			}
+
+			remote := remoteRecord(t, p)
+			if remote.Value != "192.0.2.1" || remote.Status != dns.RecordStatusEnable {
+				t.Errorf("服务商的记录未恢复: %+v", remote)
+			}
+			saved, err := GetDnsRecord(record.ID)
+			if err != nil || saved.Value != "192.0.2.1" || saved.Status != dns.RecordStatusEnable || saved.RemoteID != remote.ID {
+				t.Errorf("数据库中的记录 = %+v, %v, 期望恢复后的remote_id %s", saved, err, remote.ID)
+			}
+		})
+	}
+}
+
+func TestUpdateDnsRecordThroughCommitFailed(t *testing.T) {
+	setupTestDB(t)
+	s, p := newTestDnsServiceOf(t, testProviderRekey, "example.com")
+	record := addTestRecordThrough(t, s)
+
+	// 引用记录值的延迟外键在提交时检查，修改记录值后提交失败。
+	// SQLite提交失败后连接仍在事务中，不保留空闲连接，关闭连接时回滚
+	db.DB().SetMaxIdleConns(0)
+	for _, sql := range []string{
+		"CREATE UNIQUE INDEX idx_dns_records_value ON dns_records(value)",
+		"CREATE TABLE record_refs (value varchar(255) REFERENCES dns_records(value) DEFERRABLE INITIALLY DEFERRED)",
+		"INSERT INTO record_refs (value) VALUES ('192.0.2.1')",
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data := map[string]interface{}{"value": "192.0.2.2", "status": dns.RecordStatusDisable}
+	if _, err := s.UpdateDnsRecordThrough(context.Background(), record.ID, data); err == nil {
+		t.Fatal("提交失败时应返回错误")
+	}
+
+	remote := remoteRecord(t, p)
+	if remote.Value != "192.0.2.1" || remote.Status != dns.RecordStatusEnable {
+		t.Errorf("服务商的记录未恢复: %+v", remote)
+	}
+	saved, err := GetDnsRecord(record.ID)
+	if err != nil || saved.Value != "192.0.2.1" || saved.Status != dns.RecordStatusEnable || saved.RemoteID != remote.ID {
+		t.Errorf("数据库中的记录 = %+v, %v, 期望恢复后的remote_id %s", saved, err, remote.ID)
+	}
+}
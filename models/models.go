@@ -45,8 +45,21 @@ func init() {
 	if err != nil {
 		log.Println(err)
 	}
+	connected := err == nil
 
+	// TableName指定了完整表名的模型不加前缀。gorm按单个模型查询时直接使用TableName，
+	// 按切片查询(如Find(&[]DnsRecord{}))时仍会经过DefaultTableNameHandler，需要在这里排除
+	fullTableNames := map[string]bool{
+		DnsDomain{}.TableName():    true,
+		DnsRecord{}.TableName():    true,
+		DnsDrift{}.TableName():     true,
+		DnsPlan{}.TableName():      true,
+		DnsMigration{}.TableName(): true,
+	}
 	gorm.DefaultTableNameHandler = func(db *gorm.DB, defaultTableName string) string {
+		if fullTableNames[defaultTableName] {
+			return defaultTableName
+		}
 		return tablePrefix + defaultTableName
 	}
 
@@ -55,8 +68,11 @@ func init() {
 	db.DB().SetMaxIdleConns(10)
 	db.DB().SetMaxOpenConns(100)
 
-	// 自动迁移数据库表
-	db.AutoMigrate(&Tag{}, &DnsDomain{}, &DnsRecord{})
+	// 自动迁移数据库表，数据库不可用时跳过，数据库接口返回连接错误
+	if !connected {
+		return
+	}
+	db.AutoMigrate(&Tag{}, &DnsDomain{}, &DnsRecord{}, &DnsDrift{}, &DnsPlan{}, &DnsMigration{})
 }
 
 func CloseDB() {
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jinzhu/gorm"
+	_ "github.com/jinzhu/gorm/dialects/sqlite"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// setupTestDB 将db替换为临时的SQLite数据库，测试结束后恢复
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	testDB, err := gorm.Open("sqlite3", filepath.Join(t.TempDir(), "test.db")+"?_busy_timeout=5000&_foreign_keys=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testDB.SingularTable(true)
+	if err := testDB.AutoMigrate(&Tag{}, &DnsDomain{}, &DnsRecord{}, &DnsDrift{}, &DnsPlan{}, &DnsMigration{}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	old := db
+	db = testDB
+	t.Cleanup(func() {
+		db = old
+		testDB.Close()
+	})
+}
+
+func init() {
+	dns.Register(testProviderRekey, func(cfg dns.Config) (dns.Provider, error) {
+		p, err := dns.NewProvider(dns.Config{Name: cfg.Name, Type: dns.ProviderMemory, Options: cfg.Options})
+		if err != nil {
+			return nil, err
+		}
+		return &rekeyProvider{MemoryProvider: p.(*dns.MemoryProvider)}, nil
+	})
+}
+
+// testProviderRekey 修改记录后记录ID改变的内存服务商，与PowerDNS、RFC2136一样记录ID包含记录值
+const testProviderRekey = "memory-rekey"
+
+type rekeyProvider struct {
+	*dns.MemoryProvider
+}
+
+// UpdateRecord 修改记录后删除并重新创建，返回新的记录ID
+func (p *rekeyProvider) UpdateRecord(ctx context.Context, domain string, record dns.Record) (*dns.Record, error) {
+	updated, err := p.MemoryProvider.UpdateRecord(ctx, domain, record)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.MemoryProvider.DeleteRecord(ctx, domain, updated.ID); err != nil {
+		return nil, err
+	}
+	updated.ID = ""
+	return p.MemoryProvider.CreateRecord(ctx, domain, *updated)
+}
+
+// newTestDnsService 使用单个内存服务商账号memory的DNS服务，返回服务和服务商
+func newTestDnsService(t *testing.T, domains string) (*DnsService, *dns.MemoryProvider) {
+	t.Helper()
+	return newTestDnsServiceOf(t, dns.ProviderMemory, domains)
+}
+
+// newTestDnsServiceOf 使用typ类型的单个账号memory的DNS服务，typ为内存服务商或testProviderRekey
+func newTestDnsServiceOf(t *testing.T, typ, domains string) (*DnsService, *dns.MemoryProvider) {
+	t.Helper()
+	manager := dns.NewDnsManager([]dns.Config{
+		{Name: "memory", Type: typ, Options: map[string]string{"DOMAINS": domains}},
+	}, "memory")
+	p, err := manager.Provider("memory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rekey, ok := p.(*rekeyProvider); ok {
+		return &DnsService{Manager: manager}, rekey.MemoryProvider
+	}
+	return &DnsService{Manager: manager}, p.(*dns.MemoryProvider)
+}
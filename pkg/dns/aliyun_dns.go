@@ -1,15 +1,16 @@
 package dns
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 	"sort"
+	"strings"
 	"time"
 )
 
@@ -18,6 +19,7 @@ type AliyunDnsClient struct {
 	AccessKeyId     string
 	AccessKeySecret string
 	RegionId        string
+	HTTP            *HTTPClient
 }
 
 // AliyunDnsRecord 阿里云DNS记录结构
@@ -38,19 +40,50 @@ type AliyunDnsRecord struct {
 
 // AliyunDnsRecordListResponse 阿里云DNS记录列表响应
 type AliyunDnsRecordListResponse struct {
-	RequestId     string            `json:"RequestId"`
-	TotalCount    int               `json:"TotalCount"`
-	PageNumber    int               `json:"PageNumber"`
-	PageSize      int               `json:"PageSize"`
-	DomainRecords []AliyunDnsRecord `json:"DomainRecords"`
+	RequestId     string `json:"RequestId"`
+	TotalCount    int    `json:"TotalCount"`
+	PageNumber    int    `json:"PageNumber"`
+	PageSize      int    `json:"PageSize"`
+	DomainRecords struct {
+		Record []AliyunDnsRecord `json:"Record"`
+	} `json:"DomainRecords"`
 }
 
+// 阿里云 DescribeDomainRecords 单页最多返回的记录数
+const aliyunMaxPageSize = 500
+
 // AliyunDnsRecordResponse 阿里云DNS记录操作响应
 type AliyunDnsRecordResponse struct {
 	RequestId string `json:"RequestId"`
 	RecordId  string `json:"RecordId"`
 }
 
+// AliyunDomain 阿里云域名信息
+type AliyunDomain struct {
+	DomainId    string `json:"DomainId"`
+	DomainName  string `json:"DomainName"`
+	PunyCode    string `json:"PunyCode"`
+	RecordCount int    `json:"RecordCount"`
+	VersionCode string `json:"VersionCode"` // 云解析版本，如 mianfei
+	VersionName string `json:"VersionName"`
+	GroupId     string `json:"GroupId"`
+	GroupName   string `json:"GroupName"`
+	Remark      string `json:"Remark"`
+	AliDomain   bool   `json:"AliDomain"`
+	DnsServers  struct {
+		DnsServer []string `json:"DnsServer"`
+	} `json:"DnsServers"`
+	Tags struct {
+		Tag []AliyunTag `json:"Tag"`
+	} `json:"Tags"`
+}
+
+// AliyunTag 阿里云资源标签
+type AliyunTag struct {
+	Key   string `json:"Key"`
+	Value string `json:"Value"`
+}
+
 // AliyunDnsDomainListResponse 阿里云域名列表响应
 type AliyunDnsDomainListResponse struct {
 	RequestId  string `json:"RequestId"`
@@ -58,15 +91,13 @@ type AliyunDnsDomainListResponse struct {
 	PageNumber int    `json:"PageNumber"`
 	PageSize   int    `json:"PageSize"`
 	Domains    struct {
-		Domain []struct {
-			DomainId   string `json:"DomainId"`
-			DomainName string `json:"DomainName"`
-			PunyCode   string `json:"PunyCode"`
-			Remark     string `json:"Remark"`
-		} `json:"Domain"`
+		Domain []AliyunDomain `json:"Domain"`
 	} `json:"Domains"`
 }
 
+// 阿里云 DescribeDomains 单页最多返回的域名数
+const aliyunMaxDomainPageSize = 100
+
 // AliyunDnsRecordStatusResponse 阿里云DNS记录状态响应
 type AliyunDnsRecordStatusResponse struct {
 	RequestId string `json:"RequestId"`
@@ -83,6 +114,7 @@ func NewAliyunDnsClient(accessKeyId, accessKeySecret, regionId string) *AliyunDn
 		AccessKeyId:     accessKeyId,
 		AccessKeySecret: accessKeySecret,
 		RegionId:        regionId,
+		HTTP:            NewHTTPClient(aliyunThrottled),
 	}
 }
 
@@ -101,11 +133,11 @@ func (c *AliyunDnsClient) sign(params map[string]string, accessKeySecret string)
 		if queryStr != "" {
 			queryStr += "&"
 		}
-		queryStr += url.QueryEscape(k) + "=" + url.QueryEscape(params[k])
+		queryStr += percentEncode(k) + "=" + percentEncode(params[k])
 	}
 
 	// 构建待签名字符串
-	stringToSign := "GET&" + url.QueryEscape("/") + "&" + url.QueryEscape(queryStr)
+	stringToSign := "GET&" + percentEncode("/") + "&" + percentEncode(queryStr)
 
 	// 计算HMAC-SHA1签名
 	key := []byte(accessKeySecret + "&")
@@ -116,59 +148,66 @@ func (c *AliyunDnsClient) sign(params map[string]string, accessKeySecret string)
 	return signature
 }
 
-// makeRequest 发起阿里云DNS API请求
-func (c *AliyunDnsClient) makeRequest(action string, params map[string]string) ([]byte, error) {
-	client := &http.Client{}
-
-	// 设置公共参数
-	publicParams := map[string]string{
-		"Action":           action,
-		"Format":           "JSON",
-		"Version":          "2015-01-09",
-		"AccessKeyId":      c.AccessKeyId,
-		"SignatureMethod":  "HMAC-SHA1",
-		"SignatureVersion": "1.0",
-		"SignatureNonce":   fmt.Sprintf("%d", time.Now().UnixNano()),
-		"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
-		"RegionId":         c.RegionId,
-	}
+// percentEncode 按阿里云签名要求的RFC 3986规则编码：空格编码为%20，*编码为%2A，~不编码
+func percentEncode(s string) string {
+	encoded := url.QueryEscape(s)
+	encoded = strings.ReplaceAll(encoded, "+", "%20")
+	encoded = strings.ReplaceAll(encoded, "*", "%2A")
+	return strings.ReplaceAll(encoded, "%7E", "~")
+}
 
-	// 合并参数
-	for k, v := range params {
-		publicParams[k] = v
-	}
+// makeRequest 发起阿里云DNS API请求
+func (c *AliyunDnsClient) makeRequest(ctx context.Context, action string, params map[string]string) ([]byte, error) {
+	// 每次尝试重新生成SignatureNonce并签名
+	newRequest := func() (*http.Request, error) {
+		// 设置公共参数
+		publicParams := map[string]string{
+			"Action":           action,
+			"Format":           "JSON",
+			"Version":          "2015-01-09",
+			"AccessKeyId":      c.AccessKeyId,
+			"SignatureMethod":  "HMAC-SHA1",
+			"SignatureVersion": "1.0",
+			"SignatureNonce":   fmt.Sprintf("%d", time.Now().UnixNano()),
+			"Timestamp":        time.Now().UTC().Format("2006-01-02T15:04:05Z"),
+			"RegionId":         c.RegionId,
+		}
 
-	// 计算签名
-	signature := c.sign(publicParams, c.AccessKeySecret)
-	publicParams["Signature"] = signature
+		// 合并参数
+		for k, v := range params {
+			publicParams[k] = v
+		}
 
-	// 构建URL
-	baseURL := "https://alidns.aliyuncs.com/"
+		// 计算签名
+		signature := c.sign(publicParams, c.AccessKeySecret)
+		publicParams["Signature"] = signature
 
-	// 对参数进行排序以构建查询字符串
-	var keys []string
-	for k := range publicParams {
-		keys = append(keys, k)
-	}
-	sort.Strings(keys)
+		// 构建URL
+		baseURL := "https://alidns.aliyuncs.com/"
 
-	var queryStr string
-	for _, k := range keys {
-		if queryStr != "" {
-			queryStr += "&"
+		// 对参数进行排序以构建查询字符串
+		var keys []string
+		for k := range publicParams {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var queryStr string
+		for _, k := range keys {
+			if queryStr != "" {
+				queryStr += "&"
+			}
+			queryStr += percentEncode(k) + "=" + percentEncode(publicParams[k])
 		}
-		queryStr += url.QueryEscape(k) + "=" + url.QueryEscape(publicParams[k])
-	}
 
-	urlStr := baseURL + "?" + queryStr
+		urlStr := baseURL + "?" + queryStr
 
-	resp, err := client.Get(urlStr)
-	if err != nil {
-		return nil, err
+		return http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	// 添加记录等Add开头的操作不是幂等的
+	idempotent := !strings.HasPrefix(action, "Add")
+	resp, body, err := c.HTTP.Do(ctx, idempotent, newRequest)
 	if err != nil {
 		return nil, err
 	}
@@ -178,22 +217,101 @@ func (c *AliyunDnsClient) makeRequest(action string, params map[string]string) (
 		return nil, fmt.Errorf("API返回错误页面，可能认证失败或请求参数错误: %s", string(body))
 	}
 
-	// 检查响应是否为错误信息
+	// 检查响应是否为错误信息，阿里云的错误响应包含Code、Message和RequestId
 	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Code      string `json:"Code"`
+			Message   string `json:"Message"`
+			RequestId string `json:"RequestId"`
+		}
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Code != "" {
+			kind := aliyunErrorKind(apiErr.Code)
+			if kind == nil {
+				kind = kindFromStatus(resp.StatusCode)
+			}
+			return nil, &APIError{
+				Kind:       kind,
+				Code:       apiErr.Code,
+				Message:    apiErr.Message,
+				RequestID:  apiErr.RequestId,
+				StatusCode: resp.StatusCode,
+			}
+		}
 		return nil, fmt.Errorf("API请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(body))
 	}
 
 	return body, nil
 }
 
-// GetAliyunDomainList 获取阿里云域名列表
-func (c *AliyunDnsClient) GetAliyunDomainList(pageNumber, pageSize int) ([]AliyunDnsRecord, error) {
+// aliyunThrottled 阿里云限流时返回Throttling开头的错误码，如Throttling.User
+func aliyunThrottled(statusCode int, body []byte) bool {
+	var result struct {
+		Code string `json:"Code"`
+	}
+	if json.Unmarshal(body, &result) != nil {
+		return false
+	}
+	return aliyunErrorKind(result.Code) == ErrThrottled
+}
+
+// aliyunErrorKind 阿里云错误码对应的错误类别
+func aliyunErrorKind(code string) error {
+	switch code {
+	case "DomainRecordDuplicate", "DomainAddedByOtherAccount":
+		return ErrAlreadyExists
+	case "DomainRecordConflict", "DomainRecordLocked", "LastOperationNotFinished":
+		return ErrConflict
+	case "DomainRecordNotBelongToUser", "IncorrectDomainUser":
+		return ErrNotFound
+	}
+
+	switch {
+	case strings.HasPrefix(code, "Throttling"):
+		return ErrThrottled
+	case strings.HasPrefix(code, "InvalidAccessKeyId"), strings.HasPrefix(code, "Forbidden"),
+		strings.HasPrefix(code, "NoPermission"), code == "SignatureDoesNotMatch", code == "IncompleteSignature":
+		return ErrAuthFailed
+	case strings.HasPrefix(code, "QuotaExceeded"):
+		return ErrQuotaExceeded
+	case strings.Contains(code, "NoExist"), strings.Contains(code, "NotExist"), strings.Contains(code, "NotFound"):
+		return ErrNotFound // 如 InvalidDomainName.NoExist
+	case strings.Contains(code, "Duplicate"):
+		return ErrAlreadyExists // 如 InvalidDomainName.Duplicate
+	case strings.HasPrefix(code, "Invalid"), strings.HasPrefix(code, "Missing"), strings.HasPrefix(code, "Illegal"):
+		return ErrInvalidParam
+	}
+	return nil
+}
+
+// GetAliyunDomainList 获取阿里云域名列表，keyWord不为空时按域名模糊搜索，逐页获取直到取完
+func (c *AliyunDnsClient) GetAliyunDomainList(ctx context.Context, keyWord string) ([]AliyunDomain, error) {
+	var domains []AliyunDomain
+	for pageNumber := 1; ; pageNumber++ {
+		result, err := c.GetAliyunDomainPage(ctx, keyWord, pageNumber, aliyunMaxDomainPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		domains = append(domains, result.Domains.Domain...)
+		if len(result.Domains.Domain) < aliyunMaxDomainPageSize || len(domains) >= result.TotalCount {
+			return domains, nil
+		}
+	}
+}
+
+// GetAliyunDomainPage 获取一页阿里云域名，pageNumber从1开始
+func (c *AliyunDnsClient) GetAliyunDomainPage(ctx context.Context, keyWord string, pageNumber, pageSize int) (*AliyunDnsDomainListResponse, error) {
 	params := map[string]string{
 		"PageNumber": fmt.Sprintf("%d", pageNumber),
 		"PageSize":   fmt.Sprintf("%d", pageSize),
 	}
 
-	resp, err := c.makeRequest("DescribeDomains", params)
+	if keyWord != "" {
+		params["KeyWord"] = keyWord
+		params["SearchMode"] = "LIKE"
+	}
+
+	resp, err := c.makeRequest(ctx, "DescribeDomains", params)
 	if err != nil {
 		return nil, err
 	}
@@ -203,30 +321,107 @@ func (c *AliyunDnsClient) GetAliyunDomainList(pageNumber, pageSize int) ([]Aliyu
 		return nil, fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(resp))
 	}
 
-	// 将域名信息转换为DNS记录格式
-	var records []AliyunDnsRecord
-	for _, domain := range result.Domains.Domain {
-		records = append(records, AliyunDnsRecord{
-			DomainName: domain.DomainName,
-			RecordId:   domain.DomainId,
-			Remark:     domain.Remark,
-		})
+	return &result, nil
+}
+
+// AddAliyunDomain 添加域名
+func (c *AliyunDnsClient) AddAliyunDomain(ctx context.Context, domainName string) (*AliyunDomain, error) {
+	params := map[string]string{
+		"DomainName": domainName,
+	}
+
+	resp, err := c.makeRequest(ctx, "AddDomain", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result AliyunDomain
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(resp))
+	}
+
+	return &result, nil
+}
+
+// DeleteAliyunDomain 删除域名，域名下的记录一并删除
+func (c *AliyunDnsClient) DeleteAliyunDomain(ctx context.Context, domainName string) error {
+	params := map[string]string{
+		"DomainName": domainName,
+	}
+
+	_, err := c.makeRequest(ctx, "DeleteDomain", params)
+	return err
+}
+
+// UpdateAliyunDomainRemark 修改域名备注，remark为空时清除备注
+func (c *AliyunDnsClient) UpdateAliyunDomainRemark(ctx context.Context, domainName, remark string) error {
+	params := map[string]string{
+		"DomainName": domainName,
+		"Remark":     remark,
+	}
+
+	_, err := c.makeRequest(ctx, "UpdateDomainRemark", params)
+	return err
+}
+
+// AliyunRecordLine 阿里云解析线路
+type AliyunRecordLine struct {
+	LineCode        string `json:"LineCode"`
+	LineName        string `json:"LineName"`
+	LineDisplayName string `json:"LineDisplayName"`
+	FatherCode      string `json:"FatherCode"`
+}
+
+// DescribeAliyunSupportLines 获取域名支持的解析线路，与云解析版本有关
+func (c *AliyunDnsClient) DescribeAliyunSupportLines(ctx context.Context, domainName string) ([]AliyunRecordLine, error) {
+	params := map[string]string{
+		"DomainName": domainName,
+	}
+
+	resp, err := c.makeRequest(ctx, "DescribeSupportLines", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		RequestId   string `json:"RequestId"`
+		RecordLines struct {
+			RecordLine []AliyunRecordLine `json:"RecordLine"`
+		} `json:"RecordLines"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(resp))
 	}
 
+	return result.RecordLines.RecordLine, nil
+}
+
+// GetAliyunRecordList 获取阿里云DNS记录列表，逐页获取直到取完
+func (c *AliyunDnsClient) GetAliyunRecordList(ctx context.Context, domainName string, rrKeyWord string) ([]AliyunDnsRecord, error) {
+	var records []AliyunDnsRecord
+	pager := c.NewAliyunRecordPager(domainName, rrKeyWord, aliyunMaxPageSize)
+	for pager.Next(ctx) {
+		records = append(records, pager.Records()...)
+	}
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
 	return records, nil
 }
 
-// GetAliyunRecordList 获取阿里云DNS记录列表
-func (c *AliyunDnsClient) GetAliyunRecordList(domainName string, rrKeyWord string) ([]AliyunDnsRecord, error) {
+// GetAliyunRecordPage 获取一页阿里云DNS记录，pageNumber从1开始
+func (c *AliyunDnsClient) GetAliyunRecordPage(ctx context.Context, domainName, rrKeyWord string, pageNumber, pageSize int) (*AliyunDnsRecordListResponse, error) {
 	params := map[string]string{
 		"DomainName": domainName,
+		"PageNumber": fmt.Sprintf("%d", pageNumber),
+		"PageSize":   fmt.Sprintf("%d", pageSize),
 	}
 
 	if rrKeyWord != "" {
-		params["RrKeyWord"] = rrKeyWord
+		params["RRKeyWord"] = rrKeyWord
 	}
 
-	resp, err := c.makeRequest("DescribeDomainRecords", params)
+	resp, err := c.makeRequest(ctx, "DescribeDomainRecords", params)
 	if err != nil {
 		return nil, err
 	}
@@ -236,11 +431,77 @@ func (c *AliyunDnsClient) GetAliyunRecordList(domainName string, rrKeyWord strin
 		return nil, fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(resp))
 	}
 
-	return result.DomainRecords, nil
+	return &result, nil
+}
+
+// AliyunRecordPager 按PageNumber/PageSize逐页获取阿里云DNS记录
+type AliyunRecordPager struct {
+	client     *AliyunDnsClient
+	domainName string
+	rrKeyWord  string
+	pageSize   int
+	pageNumber int
+	fetched    int
+	total      int
+	records    []AliyunDnsRecord
+	done       bool
+	err        error
+}
+
+// NewAliyunRecordPager 创建记录分页器，pageSize为每页记录数
+func (c *AliyunDnsClient) NewAliyunRecordPager(domainName, rrKeyWord string, pageSize int) *AliyunRecordPager {
+	if pageSize <= 0 || pageSize > aliyunMaxPageSize {
+		pageSize = aliyunMaxPageSize
+	}
+	return &AliyunRecordPager{
+		client:     c,
+		domainName: domainName,
+		rrKeyWord:  rrKeyWord,
+		pageSize:   pageSize,
+	}
+}
+
+// Next 获取下一页，没有更多记录或出错时返回false
+func (p *AliyunRecordPager) Next(ctx context.Context) bool {
+	if p.done {
+		return false
+	}
+
+	p.pageNumber++
+	result, err := p.client.GetAliyunRecordPage(ctx, p.domainName, p.rrKeyWord, p.pageNumber, p.pageSize)
+	if err != nil {
+		p.err = err
+		p.done = true
+		return false
+	}
+
+	records := result.DomainRecords.Record
+	p.total = result.TotalCount
+	p.fetched += len(records)
+	p.records = records
+	if len(records) < p.pageSize || p.fetched >= p.total {
+		p.done = true
+	}
+	return len(records) > 0
+}
+
+// Records 当前页的记录
+func (p *AliyunRecordPager) Records() []AliyunDnsRecord {
+	return p.records
+}
+
+// Total 服务商返回的TotalCount
+func (p *AliyunRecordPager) Total() int {
+	return p.total
+}
+
+// Err 分页过程中的错误
+func (p *AliyunRecordPager) Err() error {
+	return p.err
 }
 
 // CreateAliyunRecord 创建阿里云DNS记录
-func (c *AliyunDnsClient) CreateAliyunRecord(domainName, rr, recordType, value string, ttl int64) (*AliyunDnsRecord, error) {
+func (c *AliyunDnsClient) CreateAliyunRecord(ctx context.Context, domainName, rr, recordType, value, line string, ttl, priority int64) (*AliyunDnsRecord, error) {
 	params := map[string]string{
 		"DomainName": domainName,
 		"RR":         rr,
@@ -248,11 +509,19 @@ func (c *AliyunDnsClient) CreateAliyunRecord(domainName, rr, recordType, value s
 		"Value":      value,
 	}
 
+	if line != "" {
+		params["Line"] = line
+	}
+
 	if ttl > 0 {
 		params["TTL"] = fmt.Sprintf("%d", ttl)
 	}
 
-	resp, err := c.makeRequest("AddDomainRecord", params)
+	if priority > 0 {
+		params["Priority"] = fmt.Sprintf("%d", priority)
+	}
+
+	resp, err := c.makeRequest(ctx, "AddDomainRecord", params)
 	if err != nil {
 		return nil, err
 	}
@@ -270,6 +539,8 @@ func (c *AliyunDnsClient) CreateAliyunRecord(domainName, rr, recordType, value s
 		Value:      value,
 		DomainName: domainName,
 		TTL:        ttl,
+		Line:       line,
+		Priority:   priority,
 		Status:     "ENABLE", // 新创建的记录默认是启用的
 	}
 
@@ -277,7 +548,7 @@ func (c *AliyunDnsClient) CreateAliyunRecord(domainName, rr, recordType, value s
 }
 
 // UpdateAliyunRecord 更新阿里云DNS记录
-func (c *AliyunDnsClient) UpdateAliyunRecord(recordId, rr, recordType, value string, ttl int64) (*AliyunDnsRecord, error) {
+func (c *AliyunDnsClient) UpdateAliyunRecord(ctx context.Context, recordId, rr, recordType, value, line string, ttl, priority int64) (*AliyunDnsRecord, error) {
 	params := map[string]string{
 		"RecordId": recordId,
 		"RR":       rr,
@@ -285,11 +556,19 @@ func (c *AliyunDnsClient) UpdateAliyunRecord(recordId, rr, recordType, value str
 		"Value":    value,
 	}
 
+	if line != "" {
+		params["Line"] = line
+	}
+
 	if ttl > 0 {
 		params["TTL"] = fmt.Sprintf("%d", ttl)
 	}
 
-	resp, err := c.makeRequest("UpdateDomainRecord", params)
+	if priority > 0 {
+		params["Priority"] = fmt.Sprintf("%d", priority)
+	}
+
+	resp, err := c.makeRequest(ctx, "UpdateDomainRecord", params)
 	if err != nil {
 		return nil, err
 	}
@@ -306,18 +585,74 @@ func (c *AliyunDnsClient) UpdateAliyunRecord(recordId, rr, recordType, value str
 		Type:     recordType,
 		Value:    value,
 		TTL:      ttl,
+		Line:     line,
+		Priority: priority,
 	}
 
 	return updatedRecord, nil
 }
 
+// GetAliyunRecord 获取单条记录的详情，DescribeDomainRecordInfo的字段直接位于响应的顶层
+func (c *AliyunDnsClient) GetAliyunRecord(ctx context.Context, recordId string) (*AliyunDnsRecord, error) {
+	params := map[string]string{
+		"RecordId": recordId,
+	}
+
+	resp, err := c.makeRequest(ctx, "DescribeDomainRecordInfo", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var record AliyunDnsRecord
+	if err := json.Unmarshal(resp, &record); err != nil {
+		return nil, fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(resp))
+	}
+	return &record, nil
+}
+
+// UpdateAliyunRecordRemark 修改记录备注，remark为空时清除备注
+func (c *AliyunDnsClient) UpdateAliyunRecordRemark(ctx context.Context, recordId, remark string) error {
+	params := map[string]string{
+		"RecordId": recordId,
+		"Remark":   remark,
+	}
+
+	_, err := c.makeRequest(ctx, "UpdateDomainRecordRemark", params)
+	return err
+}
+
+// SetAliyunDNSSLBStatus 开启或关闭子域名的负载均衡，开启后同名记录按权重返回
+//
+// subDomain为完整的子域名，如 www.example.com
+func (c *AliyunDnsClient) SetAliyunDNSSLBStatus(ctx context.Context, domainName, subDomain string, open bool) error {
+	params := map[string]string{
+		"DomainName": domainName,
+		"SubDomain":  subDomain,
+		"Open":       fmt.Sprintf("%t", open),
+	}
+
+	_, err := c.makeRequest(ctx, "SetDNSSLBStatus", params)
+	return err
+}
+
+// UpdateAliyunDNSSLBWeight 修改记录的负载均衡权重，取值1-100
+func (c *AliyunDnsClient) UpdateAliyunDNSSLBWeight(ctx context.Context, recordId string, weight int64) error {
+	params := map[string]string{
+		"RecordId": recordId,
+		"Weight":   fmt.Sprintf("%d", weight),
+	}
+
+	_, err := c.makeRequest(ctx, "UpdateDNSSLBWeight", params)
+	return err
+}
+
 // DeleteAliyunRecord 删除阿里云DNS记录
-func (c *AliyunDnsClient) DeleteAliyunRecord(recordId string) error {
+func (c *AliyunDnsClient) DeleteAliyunRecord(ctx context.Context, recordId string) error {
 	params := map[string]string{
 		"RecordId": recordId,
 	}
 
-	resp, err := c.makeRequest("DeleteDomainRecord", params)
+	resp, err := c.makeRequest(ctx, "DeleteDomainRecord", params)
 	if err != nil {
 		return err
 	}
@@ -331,13 +666,13 @@ func (c *AliyunDnsClient) DeleteAliyunRecord(recordId string) error {
 }
 
 // SetAliyunRecordStatus 设置阿里云DNS记录状态
-func (c *AliyunDnsClient) SetAliyunRecordStatus(recordId, status string) error {
+func (c *AliyunDnsClient) SetAliyunRecordStatus(ctx context.Context, recordId, status string) error {
 	params := map[string]string{
 		"RecordId": recordId,
 		"Status":   status,
 	}
 
-	resp, err := c.makeRequest("SetDomainRecordStatus", params)
+	resp, err := c.makeRequest(ctx, "SetDomainRecordStatus", params)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,140 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestPercentEncode(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"abcXYZ019-_.~", "abcXYZ019-_.~"},
+		{"hello world", "hello%20world"},
+		{"a*b", "a%2Ab"},
+		{"a+b", "a%2Bb"},
+		{"2016-02-23T12:46:24Z", "2016-02-23T12%3A46%3A24Z"},
+		{"备注", "%E5%A4%87%E6%B3%A8"},
+	}
+	for _, tt := range tests {
+		if got := percentEncode(tt.in); got != tt.want {
+			t.Errorf("percentEncode(%q) = %q, 期望 %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// 阿里云API文档“RPC风格签名”中的示例
+func TestAliyunSignExample(t *testing.T) {
+	params := map[string]string{
+		"AccessKeyId":      "testid",
+		"Action":           "DescribeRegions",
+		"Format":           "XML",
+		"SignatureMethod":  "HMAC-SHA1",
+		"SignatureNonce":   "3ee8c1b8-83d3-44af-a94f-4e0ad82fd6cf",
+		"SignatureVersion": "1.0",
+		"Timestamp":        "2016-02-23T12:46:24Z",
+		"Version":          "2014-05-26",
+	}
+	c := NewAliyunDnsClient("testid", "testsecret", "")
+	if got, want := c.sign(params, "testsecret"), "OLeaidS1JvxuMvnyHOwuJ+uX5qY="; got != want {
+		t.Errorf("签名 = %s, 期望 %s", got, want)
+	}
+}
+
+// newTestAliyunPaging 启动按PageNumber/PageSize分页的假DescribeDomainRecords接口
+func newTestAliyunPaging(t *testing.T, total int) (*AliyunDnsClient, *[]string) {
+	t.Helper()
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("AccessKeyId") != "test-id" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"Code": "InvalidAccessKeyId.NotFound", "Message": "Specified access key is not found.", "RequestId": "test-request"})
+			return
+		}
+		if q.Get("Action") != "DescribeDomainRecords" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"Code": "InvalidAction", "Message": q.Get("Action")})
+			return
+		}
+		page, _ := strconv.Atoi(q.Get("PageNumber"))
+		size, _ := strconv.Atoi(q.Get("PageSize"))
+		requests = append(requests, fmt.Sprintf("%d/%d", page, size))
+
+		resp := AliyunDnsRecordListResponse{RequestId: "test-request", TotalCount: total, PageNumber: page, PageSize: size}
+		for i := (page - 1) * size; i < total && i < page*size; i++ {
+			resp.DomainRecords.Record = append(resp.DomainRecords.Record, AliyunDnsRecord{
+				DomainName: q.Get("DomainName"), RecordId: strconv.Itoa(i + 1), Rr: fmt.Sprintf("host%d", i),
+				Type: "A", Value: "192.0.2.1", TTL: 600, Status: "ENABLE", Line: "default",
+			})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+
+	client := NewAliyunDnsClient("test-id", "test-secret", "")
+	client.HTTP = newRedirectedHTTPClient(aliyunThrottled, srv)
+	return client, &requests
+}
+
+func TestAliyunRecordPager(t *testing.T) {
+	tests := []struct {
+		total, pageSize int
+		requests        []string
+	}{
+		{5, 2, []string{"1/2", "2/2", "3/2"}},
+		{4, 2, []string{"1/2", "2/2"}}, // 已取满TotalCount，不再请求
+		{0, 2, []string{"1/2"}},
+	}
+	for _, tt := range tests {
+		client, requests := newTestAliyunPaging(t, tt.total)
+		pager := client.NewAliyunRecordPager("example.com", "", tt.pageSize)
+		var ids []string
+		for pager.Next(context.Background()) {
+			for _, r := range pager.Records() {
+				ids = append(ids, r.RecordId)
+			}
+		}
+		if err := pager.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != tt.total {
+			t.Errorf("共%d条记录时获取到 %v", tt.total, ids)
+		}
+		if fmt.Sprint(*requests) != fmt.Sprint(tt.requests) {
+			t.Errorf("共%d条记录时的请求 = %v, 期望 %v", tt.total, *requests, tt.requests)
+		}
+	}
+}
+
+func TestAliyunProviderGetRecordPage(t *testing.T) {
+	client, _ := newTestAliyunPaging(t, 5)
+	p := NewAliyunProvider(client)
+	ctx := context.Background()
+
+	records, total, err := p.GetRecordPage(ctx, "example.com", "", 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 5 || len(records) != 2 || records[0].Name != "host2" || records[0].Status != RecordStatusEnable {
+		t.Errorf("第2页 = %+v, total = %d", records, total)
+	}
+
+	all, err := p.GetRecordList(ctx, "example.com", "")
+	if err != nil || len(all) != 5 {
+		t.Errorf("全部记录 = %d条, %v", len(all), err)
+	}
+
+	client.AccessKeyId = "wrong-id"
+	_, _, err = p.GetRecordPage(ctx, "example.com", "", 1, 2)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "InvalidAccessKeyId.NotFound" || apiErr.RequestID != "test-request" {
+		t.Errorf("AccessKey错误时返回 %v", err)
+	}
+}
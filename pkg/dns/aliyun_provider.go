@@ -0,0 +1,259 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register(ProviderAliyun, func(cfg Config) (Provider, error) {
+		accessKeyId := cfg.Get("ACCESS_KEY_ID")
+		accessKeySecret := cfg.Get("ACCESS_KEY_SECRET")
+		if accessKeyId == "" || accessKeySecret == "" {
+			return nil, fmt.Errorf("阿里云AccessKey未配置")
+		}
+		client := NewAliyunDnsClient(accessKeyId, accessKeySecret, cfg.Get("REGION_ID"))
+		client.HTTP.Configure(cfg)
+		return NewAliyunProvider(client), nil
+	})
+}
+
+// AliyunProvider 将AliyunDnsClient适配为Provider
+type AliyunProvider struct {
+	client *AliyunDnsClient
+}
+
+// NewAliyunProvider 创建阿里云DNS服务商
+func NewAliyunProvider(client *AliyunDnsClient) *AliyunProvider {
+	return &AliyunProvider{client: client}
+}
+
+// GetDomainList 获取域名列表
+func (p *AliyunProvider) GetDomainList(ctx context.Context) ([]Domain, error) {
+	domains, err := p.client.GetAliyunDomainList(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Domain, 0, len(domains))
+	for _, d := range domains {
+		result = append(result, fromAliyunDomain(d))
+	}
+	return result, nil
+}
+
+// GetDomainPage 按页获取域名列表，keyword按域名模糊搜索
+func (p *AliyunProvider) GetDomainPage(ctx context.Context, keyword string, page, pageSize int) ([]Domain, int, error) {
+	if pageSize > aliyunMaxDomainPageSize {
+		pageSize = aliyunMaxDomainPageSize
+	}
+	resp, err := p.client.GetAliyunDomainPage(ctx, keyword, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]Domain, 0, len(resp.Domains.Domain))
+	for _, d := range resp.Domains.Domain {
+		result = append(result, fromAliyunDomain(d))
+	}
+	return result, resp.TotalCount, nil
+}
+
+// GetRecordList 获取记录列表
+func (p *AliyunProvider) GetRecordList(ctx context.Context, domain, subDomain string) ([]Record, error) {
+	records, err := p.client.GetAliyunRecordList(ctx, domain, subDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Record, 0, len(records))
+	for _, r := range records {
+		result = append(result, fromAliyunRecord(r))
+	}
+	return result, nil
+}
+
+// GetRecordPage 按页获取记录列表
+func (p *AliyunProvider) GetRecordPage(ctx context.Context, domain, subDomain string, page, pageSize int) ([]Record, int, error) {
+	resp, err := p.client.GetAliyunRecordPage(ctx, domain, subDomain, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]Record, 0, len(resp.DomainRecords.Record))
+	for _, r := range resp.DomainRecords.Record {
+		result = append(result, fromAliyunRecord(r))
+	}
+	return result, resp.TotalCount, nil
+}
+
+// CreateRecord 创建记录，domain为域名名称；备注和权重通过单独的接口设置
+func (p *AliyunProvider) CreateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	created, err := p.client.CreateAliyunRecord(ctx, domain, record.Name, record.Type, record.Value, record.Line, record.TTL, record.mxPriority())
+	if err != nil {
+		return nil, err
+	}
+
+	result := fromAliyunRecord(*created)
+	if err := p.setRecordOptions(ctx, domain, &result, record); err != nil {
+		return &result, fmt.Errorf("记录已创建，%w", err)
+	}
+	return &result, nil
+}
+
+// UpdateRecord 更新记录
+func (p *AliyunProvider) UpdateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	updated, err := p.client.UpdateAliyunRecord(ctx, record.ID, record.Name, record.Type, record.Value, record.Line, record.TTL, record.mxPriority())
+	if err != nil {
+		return nil, err
+	}
+
+	result := fromAliyunRecord(*updated)
+	result.Domain = domain
+	if err := p.setRecordOptions(ctx, domain, &result, record); err != nil {
+		return &result, fmt.Errorf("记录已修改，%w", err)
+	}
+	return &result, nil
+}
+
+// setRecordOptions 设置记录的备注和权重，为空时不修改；指定了RemarkSet时空备注表示清除
+//
+// 阿里云的权重属于负载均衡配置，设置权重前先开启子域名的负载均衡
+func (p *AliyunProvider) setRecordOptions(ctx context.Context, domain string, result *Record, record Record) error {
+	if record.Remark != "" || record.RemarkSet {
+		if err := p.client.UpdateAliyunRecordRemark(ctx, result.ID, record.Remark); err != nil {
+			return fmt.Errorf("设置备注失败: %w", err)
+		}
+		result.Remark = record.Remark
+	}
+
+	if record.Weight > 0 {
+		if err := p.client.SetAliyunDNSSLBStatus(ctx, domain, toFQDN(record.Name, domain), true); err != nil {
+			return fmt.Errorf("开启负载均衡失败: %w", err)
+		}
+		if err := p.client.UpdateAliyunDNSSLBWeight(ctx, result.ID, record.Weight); err != nil {
+			return fmt.Errorf("设置权重失败: %w", err)
+		}
+		result.Weight = record.Weight
+	}
+	return nil
+}
+
+// GetRecord 获取单条记录，阿里云按记录ID获取，无需域名
+func (p *AliyunProvider) GetRecord(ctx context.Context, domain, recordID string) (*Record, error) {
+	record, err := p.client.GetAliyunRecord(ctx, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := fromAliyunRecord(*record)
+	return &result, nil
+}
+
+// DeleteRecord 删除记录，阿里云按记录ID删除，无需域名
+func (p *AliyunProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	return p.client.DeleteAliyunRecord(ctx, recordID)
+}
+
+// SetRecordStatus 设置记录状态，阿里云使用大写的ENABLE/DISABLE
+func (p *AliyunProvider) SetRecordStatus(ctx context.Context, domain, recordID, status string) error {
+	return p.client.SetAliyunRecordStatus(ctx, recordID, strings.ToUpper(status))
+}
+
+// CreateDomain 添加域名
+func (p *AliyunProvider) CreateDomain(ctx context.Context, name string) (*Domain, error) {
+	created, err := p.client.AddAliyunDomain(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	domain := fromAliyunDomain(*created)
+	domain.Status = RecordStatusEnable
+	return &domain, nil
+}
+
+// DeleteDomain 删除域名，domain为域名名称
+func (p *AliyunProvider) DeleteDomain(ctx context.Context, domain string) error {
+	return p.client.DeleteAliyunDomain(ctx, domain)
+}
+
+// SetDomainStatus 阿里云不能暂停整个域名
+func (p *AliyunProvider) SetDomainStatus(ctx context.Context, domain, status string) error {
+	return errorf(ErrUnsupported, "阿里云不支持设置域名状态")
+}
+
+// SetDomainRemark 设置域名备注，domain为域名名称
+func (p *AliyunProvider) SetDomainRemark(ctx context.Context, domain, remark string) error {
+	return p.client.UpdateAliyunDomainRemark(ctx, domain, remark)
+}
+
+// LockDomain 阿里云没有域名锁定
+func (p *AliyunProvider) LockDomain(ctx context.Context, domain string, days int) (string, error) {
+	return "", errorf(ErrUnsupported, "阿里云不支持锁定域名")
+}
+
+// UnlockDomain 阿里云没有域名锁定
+func (p *AliyunProvider) UnlockDomain(ctx context.Context, domain, lockCode string) error {
+	return errorf(ErrUnsupported, "阿里云不支持锁定域名")
+}
+
+// GetLineList 获取域名可用的线路，阿里云接口中使用线路代码，如 default、telecom
+func (p *AliyunProvider) GetLineList(ctx context.Context, domain string) ([]Line, error) {
+	aliyunLines, err := p.client.DescribeAliyunSupportLines(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]Line, 0, len(aliyunLines))
+	for _, l := range aliyunLines {
+		name := l.LineDisplayName
+		if name == "" {
+			name = l.LineName
+		}
+		lines = append(lines, Line{
+			Code:   l.LineCode,
+			Name:   name,
+			Parent: l.FatherCode,
+		})
+	}
+	return lines, nil
+}
+
+// fromAliyunDomain 转换为统一域名结构，版本代码作为域名等级
+func fromAliyunDomain(d AliyunDomain) Domain {
+	domain := Domain{
+		ID:          d.DomainId,
+		Name:        d.DomainName,
+		PunyCode:    d.PunyCode,
+		Grade:       d.VersionCode,
+		RecordCount: d.RecordCount,
+		Remark:      d.Remark,
+		NameServers: d.DnsServers.DnsServer,
+		Group:       d.GroupName,
+	}
+	if len(d.Tags.Tag) > 0 {
+		domain.Tags = make(map[string]string, len(d.Tags.Tag))
+		for _, tag := range d.Tags.Tag {
+			domain.Tags[tag.Key] = tag.Value
+		}
+	}
+	return domain
+}
+
+// fromAliyunRecord 转换为统一记录结构
+func fromAliyunRecord(r AliyunDnsRecord) Record {
+	return Record{
+		ID:       r.RecordId,
+		Domain:   r.DomainName,
+		Name:     r.Rr,
+		Type:     r.Type,
+		Value:    r.Value,
+		TTL:      r.TTL,
+		Priority: r.Priority,
+		Weight:   r.Weight,
+		Line:     r.Line,
+		Status:   strings.ToLower(r.Status),
+		Remark:   r.Remark,
+	}
+}
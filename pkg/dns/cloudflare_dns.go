@@ -0,0 +1,260 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// CloudflareBaseURL Cloudflare v4 API地址
+const CloudflareBaseURL = "https://api.cloudflare.com/client/v4"
+
+// CloudflareClient Cloudflare API客户端
+type CloudflareClient struct {
+	APIToken string
+	BaseURL  string
+	HTTP     *HTTPClient
+}
+
+// CloudflareZone Cloudflare域名(Zone)结构
+type CloudflareZone struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Status      string   `json:"status"`
+	Paused      bool     `json:"paused"`
+	Type        string   `json:"type"`
+	NameServers []string `json:"name_servers"`
+	Plan        struct {
+		Name string `json:"name"`
+	} `json:"plan"`
+}
+
+// CloudflareDnsRecord Cloudflare DNS记录结构
+type CloudflareDnsRecord struct {
+	ID       string `json:"id,omitempty"`
+	ZoneID   string `json:"zone_id,omitempty"`
+	ZoneName string `json:"zone_name,omitempty"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Content  string `json:"content"`
+	TTL      int64  `json:"ttl"`
+	Priority *int64 `json:"priority,omitempty"`
+	Proxied  bool   `json:"proxied"`
+	Comment  string `json:"comment"` // PUT整条替换记录，空字符串表示清除备注
+}
+
+// CloudflareError Cloudflare API错误
+type CloudflareError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// CloudflareResultInfo Cloudflare分页信息
+type CloudflareResultInfo struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Count      int `json:"count"`
+	TotalCount int `json:"total_count"`
+	TotalPages int `json:"total_pages"`
+}
+
+// CloudflareResponse Cloudflare API响应
+type CloudflareResponse struct {
+	Success    bool                  `json:"success"`
+	Errors     []CloudflareError     `json:"errors"`
+	Result     json.RawMessage       `json:"result"`
+	ResultInfo *CloudflareResultInfo `json:"result_info"`
+}
+
+// NewCloudflareClient 创建Cloudflare客户端，baseURL为空时使用官方地址
+func NewCloudflareClient(apiToken, baseURL string) *CloudflareClient {
+	if baseURL == "" {
+		baseURL = CloudflareBaseURL
+	}
+	return &CloudflareClient{
+		APIToken: apiToken,
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		HTTP:     NewHTTPClient(nil),
+	}
+}
+
+// makeRequest 发起Cloudflare API请求，result不为nil时解析响应中的result字段
+func (c *CloudflareClient) makeRequest(ctx context.Context, method, path string, query url.Values, body interface{}, result interface{}) (*CloudflareResultInfo, error) {
+	urlStr := c.BaseURL + path
+	if len(query) > 0 {
+		urlStr += "?" + query.Encode()
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	// POST创建记录不是幂等的
+	resp, data, err := c.HTTP.Do(ctx, method != "POST", func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, urlStr, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.APIToken)
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var response CloudflareResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(data))
+	}
+
+	if !response.Success {
+		if len(response.Errors) > 0 {
+			kind := cloudflareErrorKind(response.Errors[0].Code)
+			if kind == nil {
+				kind = kindFromStatus(resp.StatusCode)
+			}
+			return nil, &APIError{
+				Kind:       kind,
+				Code:       strconv.Itoa(response.Errors[0].Code),
+				Message:    response.Errors[0].Message,
+				RequestID:  resp.Header.Get("Cf-Ray"),
+				StatusCode: resp.StatusCode,
+			}
+		}
+		return nil, fmt.Errorf("API请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(data))
+	}
+
+	if result != nil && len(response.Result) > 0 {
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return nil, fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(data))
+		}
+	}
+
+	return response.ResultInfo, nil
+}
+
+// GetZoneList 获取全部Zone，按页读取直到结束
+func (c *CloudflareClient) GetZoneList(ctx context.Context, name string) ([]CloudflareZone, error) {
+	var zones []CloudflareZone
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("page", fmt.Sprintf("%d", page))
+		query.Set("per_page", "50")
+		if name != "" {
+			query.Set("name", name)
+		}
+
+		var result []CloudflareZone
+		info, err := c.makeRequest(ctx, "GET", "/zones", query, nil, &result)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, result...)
+
+		if info == nil || page >= info.TotalPages {
+			break
+		}
+	}
+	return zones, nil
+}
+
+// GetZone 获取Zone详情
+func (c *CloudflareClient) GetZone(ctx context.Context, zoneID string) (*CloudflareZone, error) {
+	var zone CloudflareZone
+	if _, err := c.makeRequest(ctx, "GET", "/zones/"+zoneID, nil, nil, &zone); err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// GetRecordList 获取Zone下的DNS记录，name为完整域名，为空时返回全部记录
+func (c *CloudflareClient) GetRecordList(ctx context.Context, zoneID, name string) ([]CloudflareDnsRecord, error) {
+	var records []CloudflareDnsRecord
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("page", fmt.Sprintf("%d", page))
+		query.Set("per_page", "500")
+		if name != "" {
+			query.Set("name", name)
+		}
+
+		var result []CloudflareDnsRecord
+		info, err := c.makeRequest(ctx, "GET", "/zones/"+zoneID+"/dns_records", query, nil, &result)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, result...)
+
+		if info == nil || page >= info.TotalPages {
+			break
+		}
+	}
+	return records, nil
+}
+
+// GetRecord 获取单条DNS记录
+func (c *CloudflareClient) GetRecord(ctx context.Context, zoneID, recordID string) (*CloudflareDnsRecord, error) {
+	var result CloudflareDnsRecord
+	if _, err := c.makeRequest(ctx, "GET", "/zones/"+zoneID+"/dns_records/"+recordID, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CreateRecord 创建DNS记录
+func (c *CloudflareClient) CreateRecord(ctx context.Context, zoneID string, record CloudflareDnsRecord) (*CloudflareDnsRecord, error) {
+	var result CloudflareDnsRecord
+	if _, err := c.makeRequest(ctx, "POST", "/zones/"+zoneID+"/dns_records", nil, record, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// UpdateRecord 更新DNS记录
+func (c *CloudflareClient) UpdateRecord(ctx context.Context, zoneID, recordID string, record CloudflareDnsRecord) (*CloudflareDnsRecord, error) {
+	var result CloudflareDnsRecord
+	if _, err := c.makeRequest(ctx, "PUT", "/zones/"+zoneID+"/dns_records/"+recordID, nil, record, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// DeleteRecord 删除DNS记录
+func (c *CloudflareClient) DeleteRecord(ctx context.Context, zoneID, recordID string) error {
+	_, err := c.makeRequest(ctx, "DELETE", "/zones/"+zoneID+"/dns_records/"+recordID, nil, nil, nil)
+	return err
+}
+
+// cloudflareErrorKind Cloudflare错误码对应的错误类别
+func cloudflareErrorKind(code int) error {
+	switch code {
+	case 81057, 81058: // 相同的记录已经存在
+		return ErrAlreadyExists
+	case 81053, 81054: // 同名的A、AAAA、CNAME记录不能共存
+		return ErrConflict
+	case 81044, 7000, 7003: // 记录不存在、无法路由到该区域
+		return ErrNotFound
+	case 6003, 9103, 9106, 9109, 10000: // 认证失败
+		return ErrAuthFailed
+	case 971, 10429:
+		return ErrThrottled
+	case 81045: // 记录数超出限制
+		return ErrQuotaExceeded
+	case 1004:
+		return ErrInvalidParam
+	}
+	if code >= 9000 && code < 10000 {
+		return ErrInvalidParam // DNS记录校验错误
+	}
+	return nil
+}
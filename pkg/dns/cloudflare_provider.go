@@ -0,0 +1,226 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+func init() {
+	Register(ProviderCloudflare, func(cfg Config) (Provider, error) {
+		token := cfg.Get("API_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("Cloudflare API Token未配置")
+		}
+		client := NewCloudflareClient(token, cfg.Get("BASE_URL"))
+		client.HTTP.Configure(cfg)
+		return NewCloudflareProvider(client), nil
+	})
+}
+
+// cloudflareZoneID Cloudflare的Zone ID为32位十六进制字符串
+var cloudflareZoneID = regexp.MustCompile(`^[0-9a-f]{32}$`)
+
+// CloudflareProvider 将CloudflareClient适配为Provider
+type CloudflareProvider struct {
+	client *CloudflareClient
+
+	mu    sync.Mutex
+	zones map[string]CloudflareZone // 按Zone ID和名称缓存
+}
+
+// NewCloudflareProvider 创建Cloudflare服务商
+func NewCloudflareProvider(client *CloudflareClient) *CloudflareProvider {
+	return &CloudflareProvider{
+		client: client,
+		zones:  make(map[string]CloudflareZone),
+	}
+}
+
+// zone 根据域名或Zone ID查找Zone
+func (p *CloudflareProvider) zone(ctx context.Context, domain string) (CloudflareZone, error) {
+	p.mu.Lock()
+	zone, ok := p.zones[domain]
+	p.mu.Unlock()
+	if ok {
+		return zone, nil
+	}
+
+	if cloudflareZoneID.MatchString(domain) {
+		z, err := p.client.GetZone(ctx, domain)
+		if err != nil {
+			return CloudflareZone{}, err
+		}
+		zone = *z
+	} else {
+		zones, err := p.client.GetZoneList(ctx, domain)
+		if err != nil {
+			return CloudflareZone{}, err
+		}
+		if len(zones) == 0 {
+			return CloudflareZone{}, errorf(ErrNotFound, "Cloudflare域名 %s 不存在", domain)
+		}
+		zone = zones[0]
+	}
+
+	p.mu.Lock()
+	p.zones[zone.ID] = zone
+	p.zones[zone.Name] = zone
+	p.mu.Unlock()
+	return zone, nil
+}
+
+// GetDomainList 获取域名列表
+func (p *CloudflareProvider) GetDomainList(ctx context.Context) ([]Domain, error) {
+	zones, err := p.client.GetZoneList(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Domain, 0, len(zones))
+	for _, z := range zones {
+		result = append(result, Domain{
+			ID:     z.ID,
+			Name:   z.Name,
+			Status: z.Status,
+			Grade:  z.Plan.Name,
+		})
+	}
+	return result, nil
+}
+
+// GetRecordList 获取记录列表
+func (p *CloudflareProvider) GetRecordList(ctx context.Context, domain, subDomain string) ([]Record, error) {
+	zone, err := p.zone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	name := ""
+	if subDomain != "" {
+		name = toFQDN(subDomain, zone.Name)
+	}
+	records, err := p.client.GetRecordList(ctx, zone.ID, name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Record, 0, len(records))
+	for _, r := range records {
+		result = append(result, fromCloudflareRecord(zone.Name, r))
+	}
+	return result, nil
+}
+
+// GetRecord 获取单条记录
+func (p *CloudflareProvider) GetRecord(ctx context.Context, domain, recordID string) (*Record, error) {
+	zone, err := p.zone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	record, err := p.client.GetRecord(ctx, zone.ID, recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := fromCloudflareRecord(zone.Name, *record)
+	return &result, nil
+}
+
+// CreateRecord 创建记录
+func (p *CloudflareProvider) CreateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	zone, err := p.zone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := p.client.CreateRecord(ctx, zone.ID, toCloudflareRecord(zone.Name, record))
+	if err != nil {
+		return nil, err
+	}
+
+	result := fromCloudflareRecord(zone.Name, *created)
+	return &result, nil
+}
+
+// UpdateRecord 更新记录
+//
+// Cloudflare的PUT会整条替换记录，未指定备注时先读取原记录的备注保留下来
+func (p *CloudflareProvider) UpdateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	zone, err := p.zone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Remark == "" && !record.RemarkSet {
+		current, err := p.client.GetRecord(ctx, zone.ID, record.ID)
+		if err != nil {
+			return nil, err
+		}
+		record.Remark = current.Comment
+	}
+
+	updated, err := p.client.UpdateRecord(ctx, zone.ID, record.ID, toCloudflareRecord(zone.Name, record))
+	if err != nil {
+		return nil, err
+	}
+
+	result := fromCloudflareRecord(zone.Name, *updated)
+	return &result, nil
+}
+
+// DeleteRecord 删除记录
+func (p *CloudflareProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	zone, err := p.zone(ctx, domain)
+	if err != nil {
+		return err
+	}
+	return p.client.DeleteRecord(ctx, zone.ID, recordID)
+}
+
+// SetRecordStatus Cloudflare记录没有启用/暂停状态
+func (p *CloudflareProvider) SetRecordStatus(ctx context.Context, domain, recordID, status string) error {
+	return errorf(ErrUnsupported, "Cloudflare不支持设置记录状态")
+}
+
+// toCloudflareRecord 转换为Cloudflare记录结构，TTL为1表示自动
+func toCloudflareRecord(zone string, record Record) CloudflareDnsRecord {
+	ttl := record.TTL
+	if ttl <= 0 {
+		ttl = 1
+	}
+	r := CloudflareDnsRecord{
+		Name:    toFQDN(record.Name, zone),
+		Type:    record.Type,
+		Content: record.Value,
+		TTL:     ttl,
+		Proxied: record.Proxied,
+		Comment: record.Remark,
+	}
+	if record.Type == "MX" || record.Type == "SRV" || record.Type == "URI" {
+		priority := record.Priority
+		r.Priority = &priority
+	}
+	return r
+}
+
+// fromCloudflareRecord 转换为统一记录结构
+func fromCloudflareRecord(zone string, r CloudflareDnsRecord) Record {
+	record := Record{
+		ID:      r.ID,
+		Domain:  zone,
+		Name:    toRelativeName(r.Name, zone),
+		Type:    r.Type,
+		Value:   r.Content,
+		TTL:     r.TTL,
+		Status:  RecordStatusEnable,
+		Remark:  r.Comment,
+		Proxied: r.Proxied,
+	}
+	if r.Priority != nil {
+		record.Priority = *r.Priority
+	}
+	return record
+}
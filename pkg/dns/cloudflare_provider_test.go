@@ -0,0 +1,317 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testCloudflareToken  = "test-token"
+	testCloudflareZoneID = "023e105f4ecef8ad9ca31a8372d0c353"
+)
+
+// fakeCloudflare 进程内的Cloudflare v4 API，按pageSize分页，PUT与真实接口一样整条替换记录
+type fakeCloudflare struct {
+	mu       sync.Mutex
+	zones    []CloudflareZone
+	records  []CloudflareDnsRecord
+	nextID   int
+	pageSize int
+	requests []string // 收到的请求，如 "PUT /zones/.../dns_records/1"
+}
+
+func (f *fakeCloudflare) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	if r.Header.Get("Authorization") != "Bearer "+testCloudflareToken {
+		f.fail(w, http.StatusForbidden, 10000, "Authentication error")
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "zones" && r.Method == http.MethodGet:
+		var zones []CloudflareZone
+		for _, z := range f.zones {
+			if name := r.URL.Query().Get("name"); name == "" || name == z.Name {
+				zones = append(zones, z)
+			}
+		}
+		f.page(w, r, len(zones), func(i int) interface{} { return zones[i] })
+	case len(parts) == 2 && parts[0] == "zones" && r.Method == http.MethodGet:
+		for _, z := range f.zones {
+			if z.ID == parts[1] {
+				f.ok(w, z)
+				return
+			}
+		}
+		f.fail(w, http.StatusNotFound, 7003, "Could not route to /zones/"+parts[1])
+	case len(parts) >= 3 && parts[0] == "zones" && parts[1] == testCloudflareZoneID && parts[2] == "dns_records":
+		recordID := ""
+		if len(parts) == 4 {
+			recordID = parts[3]
+		}
+		f.serveRecords(w, r, recordID)
+	default:
+		f.fail(w, http.StatusNotFound, 7003, "Could not route to "+r.URL.Path)
+	}
+}
+
+func (f *fakeCloudflare) serveRecords(w http.ResponseWriter, r *http.Request, recordID string) {
+	index := -1
+	for i, record := range f.records {
+		if record.ID == recordID {
+			index = i
+		}
+	}
+	if recordID != "" && index < 0 {
+		f.fail(w, http.StatusNotFound, 81044, "Record does not exist.")
+		return
+	}
+
+	var body CloudflareDnsRecord
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			f.fail(w, http.StatusBadRequest, 1004, err.Error())
+			return
+		}
+		for i, record := range f.records {
+			if i != index && record.Name == body.Name && record.Type == body.Type && record.Content == body.Content {
+				f.fail(w, http.StatusBadRequest, 81057, "Record already exists.")
+				return
+			}
+		}
+	}
+
+	switch {
+	case r.Method == http.MethodGet && recordID == "":
+		var records []CloudflareDnsRecord
+		for _, record := range f.records {
+			if name := r.URL.Query().Get("name"); name == "" || name == record.Name {
+				records = append(records, record)
+			}
+		}
+		f.page(w, r, len(records), func(i int) interface{} { return records[i] })
+	case r.Method == http.MethodGet:
+		f.ok(w, f.records[index])
+	case r.Method == http.MethodPost:
+		f.nextID++
+		body.ID = strconv.Itoa(f.nextID)
+		body.ZoneID, body.ZoneName = testCloudflareZoneID, "example.com"
+		f.records = append(f.records, body)
+		f.ok(w, body)
+	case r.Method == http.MethodPut:
+		body.ID = recordID
+		body.ZoneID, body.ZoneName = testCloudflareZoneID, "example.com"
+		f.records[index] = body
+		f.ok(w, body)
+	case r.Method == http.MethodDelete:
+		f.records = append(f.records[:index], f.records[index+1:]...)
+		f.ok(w, map[string]string{"id": recordID})
+	}
+}
+
+// page 按page参数返回第几页
+func (f *fakeCloudflare) page(w http.ResponseWriter, r *http.Request, total int, item func(int) interface{}) {
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	if page < 1 {
+		page = 1
+	}
+	result := []interface{}{}
+	for i := (page - 1) * f.pageSize; i < total && i < page*f.pageSize; i++ {
+		result = append(result, item(i))
+	}
+	f.write(w, http.StatusOK, true, result, nil, &CloudflareResultInfo{
+		Page:       page,
+		PerPage:    f.pageSize,
+		Count:      len(result),
+		TotalCount: total,
+		TotalPages: (total + f.pageSize - 1) / f.pageSize,
+	})
+}
+
+func (f *fakeCloudflare) ok(w http.ResponseWriter, result interface{}) {
+	f.write(w, http.StatusOK, true, result, nil, nil)
+}
+
+func (f *fakeCloudflare) fail(w http.ResponseWriter, status, code int, message string) {
+	f.write(w, status, false, nil, []CloudflareError{{Code: code, Message: message}}, nil)
+}
+
+func (f *fakeCloudflare) write(w http.ResponseWriter, status int, success bool, result interface{}, errs []CloudflareError, info *CloudflareResultInfo) {
+	raw, _ := json.Marshal(result)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cf-Ray", "test-ray")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(CloudflareResponse{Success: success, Errors: errs, Result: raw, ResultInfo: info})
+}
+
+// newTestCloudflareProvider 启动假的Cloudflare API，返回连接到它的服务商
+func newTestCloudflareProvider(t *testing.T) (*fakeCloudflare, *CloudflareProvider) {
+	t.Helper()
+	f := &fakeCloudflare{
+		zones: []CloudflareZone{
+			{ID: testCloudflareZoneID, Name: "example.com", Status: "active"},
+			{ID: "0123456789abcdef0123456789abcdef", Name: "example.net", Status: "pending"},
+			{ID: "fedcba9876543210fedcba9876543210", Name: "example.org", Status: "active"},
+		},
+		pageSize: 2,
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	client := NewCloudflareClient(testCloudflareToken, srv.URL+"/")
+	client.HTTP = newTestHTTPClient(nil)
+	return f, NewCloudflareProvider(client)
+}
+
+func TestCloudflareProvider(t *testing.T) {
+	f, p := newTestCloudflareProvider(t)
+	ctx := context.Background()
+
+	// 域名列表跨越多页
+	domains, err := p.GetDomainList(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 3 || domains[2].Name != "example.org" {
+		t.Errorf("域名列表 = %+v", domains)
+	}
+
+	// 创建，TTL为0时使用自动TTL
+	created, err := p.CreateRecord(ctx, "example.com", Record{Name: "www", Type: "A", Value: "192.0.2.1", Proxied: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.Name != "www" || created.TTL != 1 || !created.Proxied {
+		t.Errorf("创建的记录 = %+v", created)
+	}
+	mx, err := p.CreateRecord(ctx, "example.com", Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 10, TTL: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mx.Name != "@" || mx.Priority != 10 || mx.TTL != 300 {
+		t.Errorf("MX记录 = %+v", mx)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := p.CreateRecord(ctx, testCloudflareZoneID, Record{Name: fmt.Sprintf("host%d", i), Type: "A", Value: "192.0.2.10", TTL: 60}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 重复的记录返回ErrAlreadyExists，保留服务商的错误码和请求ID
+	_, err = p.CreateRecord(ctx, "example.com", Record{Name: "www", Type: "A", Value: "192.0.2.1"})
+	var apiErr *APIError
+	if !errors.Is(err, ErrAlreadyExists) || !errors.As(err, &apiErr) || apiErr.Code != "81057" || apiErr.RequestID != "test-ray" {
+		t.Errorf("创建重复的记录返回 %v", err)
+	}
+
+	// 记录列表跨越多页
+	records, err := p.GetRecordList(ctx, "example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 5 {
+		t.Errorf("记录列表有 %d 条，期望5条: %+v", len(records), records)
+	}
+	records, err = p.GetRecordList(ctx, "example.com", "www")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].ID != created.ID {
+		t.Errorf("按主机记录过滤 = %+v", records)
+	}
+
+	// 修改、获取
+	updated, err := p.UpdateRecord(ctx, "example.com", Record{ID: created.ID, Name: "www", Type: "A", Value: "192.0.2.2", TTL: 120})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Value != "192.0.2.2" || updated.TTL != 120 || updated.Proxied {
+		t.Errorf("修改后的记录 = %+v", updated)
+	}
+	got, err := p.GetRecord(ctx, "example.com", created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Value != "192.0.2.2" {
+		t.Errorf("获取的记录 = %+v", got)
+	}
+
+	// 删除后获取返回ErrNotFound
+	if err := p.DeleteRecord(ctx, "example.com", created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GetRecord(ctx, "example.com", created.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("获取已删除的记录返回 %v", err)
+	}
+
+	if _, err := p.GetRecordList(ctx, "missing.com", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("不存在的域名返回 %v", err)
+	}
+	if err := p.SetRecordStatus(ctx, "example.com", mx.ID, RecordStatusDisable); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("设置记录状态返回 %v", err)
+	}
+
+	// Zone按名称查询一次后缓存
+	lookups := 0
+	for _, req := range f.requests {
+		if req == "GET /zones" {
+			lookups++
+		}
+	}
+	if lookups != 2+2 { // 域名列表2页，example.com和missing.com各查询一次
+		t.Errorf("查询Zone %d 次: %v", lookups, f.requests)
+	}
+}
+
+func TestCloudflareAuthFailed(t *testing.T) {
+	_, p := newTestCloudflareProvider(t)
+	p.client.APIToken = "wrong-token"
+	if _, err := p.GetDomainList(context.Background()); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("令牌错误时返回 %v, 期望ErrAuthFailed", err)
+	}
+}
+
+func TestCloudflareRecordRemark(t *testing.T) {
+	f, p := newTestCloudflareProvider(t)
+	ctx := context.Background()
+
+	created, err := p.CreateRecord(ctx, "example.com", Record{Name: "www", Type: "A", Value: "192.0.2.1", Remark: "web server"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 未指定备注时保留原备注
+	updated, err := p.UpdateRecord(ctx, "example.com", Record{ID: created.ID, Name: "www", Type: "A", Value: "192.0.2.2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Remark != "web server" || f.records[0].Comment != "web server" {
+		t.Errorf("未指定备注时修改后的记录 = %+v", updated)
+	}
+
+	// 指定空备注时清除
+	updated, err = p.UpdateRecord(ctx, "example.com", Record{ID: created.ID, Name: "www", Type: "A", Value: "192.0.2.2", RemarkSet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Remark != "" || f.records[0].Comment != "" {
+		t.Errorf("清除备注后的记录 = %+v", updated)
+	}
+
+	// 修改不存在的记录返回ErrNotFound
+	if _, err := p.UpdateRecord(ctx, "example.com", Record{ID: "missing", Name: "www", Type: "A", Value: "192.0.2.3"}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("修改不存在的记录返回 %v", err)
+	}
+}
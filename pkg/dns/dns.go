@@ -1,16 +1,19 @@
 package dns
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 )
 
 // DnsPodClient DNSPod API客户端
 type DnsPodClient struct {
 	Token string
+	HTTP  *HTTPClient
 }
 
 // DnsRecord DNS记录结构
@@ -19,6 +22,8 @@ type DnsRecord struct {
 	Name      string `json:"name"`
 	Type      string `json:"type"`
 	Value     string `json:"value"`
+	TTL       string `json:"ttl"`
+	MX        string `json:"mx"`
 	Status    string `json:"status"`
 	Weight    string `json:"weight,omitempty"`
 	Line      string `json:"line,omitempty"`
@@ -28,6 +33,22 @@ type DnsRecord struct {
 	UpdatedOn string `json:"updated_on"`
 }
 
+// DnsRecordInfo Record.Info返回的记录详情，字段名与记录列表不同
+type DnsRecordInfo struct {
+	ID           string    `json:"id"`
+	SubDomain    string    `json:"sub_domain"`
+	RecordType   string    `json:"record_type"`
+	RecordLine   string    `json:"record_line"`
+	RecordLineID string    `json:"record_line_id"`
+	Value        string    `json:"value"`
+	Weight       DnsPodInt `json:"weight"`
+	MX           DnsPodInt `json:"mx"`
+	TTL          DnsPodInt `json:"ttl"`
+	Enabled      string    `json:"enabled"` // 1启用，0暂停
+	Remark       string    `json:"remark"`
+	UpdatedOn    string    `json:"updated_on"`
+}
+
 // DnsRecordListResponse DNS记录列表响应
 type DnsRecordListResponse struct {
 	Status   DnsStatus   `json:"status"`
@@ -46,12 +67,34 @@ type DnsStatus struct {
 
 // DnsInfo API信息
 type DnsInfo struct {
-	SubDomainItems int `json:"sub_domain_items"`
-	RecordTotal    int `json:"record_total"`
-	PageLimit      int `json:"page_limit"`
-	Page           int `json:"page"`
+	SubDomainItems DnsPodInt `json:"sub_domain_items"`
+	RecordTotal    DnsPodInt `json:"record_total"`
+	RecordsNum     DnsPodInt `json:"records_num"`
+	PageLimit      DnsPodInt `json:"page_limit"`
+	Page           DnsPodInt `json:"page"`
 }
 
+// DnsPodInt DNSPod的数量字段有时以字符串返回，如 "record_total": "120"
+type DnsPodInt int
+
+// UnmarshalJSON 同时接受数字和数字字符串
+func (n *DnsPodInt) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*n = 0
+		return nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+	*n = DnsPodInt(v)
+	return nil
+}
+
+// DNSPod Record.List 单页最多返回的记录数
+const dnsPodMaxPageSize = 3000
+
 // DnsDomain 域名信息
 type DnsDomain struct {
 	ID               int           `json:"id"` // 修复：ID字段应为int类型，不是string
@@ -63,7 +106,6 @@ type DnsDomain struct {
 	GradeLevel       int           `json:"grade_level"`
 	GradeTitle       string        `json:"grade_title"`
 	IsVip            string        `json:"is_vip"`
-	OwnerEmail       string        `json:"owner"`
 	Records          string        `json:"records"`
 	CreatedOn        string        `json:"created_on"`
 	UpdatedOn        string        `json:"updated_on"`
@@ -84,13 +126,12 @@ type DnsDomain struct {
 func NewDnsPodClient(token string) *DnsPodClient {
 	return &DnsPodClient{
 		Token: token,
+		HTTP:  NewHTTPClient(dnsPodThrottled),
 	}
 }
 
-// makeRequest 发起HTTP请求
-func (c *DnsPodClient) makeRequest(method, url string, params map[string]string) ([]byte, error) {
-	client := &http.Client{}
-
+// makeRequest 发起HTTP请求，创建记录的请求不会在服务商可能已处理后重试
+func (c *DnsPodClient) makeRequest(ctx context.Context, method, apiURL string, params map[string]string) ([]byte, error) {
 	// 添加认证参数
 	if params == nil {
 		params = make(map[string]string)
@@ -98,46 +139,27 @@ func (c *DnsPodClient) makeRequest(method, url string, params map[string]string)
 	params["login_token"] = c.Token
 	params["format"] = "json"
 
-	// 构建请求体
-	var req *http.Request
-	var err error
-
-	if method == "GET" {
-		// 构建查询字符串
-		query := ""
-		for k, v := range params {
-			if query != "" {
-				query += "&"
-			}
-			query += fmt.Sprintf("%s=%s", k, v)
+	// 构建查询字符串或表单
+	values := url.Values{}
+	for k, v := range params {
+		values.Set(k, v)
+	}
+	data := values.Encode()
+
+	idempotent := !strings.HasSuffix(apiURL, ".Create")
+	_, body, err := c.HTTP.Do(ctx, idempotent, func() (*http.Request, error) {
+		if method == "GET" {
+			return http.NewRequestWithContext(ctx, method, apiURL+"?"+data, nil)
 		}
-		url = fmt.Sprintf("%s?%s", url, query)
-		req, err = http.NewRequest(method, url, nil)
-	} else {
+
 		// POST请求
-		// 将参数转换为表单格式
-		formData := ""
-		for k, v := range params {
-			if formData != "" {
-				formData += "&"
-			}
-			formData += fmt.Sprintf("%s=%s", k, v)
+		req, err := http.NewRequestWithContext(ctx, method, apiURL, strings.NewReader(data))
+		if err != nil {
+			return nil, err
 		}
-		req, err = http.NewRequest(method, url, strings.NewReader(formData))
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
+		return req, nil
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -150,12 +172,55 @@ func (c *DnsPodClient) makeRequest(method, url string, params map[string]string)
 	return body, nil
 }
 
+// dnsPodThrottled DNSPod超出API调用频率限制时返回状态码-2
+func dnsPodThrottled(statusCode int, body []byte) bool {
+	var result struct {
+		Status DnsStatus `json:"status"`
+	}
+	if json.Unmarshal(body, &result) != nil {
+		return false
+	}
+	return dnsPodErrorKind(result.Status.Code) == ErrThrottled
+}
+
+// dnsPodError 将DNSPod接口返回的失败状态转换为APIError
+func dnsPodError(status DnsStatus) error {
+	return &APIError{
+		Kind:    dnsPodErrorKind(status.Code),
+		Code:    status.Code,
+		Message: status.Message,
+	}
+}
+
+// dnsPodErrorKind DNSPod状态码对应的错误类别，参见各接口文档的返回码说明
+func dnsPodErrorKind(code string) error {
+	switch code {
+	case "-1", "-3", "-4", "-7", "-8", "83", "85":
+		// 登录失败、无权使用接口等；83为帐户被锁定，85为帐户异地登录被拒绝，
+		// 都需要在控制台处理，重试不会成功，因此不作为限流
+		return ErrAuthFailed
+	case "-2":
+		return ErrThrottled // API使用超出限制
+	case "8", "9":
+		return ErrNotFound // 记录ID错误、不是域名所有者
+	case "11", "12", "104":
+		return ErrAlreadyExists // 域名已经存在(添加域名时)、记录已经存在
+	case "21", "31":
+		return ErrConflict // 域名被锁定、存在冲突的记录(A、CNAME、URL不能共存)
+	case "25", "33":
+		return ErrQuotaExceeded // 轮循记录数量超出限制
+	case "6", "22", "23", "24", "26", "27", "30", "32", "34", "35", "36", "82":
+		return ErrInvalidParam // 参数错误、子域名、线路、类型、MX、TTL、记录值不合法等
+	}
+	return nil
+}
+
 // GetDomainList 获取域名列表
-func (c *DnsPodClient) GetDomainList() ([]DnsDomain, error) {
+func (c *DnsPodClient) GetDomainList(ctx context.Context) ([]DnsDomain, error) {
 	url := "https://dnsapi.cn/Domain.List"
 	params := map[string]string{}
 
-	resp, err := c.makeRequest("POST", url, params)
+	resp, err := c.makeRequest(ctx, "POST", url, params)
 	if err != nil {
 		return nil, err
 	}
@@ -171,52 +236,300 @@ func (c *DnsPodClient) GetDomainList() ([]DnsDomain, error) {
 	}
 
 	if result.Status.Code != "1" {
-		return nil, fmt.Errorf("API Error: %s", result.Status.Message)
+		return nil, dnsPodError(result.Status)
 	}
 
 	return result.Domains, nil
 }
 
-// GetRecordList 获取DNS记录列表
-func (c *DnsPodClient) GetRecordList(domain string, subDomain string) ([]DnsRecord, error) {
-	url := "https://dnsapi.cn/Record.List"
+// CreateDomain 添加域名，返回域名ID、名称和punycode
+func (c *DnsPodClient) CreateDomain(ctx context.Context, domain string) (*DnsDomain, error) {
+	url := "https://dnsapi.cn/Domain.Create"
 	params := map[string]string{
 		"domain": domain,
 	}
 
+	resp, err := c.makeRequest(ctx, "POST", url, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Status DnsStatus `json:"status"`
+		Domain struct {
+			ID       DnsPodInt `json:"id"`
+			PunyCode string    `json:"punycode"`
+			Domain   string    `json:"domain"`
+		} `json:"domain"`
+	}
+
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(resp))
+	}
+
+	if result.Status.Code != "1" {
+		return nil, dnsPodError(result.Status)
+	}
+
+	return &DnsDomain{
+		ID:       int(result.Domain.ID),
+		Name:     result.Domain.Domain,
+		PunyCode: result.Domain.PunyCode,
+		Status:   "enable",
+	}, nil
+}
+
+// DeleteDomain 删除域名
+func (c *DnsPodClient) DeleteDomain(ctx context.Context, domain string) error {
+	params := map[string]string{}
+	setDomainParam(params, domain)
+	return c.domainRequest(ctx, "https://dnsapi.cn/Domain.Remove", params, nil)
+}
+
+// SetDomainStatus 设置域名状态，status为enable或disable
+func (c *DnsPodClient) SetDomainStatus(ctx context.Context, domain, status string) error {
+	params := map[string]string{
+		"status": status,
+	}
+	setDomainParam(params, domain)
+	return c.domainRequest(ctx, "https://dnsapi.cn/Domain.Status", params, nil)
+}
+
+// SetDomainRemark 设置域名备注，remark为空时清除备注
+func (c *DnsPodClient) SetDomainRemark(ctx context.Context, domain, remark string) error {
+	params := map[string]string{
+		"remark": remark,
+	}
+	setDomainParam(params, domain)
+	return c.domainRequest(ctx, "https://dnsapi.cn/Domain.Remark", params, nil)
+}
+
+// LockDomain 锁定域名days天，返回解锁码
+func (c *DnsPodClient) LockDomain(ctx context.Context, domain string, days int) (string, error) {
+	params := map[string]string{
+		"days": strconv.Itoa(days),
+	}
+	setDomainParam(params, domain)
+
+	var result struct {
+		Lock struct {
+			LockCode string `json:"lock_code"`
+			LockEnd  string `json:"lock_end"`
+		} `json:"lock"`
+	}
+	if err := c.domainRequest(ctx, "https://dnsapi.cn/Domain.Lock", params, &result); err != nil {
+		return "", err
+	}
+	return result.Lock.LockCode, nil
+}
+
+// UnlockDomain 使用锁定时返回的解锁码解锁域名
+func (c *DnsPodClient) UnlockDomain(ctx context.Context, domain, lockCode string) error {
+	params := map[string]string{
+		"lock_code": lockCode,
+	}
+	setDomainParam(params, domain)
+	return c.domainRequest(ctx, "https://dnsapi.cn/Domain.Unlock", params, nil)
+}
+
+// domainRequest 发起域名管理请求并检查状态码，result不为nil时解析响应
+func (c *DnsPodClient) domainRequest(ctx context.Context, url string, params map[string]string, result interface{}) error {
+	resp, err := c.makeRequest(ctx, "POST", url, params)
+	if err != nil {
+		return err
+	}
+
+	var status struct {
+		Status DnsStatus `json:"status"`
+	}
+	if err := json.Unmarshal(resp, &status); err != nil {
+		return fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(resp))
+	}
+	if status.Status.Code != "1" {
+		return dnsPodError(status.Status)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(resp, result); err != nil {
+			return fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(resp))
+		}
+	}
+	return nil
+}
+
+// GetDomainGrade 获取域名等级，线路列表与域名等级有关
+func (c *DnsPodClient) GetDomainGrade(ctx context.Context, domain string) (string, error) {
+	params := map[string]string{}
+	setDomainParam(params, domain)
+
+	var result struct {
+		Domain struct {
+			Grade string `json:"grade"`
+		} `json:"domain"`
+	}
+	if err := c.domainRequest(ctx, "https://dnsapi.cn/Domain.Info", params, &result); err != nil {
+		return "", err
+	}
+	return result.Domain.Grade, nil
+}
+
+// GetLineList 获取域名等级对应的线路名称及线路ID
+func (c *DnsPodClient) GetLineList(ctx context.Context, domain, grade string) ([]string, map[string]string, error) {
+	params := map[string]string{
+		"domain_grade": grade,
+	}
+	setDomainParam(params, domain)
+
+	var result struct {
+		Lines   []string          `json:"lines"`
+		LineIds map[string]string `json:"line_ids"`
+	}
+	if err := c.domainRequest(ctx, "https://dnsapi.cn/Record.Line", params, &result); err != nil {
+		return nil, nil, err
+	}
+	return result.Lines, result.LineIds, nil
+}
+
+// setDomainParam DNSPod接口支持domain_id或domain二选一，纯数字按域名ID处理
+func setDomainParam(params map[string]string, domain string) {
+	if _, err := strconv.Atoi(domain); err == nil {
+		params["domain_id"] = domain
+	} else {
+		params["domain"] = domain
+	}
+}
+
+// GetRecordList 获取DNS记录列表，逐页获取直到取完
+func (c *DnsPodClient) GetRecordList(ctx context.Context, domain string, subDomain string) ([]DnsRecord, error) {
+	var records []DnsRecord
+	pager := c.NewRecordPager(domain, subDomain, dnsPodMaxPageSize)
+	for pager.Next(ctx) {
+		records = append(records, pager.Records()...)
+	}
+	if err := pager.Err(); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// GetRecordPage 获取一页DNS记录，offset从0开始，返回的DnsInfo.RecordTotal为记录总数
+func (c *DnsPodClient) GetRecordPage(ctx context.Context, domain, subDomain string, offset, length int) ([]DnsRecord, DnsInfo, error) {
+	url := "https://dnsapi.cn/Record.List"
+	params := map[string]string{
+		"offset": strconv.Itoa(offset),
+		"length": strconv.Itoa(length),
+	}
+	setDomainParam(params, domain)
 	if subDomain != "" {
 		params["sub_domain"] = subDomain
 	}
 
-	resp, err := c.makeRequest("POST", url, params)
+	resp, err := c.makeRequest(ctx, "POST", url, params)
 	if err != nil {
-		return nil, err
+		return nil, DnsInfo{}, err
 	}
 
 	var result DnsRecordListResponse
 	if err := json.Unmarshal(resp, &result); err != nil {
-		return nil, fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(resp))
+		return nil, DnsInfo{}, fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(resp))
 	}
 
+	// 状态码10表示记录列表为空，offset超出记录总数时也会返回
+	if result.Status.Code == "10" {
+		return nil, result.Info, nil
+	}
 	if result.Status.Code != "1" {
-		return nil, fmt.Errorf("API Error: %v", result.Status.Message)
+		return nil, DnsInfo{}, dnsPodError(result.Status)
 	}
 
-	return result.Records, nil
+	return result.Records, result.Info, nil
+}
+
+// DnsPodRecordPager 按offset/length逐页获取DNS记录
+//
+//	pager := client.NewRecordPager(domain, "", 500)
+//	for pager.Next(ctx) {
+//		records := pager.Records()
+//	}
+//	err := pager.Err()
+type DnsPodRecordPager struct {
+	client    *DnsPodClient
+	domain    string
+	subDomain string
+	length    int
+	offset    int
+	total     int
+	records   []DnsRecord
+	done      bool
+	err       error
+}
+
+// NewRecordPager 创建记录分页器，length为每页记录数
+func (c *DnsPodClient) NewRecordPager(domain, subDomain string, length int) *DnsPodRecordPager {
+	if length <= 0 || length > dnsPodMaxPageSize {
+		length = dnsPodMaxPageSize
+	}
+	return &DnsPodRecordPager{
+		client:    c,
+		domain:    domain,
+		subDomain: subDomain,
+		length:    length,
+	}
+}
+
+// Next 获取下一页，没有更多记录或出错时返回false
+func (p *DnsPodRecordPager) Next(ctx context.Context) bool {
+	if p.done {
+		return false
+	}
+
+	records, info, err := p.client.GetRecordPage(ctx, p.domain, p.subDomain, p.offset, p.length)
+	if err != nil {
+		p.err = err
+		p.done = true
+		return false
+	}
+
+	p.total = int(info.RecordTotal)
+	p.offset += len(records)
+	p.records = records
+	if len(records) < p.length || (p.total > 0 && p.offset >= p.total) {
+		p.done = true
+	}
+	return len(records) > 0
+}
+
+// Records 当前页的记录
+func (p *DnsPodRecordPager) Records() []DnsRecord {
+	return p.records
+}
+
+// Total 服务商返回的记录总数
+func (p *DnsPodRecordPager) Total() int {
+	return p.total
+}
+
+// Err 分页过程中的错误
+func (p *DnsPodRecordPager) Err() error {
+	return p.err
 }
 
 // CreateRecord 创建DNS记录
-func (c *DnsPodClient) CreateRecord(domainID, subDomain, recordType, value, recordLine string) (*DnsRecord, error) {
+//
+// ttl、mx和weight为0时不传，由DNSPod使用默认值；mx仅对MX记录有效，weight为0-100的权重
+func (c *DnsPodClient) CreateRecord(ctx context.Context, domain, subDomain, recordType, value, recordLine string, ttl, mx, weight int64) (*DnsRecord, error) {
 	url := "https://dnsapi.cn/Record.Create"
 	params := map[string]string{
-		"domain_id":   domainID,
 		"sub_domain":  subDomain,
 		"record_type": recordType,
 		"value":       value,
 		"record_line": recordLine,
 	}
 
-	resp, err := c.makeRequest("POST", url, params)
+	setDomainParam(params, domain)
+	setRecordOptions(params, ttl, mx, weight)
+
+	resp, err := c.makeRequest(ctx, "POST", url, params)
 	if err != nil {
 		return nil, err
 	}
@@ -231,25 +544,27 @@ func (c *DnsPodClient) CreateRecord(domainID, subDomain, recordType, value, reco
 	}
 
 	if result.Status.Code != "1" {
-		return nil, fmt.Errorf("API Error: %s", result.Status.Message)
+		return nil, dnsPodError(result.Status)
 	}
 
 	return &result.Record, nil
 }
 
 // UpdateRecord 更新DNS记录
-func (c *DnsPodClient) UpdateRecord(recordID, domainID, subDomain, recordType, value, recordLine string) (*DnsRecord, error) {
+func (c *DnsPodClient) UpdateRecord(ctx context.Context, recordID, domain, subDomain, recordType, value, recordLine string, ttl, mx, weight int64) (*DnsRecord, error) {
 	url := "https://dnsapi.cn/Record.Modify"
 	params := map[string]string{
 		"record_id":   recordID,
-		"domain_id":   domainID,
 		"sub_domain":  subDomain,
 		"record_type": recordType,
 		"value":       value,
 		"record_line": recordLine,
 	}
 
-	resp, err := c.makeRequest("POST", url, params)
+	setDomainParam(params, domain)
+	setRecordOptions(params, ttl, mx, weight)
+
+	resp, err := c.makeRequest(ctx, "POST", url, params)
 	if err != nil {
 		return nil, err
 	}
@@ -264,21 +579,61 @@ func (c *DnsPodClient) UpdateRecord(recordID, domainID, subDomain, recordType, v
 	}
 
 	if result.Status.Code != "1" {
-		return nil, fmt.Errorf("API Error: %s", result.Status.Message)
+		return nil, dnsPodError(result.Status)
+	}
+
+	return &result.Record, nil
+}
+
+// GetRecord 获取单条记录的详情
+func (c *DnsPodClient) GetRecord(ctx context.Context, recordID, domain string) (*DnsRecordInfo, error) {
+	params := map[string]string{
+		"record_id": recordID,
 	}
+	setDomainParam(params, domain)
 
+	var result struct {
+		Record DnsRecordInfo `json:"record"`
+	}
+	if err := c.domainRequest(ctx, "https://dnsapi.cn/Record.Info", params, &result); err != nil {
+		return nil, err
+	}
 	return &result.Record, nil
 }
 
+// setRecordOptions 设置记录的TTL、MX优先级和权重，为0时不传
+func setRecordOptions(params map[string]string, ttl, mx, weight int64) {
+	if ttl > 0 {
+		params["ttl"] = strconv.FormatInt(ttl, 10)
+	}
+	if mx > 0 {
+		params["mx"] = strconv.FormatInt(mx, 10)
+	}
+	if weight > 0 {
+		params["weight"] = strconv.FormatInt(weight, 10)
+	}
+}
+
+// SetRecordRemark 设置记录备注，remark为空时清除备注
+func (c *DnsPodClient) SetRecordRemark(ctx context.Context, recordID, domain, remark string) error {
+	params := map[string]string{
+		"record_id": recordID,
+		"remark":    remark,
+	}
+	setDomainParam(params, domain)
+	return c.domainRequest(ctx, "https://dnsapi.cn/Record.Remark", params, nil)
+}
+
 // DeleteRecord 删除DNS记录
-func (c *DnsPodClient) DeleteRecord(recordID, domainID string) error {
+func (c *DnsPodClient) DeleteRecord(ctx context.Context, recordID, domain string) error {
 	url := "https://dnsapi.cn/Record.Remove"
 	params := map[string]string{
 		"record_id": recordID,
-		"domain_id": domainID,
 	}
 
-	resp, err := c.makeRequest("POST", url, params)
+	setDomainParam(params, domain)
+
+	resp, err := c.makeRequest(ctx, "POST", url, params)
 	if err != nil {
 		return err
 	}
@@ -292,22 +647,23 @@ func (c *DnsPodClient) DeleteRecord(recordID, domainID string) error {
 	}
 
 	if result.Status.Code != "1" {
-		return fmt.Errorf("API Error: %s", result.Status.Message)
+		return dnsPodError(result.Status)
 	}
 
 	return nil
 }
 
 // SetRecordStatus 设置记录状态
-func (c *DnsPodClient) SetRecordStatus(recordID, domainID, status string) error {
+func (c *DnsPodClient) SetRecordStatus(ctx context.Context, recordID, domain, status string) error {
 	url := "https://dnsapi.cn/Record.Status"
 	params := map[string]string{
 		"record_id": recordID,
-		"domain_id": domainID,
 		"status":    status,
 	}
 
-	resp, err := c.makeRequest("POST", url, params)
+	setDomainParam(params, domain)
+
+	resp, err := c.makeRequest(ctx, "POST", url, params)
 	if err != nil {
 		return err
 	}
@@ -321,7 +677,7 @@ func (c *DnsPodClient) SetRecordStatus(recordID, domainID, status string) error
 	}
 
 	if result.Status.Code != "1" {
-		return fmt.Errorf("API Error: %s", result.Status.Message)
+		return dnsPodError(result.Status)
 	}
 
 	return nil
@@ -0,0 +1,147 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestDnsPodErrorKind(t *testing.T) {
+	tests := []struct {
+		code      string
+		kind      error
+		throttled bool
+	}{
+		{"-2", ErrThrottled, true},
+		{"-1", ErrAuthFailed, false},
+		{"83", ErrAuthFailed, false}, // 帐户被锁定，重试不会成功
+		{"85", ErrAuthFailed, false}, // 帐户异地登录被拒绝
+		{"8", ErrNotFound, false},
+		{"104", ErrAlreadyExists, false},
+		{"31", ErrConflict, false},
+		{"25", ErrQuotaExceeded, false},
+		{"22", ErrInvalidParam, false},
+		{"1000", nil, false},
+	}
+	for _, tt := range tests {
+		if kind := dnsPodErrorKind(tt.code); kind != tt.kind {
+			t.Errorf("dnsPodErrorKind(%s) = %v, 期望 %v", tt.code, kind, tt.kind)
+		}
+		body := []byte(`{"status":{"code":"` + tt.code + `","message":"test"}}`)
+		if throttled := dnsPodThrottled(200, body); throttled != tt.throttled {
+			t.Errorf("状态码%s是否限流 = %v, 期望 %v", tt.code, throttled, tt.throttled)
+		}
+	}
+}
+
+func TestAliyunThrottled(t *testing.T) {
+	tests := []struct {
+		body      string
+		throttled bool
+	}{
+		{`{"Code":"Throttling.User","Message":"Request was denied due to user flow control."}`, true},
+		{`{"Code":"Throttling","Message":"Request was denied due to flow control."}`, true},
+		{`{"Code":"DomainRecordDuplicate","Message":"The DNS record already exists."}`, false},
+		{`<html></html>`, false},
+	}
+	for _, tt := range tests {
+		if throttled := aliyunThrottled(400, []byte(tt.body)); throttled != tt.throttled {
+			t.Errorf("%s 是否限流 = %v, 期望 %v", tt.body, throttled, tt.throttled)
+		}
+	}
+}
+
+// newTestDnsPodPaging 启动按offset/length分页的假Record.List接口，record_total以字符串返回
+func newTestDnsPodPaging(t *testing.T, total int) (*DnsPodClient, *[]string) {
+	t.Helper()
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Record.List" || r.FormValue("login_token") != "test-token" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": map[string]string{"code": "-1", "message": "登录失败"}})
+			return
+		}
+		offset, _ := strconv.Atoi(r.FormValue("offset"))
+		length, _ := strconv.Atoi(r.FormValue("length"))
+		requests = append(requests, fmt.Sprintf("%d/%d", offset, length))
+		if offset >= total {
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": map[string]string{"code": "10", "message": "记录列表为空"}})
+			return
+		}
+		records := []DnsRecord{}
+		for i := offset; i < total && i < offset+length; i++ {
+			records = append(records, DnsRecord{ID: strconv.Itoa(i + 1), Name: fmt.Sprintf("host%d", i), Type: "A", Value: "192.0.2.1", TTL: "600", Line: "默认"})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":  map[string]string{"code": "1"},
+			"info":    map[string]string{"record_total": strconv.Itoa(total), "records_num": strconv.Itoa(len(records))},
+			"records": records,
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	client := NewDnsPodClient("test-token")
+	client.HTTP = newRedirectedHTTPClient(nil, srv)
+	return client, &requests
+}
+
+func TestDnsPodRecordPager(t *testing.T) {
+	tests := []struct {
+		total, length int
+		requests      []string
+	}{
+		{5, 2, []string{"0/2", "2/2", "4/2"}},
+		{4, 2, []string{"0/2", "2/2"}}, // 已取满record_total，不再请求
+		{0, 2, []string{"0/2"}},        // 状态码10，记录列表为空
+	}
+	for _, tt := range tests {
+		client, requests := newTestDnsPodPaging(t, tt.total)
+		pager := client.NewRecordPager("example.com", "", tt.length)
+		var ids []string
+		for pager.Next(context.Background()) {
+			for _, r := range pager.Records() {
+				ids = append(ids, r.ID)
+			}
+		}
+		if err := pager.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != tt.total || pager.Total() != tt.total {
+			t.Errorf("共%d条记录时获取到 %v, total = %d", tt.total, ids, pager.Total())
+		}
+		if fmt.Sprint(*requests) != fmt.Sprint(tt.requests) {
+			t.Errorf("共%d条记录时的请求 = %v, 期望 %v", tt.total, *requests, tt.requests)
+		}
+	}
+}
+
+func TestDnsPodProviderGetRecordPage(t *testing.T) {
+	client, requests := newTestDnsPodPaging(t, 5)
+	p := NewDnsPodProvider(client)
+	ctx := context.Background()
+
+	records, total, err := p.GetRecordPage(ctx, "example.com", "", 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 5 || len(records) != 1 || records[0].Name != "host4" || records[0].TTL != 600 {
+		t.Errorf("第3页 = %+v, total = %d", records, total)
+	}
+	if (*requests)[0] != "4/2" {
+		t.Errorf("第3页的请求 = %v, 期望offset为4", *requests)
+	}
+
+	// 超出最后一页时返回空列表而不是错误
+	records, _, err = p.GetRecordPage(ctx, "example.com", "", 4, 2)
+	if err != nil || len(records) != 0 {
+		t.Errorf("超出最后一页返回 %+v, %v", records, err)
+	}
+
+	all, err := p.GetRecordList(ctx, "example.com", "")
+	if err != nil || len(all) != 5 {
+		t.Errorf("全部记录 = %d条, %v", len(all), err)
+	}
+}
@@ -0,0 +1,247 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+func init() {
+	Register(ProviderDnsPod, func(cfg Config) (Provider, error) {
+		token := cfg.Get("TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("DNSPod Token未配置")
+		}
+		client := NewDnsPodClient(token)
+		client.HTTP.Configure(cfg)
+		return NewDnsPodProvider(client), nil
+	})
+}
+
+// DnsPodProvider 将DnsPodClient适配为Provider
+type DnsPodProvider struct {
+	client *DnsPodClient
+}
+
+// NewDnsPodProvider 创建DNSPod服务商
+func NewDnsPodProvider(client *DnsPodClient) *DnsPodProvider {
+	return &DnsPodProvider{client: client}
+}
+
+// GetDomainList 获取域名列表
+func (p *DnsPodProvider) GetDomainList(ctx context.Context) ([]Domain, error) {
+	domains, err := p.client.GetDomainList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Domain, 0, len(domains))
+	for _, d := range domains {
+		count, _ := strconv.Atoi(d.Records)
+		result = append(result, Domain{
+			ID:          strconv.Itoa(d.ID),
+			Name:        d.Name,
+			PunyCode:    d.PunyCode,
+			Status:      d.Status,
+			Grade:       d.Grade,
+			RecordCount: count,
+			Remark:      d.Remark,
+			NameServers: d.GradeNs,
+		})
+	}
+	return result, nil
+}
+
+// GetRecordList 获取记录列表
+func (p *DnsPodProvider) GetRecordList(ctx context.Context, domain, subDomain string) ([]Record, error) {
+	records, err := p.client.GetRecordList(ctx, domain, subDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Record, 0, len(records))
+	for _, r := range records {
+		result = append(result, fromDnsPodRecord(domain, r))
+	}
+	return result, nil
+}
+
+// GetRecordPage 按页获取记录列表
+func (p *DnsPodProvider) GetRecordPage(ctx context.Context, domain, subDomain string, page, pageSize int) ([]Record, int, error) {
+	records, info, err := p.client.GetRecordPage(ctx, domain, subDomain, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]Record, 0, len(records))
+	for _, r := range records {
+		result = append(result, fromDnsPodRecord(domain, r))
+	}
+	return result, int(info.RecordTotal), nil
+}
+
+// CreateRecord 创建记录，备注通过Record.Remark单独设置
+func (p *DnsPodProvider) CreateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	created, err := p.client.CreateRecord(ctx, domain, record.Name, record.Type, record.Value, record.Line,
+		record.TTL, record.mxPriority(), record.Weight)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record.Create只返回ID、名称和状态
+	record.ID = created.ID
+	record.Domain = domain
+	record.Status = dnsPodStatus(created.Status)
+
+	if record.Remark != "" {
+		if err := p.client.SetRecordRemark(ctx, record.ID, domain, record.Remark); err != nil {
+			return &record, fmt.Errorf("记录已创建，设置备注失败: %w", err)
+		}
+	}
+	return &record, nil
+}
+
+// UpdateRecord 更新记录，remark不为空或指定了RemarkSet时同时修改备注
+func (p *DnsPodProvider) UpdateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	updated, err := p.client.UpdateRecord(ctx, record.ID, domain, record.Name, record.Type, record.Value, record.Line,
+		record.TTL, record.mxPriority(), record.Weight)
+	if err != nil {
+		return nil, err
+	}
+
+	record.Domain = domain
+	record.Status = dnsPodStatus(updated.Status)
+
+	if record.Remark != "" || record.RemarkSet {
+		if err := p.client.SetRecordRemark(ctx, record.ID, domain, record.Remark); err != nil {
+			return &record, fmt.Errorf("记录已修改，设置备注失败: %w", err)
+		}
+	}
+	return &record, nil
+}
+
+// GetRecord 获取单条记录
+func (p *DnsPodProvider) GetRecord(ctx context.Context, domain, recordID string) (*Record, error) {
+	info, err := p.client.GetRecord(ctx, recordID, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	status := RecordStatusEnable
+	if info.Enabled == "0" {
+		status = RecordStatusDisable
+	}
+	return &Record{
+		ID:       info.ID,
+		Domain:   domain,
+		Name:     info.SubDomain,
+		Type:     info.RecordType,
+		Value:    info.Value,
+		TTL:      int64(info.TTL),
+		Priority: int64(info.MX),
+		Weight:   int64(info.Weight),
+		Line:     info.RecordLine,
+		Status:   status,
+		Remark:   info.Remark,
+	}, nil
+}
+
+// DeleteRecord 删除记录
+func (p *DnsPodProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	return p.client.DeleteRecord(ctx, recordID, domain)
+}
+
+// SetRecordStatus 设置记录状态
+func (p *DnsPodProvider) SetRecordStatus(ctx context.Context, domain, recordID, status string) error {
+	return p.client.SetRecordStatus(ctx, recordID, domain, status)
+}
+
+// CreateDomain 添加域名
+func (p *DnsPodProvider) CreateDomain(ctx context.Context, name string) (*Domain, error) {
+	created, err := p.client.CreateDomain(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Domain{
+		ID:       strconv.Itoa(created.ID),
+		Name:     created.Name,
+		PunyCode: created.PunyCode,
+		Status:   created.Status,
+	}, nil
+}
+
+// DeleteDomain 删除域名
+func (p *DnsPodProvider) DeleteDomain(ctx context.Context, domain string) error {
+	return p.client.DeleteDomain(ctx, domain)
+}
+
+// SetDomainStatus 设置域名状态
+func (p *DnsPodProvider) SetDomainStatus(ctx context.Context, domain, status string) error {
+	return p.client.SetDomainStatus(ctx, domain, status)
+}
+
+// SetDomainRemark 设置域名备注
+func (p *DnsPodProvider) SetDomainRemark(ctx context.Context, domain, remark string) error {
+	return p.client.SetDomainRemark(ctx, domain, remark)
+}
+
+// LockDomain 锁定域名
+func (p *DnsPodProvider) LockDomain(ctx context.Context, domain string, days int) (string, error) {
+	return p.client.LockDomain(ctx, domain, days)
+}
+
+// UnlockDomain 解锁域名
+func (p *DnsPodProvider) UnlockDomain(ctx context.Context, domain, lockCode string) error {
+	return p.client.UnlockDomain(ctx, domain, lockCode)
+}
+
+// GetLineList 获取域名可用的线路，DNSPod接口中使用线路名称
+func (p *DnsPodProvider) GetLineList(ctx context.Context, domain string) ([]Line, error) {
+	grade, err := p.client.GetDomainGrade(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	names, ids, err := p.client.GetLineList(ctx, domain, grade)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]Line, 0, len(names))
+	for _, name := range names {
+		lines = append(lines, Line{
+			ID:   ids[name],
+			Code: name,
+			Name: name,
+		})
+	}
+	return lines, nil
+}
+
+// fromDnsPodRecord 转换为统一记录结构
+func fromDnsPodRecord(domain string, r DnsRecord) Record {
+	ttl, _ := strconv.ParseInt(r.TTL, 10, 64)
+	mx, _ := strconv.ParseInt(r.MX, 10, 64)
+	weight, _ := strconv.ParseInt(r.Weight, 10, 64)
+	return Record{
+		ID:       r.ID,
+		Domain:   domain,
+		Name:     r.Name,
+		Type:     r.Type,
+		Value:    r.Value,
+		TTL:      ttl,
+		Priority: mx,
+		Weight:   weight,
+		Line:     r.Line,
+		Status:   dnsPodStatus(r.Status),
+		Remark:   r.Remark,
+	}
+}
+
+// dnsPodStatus DNSPod状态本身即为enable/disable，为空时视为启用
+func dnsPodStatus(status string) string {
+	if status == "" {
+		return RecordStatusEnable
+	}
+	return status
+}
@@ -0,0 +1,104 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrTimeout 服务商接口在超时时间内没有响应
+	ErrTimeout = errors.New("DNS服务商请求超时")
+	// ErrCanceled 调用方已取消请求，通常是客户端断开了连接
+	ErrCanceled = errors.New("请求已取消")
+)
+
+// ContextError 根据ctx的状态包装服务商调用返回的错误，
+// 超时包装为ErrTimeout，取消包装为ErrCanceled，可以用errors.Is判断
+func ContextError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	case context.Canceled:
+		return fmt.Errorf("%w: %v", ErrCanceled, err)
+	}
+	return err
+}
+
+// 服务商错误的类别，各服务商的错误码会归到这些类别中，可以用errors.Is判断
+var (
+	ErrNotFound      = errors.New("域名或记录不存在")
+	ErrAlreadyExists = errors.New("记录已经存在")
+	ErrConflict      = errors.New("与现有记录冲突")
+	ErrAuthFailed    = errors.New("服务商认证失败")
+	ErrThrottled     = errors.New("服务商请求过于频繁")
+	ErrInvalidParam  = errors.New("参数错误")
+	ErrQuotaExceeded = errors.New("超出服务商配额")
+	ErrUnsupported   = errors.New("服务商不支持该操作")
+)
+
+// APIError 服务商接口返回的错误，保留服务商的错误码和请求ID
+type APIError struct {
+	Kind       error  // 错误类别，如ErrNotFound，无法归类时为nil
+	Code       string // 服务商错误码
+	Message    string
+	RequestID  string
+	StatusCode int // HTTP状态码，非HTTP接口为0
+}
+
+func (e *APIError) Error() string {
+	msg := "API Error: "
+	if e.Code != "" {
+		msg += e.Code + " "
+	}
+	msg += e.Message
+	if e.RequestID != "" {
+		msg += " (RequestId: " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// Unwrap 返回错误类别
+func (e *APIError) Unwrap() error {
+	return e.Kind
+}
+
+// kindFromStatus 服务商错误码无法归类时，按HTTP状态码归类
+func kindFromStatus(statusCode int) error {
+	switch statusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrInvalidParam
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return ErrAuthFailed
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusTooManyRequests:
+		return ErrThrottled
+	}
+	return nil
+}
+
+// kindError 本地判断出的错误，保留原有的错误信息
+type kindError struct {
+	kind error
+	msg  string
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Unwrap() error {
+	return e.kind
+}
+
+// errorf 创建属于kind类别的错误，错误信息与fmt.Errorf相同
+func errorf(kind error, format string, a ...interface{}) error {
+	return &kindError{kind: kind, msg: fmt.Sprintf(format, a...)}
+}
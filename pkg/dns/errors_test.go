@@ -0,0 +1,87 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAliyunAPIError(t *testing.T) {
+	tests := []struct {
+		status int
+		code   string
+		kind   error
+	}{
+		{http.StatusBadRequest, "DomainRecordDuplicate", ErrAlreadyExists},
+		{http.StatusBadRequest, "InvalidDomainName.NoExist", ErrNotFound},
+		{http.StatusBadRequest, "DomainRecordNotBelongToUser", ErrNotFound},
+		{http.StatusBadRequest, "DomainRecordConflict", ErrConflict},
+		{http.StatusForbidden, "InvalidAccessKeyId.NotFound", ErrAuthFailed},
+		{http.StatusBadRequest, "SignatureDoesNotMatch", ErrAuthFailed},
+		{http.StatusServiceUnavailable, "Throttling.User", ErrThrottled},
+		{http.StatusBadRequest, "QuotaExceeded.Record", ErrQuotaExceeded},
+		{http.StatusBadRequest, "InvalidRR.Format", ErrInvalidParam},
+		{http.StatusNotFound, "SomethingElse", ErrNotFound}, // 未知错误码按HTTP状态码归类
+		{http.StatusInternalServerError, "InternalError", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprintf(w, `{"Code":%q,"Message":"test message","RequestId":"test-request"}`, tt.code)
+			}))
+			defer srv.Close()
+			client := NewAliyunDnsClient("test-id", "test-secret", "")
+			client.HTTP = newRedirectedHTTPClient(aliyunThrottled, srv)
+			client.HTTP.MaxRetries = 0
+
+			err := NewAliyunProvider(client).DeleteRecord(context.Background(), "example.com", "1")
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("返回 %v, 期望APIError", err)
+			}
+			if apiErr.Kind != tt.kind || apiErr.Code != tt.code || apiErr.RequestID != "test-request" || apiErr.StatusCode != tt.status {
+				t.Errorf("APIError = %+v, 期望类别 %v", apiErr, tt.kind)
+			}
+			if tt.kind != nil && !errors.Is(fmt.Errorf("删除记录失败: %w", err), tt.kind) {
+				t.Errorf("包装后 errors.Is(%v) = false", tt.kind)
+			}
+		})
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	err := &APIError{Kind: ErrNotFound, Code: "8", Message: "记录不存在", RequestID: "abc"}
+	if got, want := err.Error(), "API Error: 8 记录不存在 (RequestId: abc)"; got != want {
+		t.Errorf("Error() = %q, 期望 %q", got, want)
+	}
+	if got, want := (&APIError{Message: "失败"}).Error(), "API Error: 失败"; got != want {
+		t.Errorf("Error() = %q, 期望 %q", got, want)
+	}
+}
+
+func TestContextError(t *testing.T) {
+	err := errorf(ErrNotFound, "记录不存在")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if got := ContextError(ctx, err); got != err {
+		t.Errorf("未取消时返回 %v", got)
+	}
+	cancel()
+	if got := ContextError(ctx, err); !errors.Is(got, ErrCanceled) {
+		t.Errorf("取消后返回 %v", got)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 0)
+	defer cancel()
+	<-ctx.Done()
+	if got := ContextError(ctx, err); !errors.Is(got, ErrTimeout) {
+		t.Errorf("超时后返回 %v", got)
+	}
+	if ContextError(ctx, nil) != nil {
+		t.Error("err为nil时应返回nil")
+	}
+}
@@ -0,0 +1,121 @@
+package dns
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// NormalizeDomain 规范化域名或主机记录，返回ASCII(punycode)和Unicode两种形式
+//
+// 去掉结尾的点并转为小写，含非ASCII字符的标签按IDNA2008(UTS #46)转换为xn--形式，
+// xn--开头的标签转换回Unicode。ASCII标签只转小写，以便保留下划线(如 _dmarc)和通配符*，
+// 空字符串和@原样返回
+func NormalizeDomain(name string) (ascii, unicode string, err error) {
+	name = strings.TrimSuffix(ideographicDots.Replace(strings.TrimSpace(name)), ".")
+	if name == "" || name == "@" {
+		return name, name, nil
+	}
+
+	labels := strings.Split(name, ".")
+	asciiLabels := make([]string, len(labels))
+	unicodeLabels := make([]string, len(labels))
+	for i, label := range labels {
+		a, u, err := normalizeLabel(label)
+		if err != nil {
+			return "", "", errorf(ErrInvalidParam, "无效的域名 %s: %v", name, err)
+		}
+		asciiLabels[i] = a
+		unicodeLabels[i] = u
+	}
+	return strings.Join(asciiLabels, "."), strings.Join(unicodeLabels, "."), nil
+}
+
+// ideographicDots UTS #46中与点等价的全角和表意文字句点，如 中文。com
+var ideographicDots = strings.NewReplacer("\u3002", ".", "\uff0e", ".", "\uff61", ".")
+
+// ToASCII 将域名或主机记录转换为服务商接口使用的ASCII形式
+func ToASCII(name string) (string, error) {
+	ascii, _, err := NormalizeDomain(name)
+	return ascii, err
+}
+
+// ToUnicode 将域名转换为Unicode形式用于显示，无法转换时返回小写的原值
+func ToUnicode(name string) string {
+	_, unicode, err := NormalizeDomain(name)
+	if err != nil {
+		return strings.ToLower(strings.TrimSuffix(name, "."))
+	}
+	return unicode
+}
+
+// DomainParam 规范化接口中的域名参数，返回ASCII形式
+//
+// 不含点的ASCII值视为服务商的域名ID(如DNSPod的数字ID、Route 53的托管区域ID)，原样返回
+func DomainParam(domain string) (string, error) {
+	if !strings.Contains(domain, ".") && !hasNonASCII(domain) {
+		return domain, nil
+	}
+	return ToASCII(domain)
+}
+
+// NormalizeRecord 将记录的主机记录和域名类型的记录值转换为ASCII形式
+//
+// CNAME、NS、PTR、ALIAS、MX的记录值保留结尾的点，其他类型的记录值不修改
+func NormalizeRecord(record Record) (Record, error) {
+	name, err := ToASCII(record.Name)
+	if err != nil {
+		return record, err
+	}
+	record.Name = name
+
+	switch strings.ToUpper(record.Type) {
+	case "CNAME", "NS", "PTR", "ALIAS", "MX":
+		value := strings.TrimSpace(record.Value)
+		ascii, err := ToASCII(value)
+		if err != nil {
+			return record, err
+		}
+		if strings.HasSuffix(value, ".") && ascii != "" {
+			ascii += "."
+		}
+		record.Value = ascii
+	}
+	return record, nil
+}
+
+// normalizeLabel 规范化域名中的一个标签
+func normalizeLabel(label string) (string, string, error) {
+	if !hasNonASCII(label) {
+		label = strings.ToLower(label)
+		if !strings.HasPrefix(label, "xn--") {
+			return label, label, nil
+		}
+		unicode, err := idna.Lookup.ToUnicode(label)
+		if err != nil {
+			return "", "", err
+		}
+		return label, unicode, nil
+	}
+
+	ascii, err := idna.Lookup.ToASCII(label)
+	if err != nil {
+		return "", "", err
+	}
+	unicode, err := idna.Lookup.ToUnicode(ascii)
+	if err != nil {
+		return "", "", err
+	}
+	return ascii, unicode, nil
+}
+
+// hasNonASCII 是否包含非ASCII字符
+func hasNonASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package dns
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		in, ascii, unicode string
+	}{
+		{"中文.com", "xn--fiq228c.com", "中文.com"},
+		{"xn--fiq228c.com", "xn--fiq228c.com", "中文.com"},
+		{"XN--FIQ228C.COM", "xn--fiq228c.com", "中文.com"},
+		{"中文。com", "xn--fiq228c.com", "中文.com"}, // 表意文字句点
+		{"WWW.Example.COM.", "www.example.com", "www.example.com"},
+		{"bücher.de", "xn--bcher-kva.de", "bücher.de"},
+		{"*.中文", "*.xn--fiq228c", "*.中文"},
+		{"_dmarc", "_dmarc", "_dmarc"},
+		{"@", "@", "@"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		ascii, unicode, err := NormalizeDomain(tt.in)
+		if err != nil || ascii != tt.ascii || unicode != tt.unicode {
+			t.Errorf("NormalizeDomain(%q) = %q, %q, %v, 期望 %q, %q", tt.in, ascii, unicode, err, tt.ascii, tt.unicode)
+		}
+	}
+
+	if _, _, err := NormalizeDomain("xn--zz.com"); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("无效的punycode返回 %v, 期望ErrInvalidParam", err)
+	}
+	if got := ToUnicode("xn--zz.com."); got != "xn--zz.com" {
+		t.Errorf("ToUnicode无法转换时返回 %q", got)
+	}
+}
+
+func TestDomainParam(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"12345", "12345"},       // DNSPod域名ID
+		{"Z0123ABC", "Z0123ABC"}, // Route 53托管区域ID，不转小写
+		{"Example.COM.", "example.com"},
+		{"中文.com", "xn--fiq228c.com"},
+		{"中文", "xn--fiq228c"},
+	}
+	for _, tt := range tests {
+		if got, err := DomainParam(tt.in); err != nil || got != tt.want {
+			t.Errorf("DomainParam(%q) = %q, %v, 期望 %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeRecord(t *testing.T) {
+	tests := []struct {
+		in, want Record
+	}{
+		{Record{Name: "WWW", Type: "A", Value: "192.0.2.1"}, Record{Name: "www", Type: "A", Value: "192.0.2.1"}},
+		{Record{Name: "中文", Type: "CNAME", Value: "例子.com."}, Record{Name: "xn--fiq228c", Type: "CNAME", Value: "xn--fsqu00a.com."}},
+		{Record{Name: "@", Type: "mx", Value: "Mail.中文.com"}, Record{Name: "@", Type: "mx", Value: "mail.xn--fiq228c.com"}},
+		{Record{Name: "_dmarc", Type: "TXT", Value: "v=DMARC1; p=None"}, Record{Name: "_dmarc", Type: "TXT", Value: "v=DMARC1; p=None"}}, // TXT的值不修改
+	}
+	for _, tt := range tests {
+		got, err := NormalizeRecord(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("NormalizeRecord(%+v) = %+v, %v, 期望 %+v", tt.in, got, err, tt.want)
+		}
+	}
+
+	if _, err := NormalizeRecord(Record{Name: "xn--zz", Type: "A"}); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("无效的主机记录返回 %v", err)
+	}
+}
@@ -1,150 +1,217 @@
 package dns
 
-import "fmt"
-
-// DnsProvider DNS服务提供商接口
-type DnsProvider interface {
-	GetDomainList() ([]DnsDomain, error)
-	GetRecordList(domain string, subDomain string) ([]DnsRecord, error)
-	CreateRecord(domainID, subDomain, recordType, value, recordLine string) (*DnsRecord, error)
-	UpdateRecord(recordID, domainID, subDomain, recordType, value, recordLine string) (*DnsRecord, error)
-	DeleteRecord(recordID, domainID string) error
-	SetRecordStatus(recordID, domainID, status string) error
-}
-
-// AliyunDnsProvider 阿里云DNS服务提供商接口
-type AliyunDnsProvider interface {
-	GetAliyunDomainList(pageNumber, pageSize int) ([]AliyunDnsRecord, error)
-	GetAliyunRecordList(domainName string, rrKeyWord string) ([]AliyunDnsRecord, error)
-	CreateAliyunRecord(domainName, rr, recordType, value string, ttl int64) (*AliyunDnsRecord, error)
-	UpdateAliyunRecord(recordId, rr, recordType, value string, ttl int64) (*AliyunDnsRecord, error)
-	DeleteAliyunRecord(recordId string) error
-	SetAliyunRecordStatus(recordId, status string) error
-}
-
-// DnsManager 统一DNS管理器
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 内置的服务商类型
+const (
+	ProviderDnsPod       = "dns_pod"
+	ProviderAliyun       = "aliyun"
+	ProviderCloudflare   = "cloudflare"
+	ProviderRoute53      = "route53"
+	ProviderTencentCloud = "tencentcloud"
+	ProviderRFC2136      = "rfc2136"
+	ProviderPowerDNS     = "powerdns"
+	ProviderMemory       = "memory"
+)
+
+// 统一的记录状态，域名状态同样使用enable/disable
+const (
+	RecordStatusEnable  = "enable"
+	RecordStatusDisable = "disable"
+)
+
+// Record 与服务商无关的DNS记录结构
+type Record struct {
+	ID       string `json:"id"`               // 服务商的记录ID
+	Domain   string `json:"domain,omitempty"` // 所属域名
+	Name     string `json:"name"`             // 主机记录，如 www、@
+	Type     string `json:"type"`             // 记录类型，如 A、CNAME、MX
+	Value    string `json:"value"`            // 记录值
+	TTL      int64  `json:"ttl"`              // TTL，单位秒
+	Priority int64  `json:"priority,omitempty"`
+	Weight   int64  `json:"weight,omitempty"`
+	Line     string `json:"line,omitempty"` // 线路/视图
+	Status   string `json:"status"`         // enable, disable
+	Remark   string `json:"remark,omitempty"`
+	Proxied  bool   `json:"proxied,omitempty"` // Cloudflare代理(橙色云朵)
+
+	SetIdentifier string `json:"set_identifier,omitempty"` // Route 53加权/延迟路由的记录集标识
+
+	// RemarkSet 修改记录时请求中指定了备注，此时备注为空表示清除备注；否则空备注表示不修改
+	RemarkSet bool `json:"-"`
+}
+
+// mxPriority MX记录的优先级，其他类型的记录返回0
+func (r Record) mxPriority() int64 {
+	if strings.EqualFold(r.Type, "MX") {
+		return r.Priority
+	}
+	return 0
+}
+
+// Domain 与服务商无关的域名结构
+type Domain struct {
+	ID          string            `json:"id"` // 服务商的域名ID
+	Name        string            `json:"name"`
+	PunyCode    string            `json:"punycode,omitempty"`
+	Status      string            `json:"status,omitempty"`
+	Grade       string            `json:"grade,omitempty"`
+	RecordCount int               `json:"record_count"`
+	Remark      string            `json:"remark,omitempty"`
+	NameServers []string          `json:"name_servers,omitempty"` // 服务商分配的DNS服务器
+	Group       string            `json:"group,omitempty"`        // 域名分组
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// Provider DNS服务提供商接口
+//
+// domain 参数为域名或服务商的域名ID，由各实现自行识别；
+// ctx 取消或超时后，各实现应尽快返回
+type Provider interface {
+	GetDomainList(ctx context.Context) ([]Domain, error)
+	GetRecordList(ctx context.Context, domain, subDomain string) ([]Record, error)
+	CreateRecord(ctx context.Context, domain string, record Record) (*Record, error)
+	UpdateRecord(ctx context.Context, domain string, record Record) (*Record, error)
+	DeleteRecord(ctx context.Context, domain, recordID string) error
+	SetRecordStatus(ctx context.Context, domain, recordID, status string) error
+}
+
+// RecordGetter 可选接口，服务商支持按ID获取单条记录时实现
+//
+// 创建、修改记录和设置状态后用于获取服务商实际保存的记录
+type RecordGetter interface {
+	GetRecord(ctx context.Context, domain, recordID string) (*Record, error)
+}
+
+// RecordPager 可选接口，服务商支持按页获取记录时实现
+//
+// page从1开始，返回当前页的记录和服务商给出的记录总数
+type RecordPager interface {
+	GetRecordPage(ctx context.Context, domain, subDomain string, page, pageSize int) ([]Record, int, error)
+}
+
+// DomainPager 可选接口，服务商支持按页和关键字获取域名时实现
+//
+// keyword为空时不过滤，page从1开始，返回当前页的域名和符合条件的域名总数
+type DomainPager interface {
+	GetDomainPage(ctx context.Context, keyword string, page, pageSize int) ([]Domain, int, error)
+}
+
+// DomainManager 可选接口，服务商支持添加、删除域名及修改域名状态、锁定和备注时实现
+//
+// domain 参数为域名或服务商的域名ID；服务商没有对应操作时返回ErrUnsupported
+type DomainManager interface {
+	CreateDomain(ctx context.Context, name string) (*Domain, error)
+	DeleteDomain(ctx context.Context, domain string) error
+	SetDomainStatus(ctx context.Context, domain, status string) error
+	SetDomainRemark(ctx context.Context, domain, remark string) error
+	// LockDomain 锁定域名days天，锁定期间不能修改记录，返回解锁码
+	LockDomain(ctx context.Context, domain string, days int) (string, error)
+	UnlockDomain(ctx context.Context, domain, lockCode string) error
+}
+
+// DefaultTimeout 未配置TIMEOUT时单次服务商调用的超时时间
+const DefaultTimeout = 30 * time.Second
+
+// Account 已配置的服务商账号
+type Account struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// DnsManager 统一DNS管理器，按账号名称管理多个服务商
 type DnsManager struct {
-	dnsPodClient    *DnsPodClient
-	aliyunDnsClient *AliyunDnsClient
-}
-
-// NewDnsManager 创建DNS管理器
-func NewDnsManager(dnsPodToken, aliyunAccessKeyId, aliyunAccessKeySecret, aliyunRegionId string) *DnsManager {
-	manager := &DnsManager{}
-
-	if dnsPodToken != "" {
-		manager.dnsPodClient = NewDnsPodClient(dnsPodToken)
+	defaultName string
+	accounts    []Account
+	providers   map[string]Provider
+	timeouts    map[string]time.Duration
+	errs        map[string]error
+}
+
+// NewDnsManager 根据账号配置创建DNS管理器
+//
+// 单个账号配置错误不会影响其他账号，错误会在使用该账号时返回
+func NewDnsManager(configs []Config, defaultName string) *DnsManager {
+	manager := &DnsManager{
+		defaultName: defaultName,
+		providers:   make(map[string]Provider),
+		timeouts:    make(map[string]time.Duration),
+		errs:        make(map[string]error),
 	}
 
-	if aliyunAccessKeyId != "" && aliyunAccessKeySecret != "" {
-		manager.aliyunDnsClient = NewAliyunDnsClient(aliyunAccessKeyId, aliyunAccessKeySecret, aliyunRegionId)
+	for _, cfg := range configs {
+		manager.accounts = append(manager.accounts, Account{Name: cfg.Name, Type: cfg.Type})
+
+		manager.timeouts[cfg.Name] = DefaultTimeout
+		if timeout, err := strconv.Atoi(cfg.Get("TIMEOUT")); err == nil && timeout > 0 {
+			manager.timeouts[cfg.Name] = time.Duration(timeout) * time.Second
+		}
+
+		provider, err := NewProvider(cfg)
+		if err != nil {
+			log.Printf("DNS服务提供商 %s 初始化失败: %v", cfg.Name, err)
+			manager.errs[cfg.Name] = err
+			continue
+		}
+		manager.providers[cfg.Name] = provider
 	}
 
 	return manager
 }
 
-// 使用DNSPod
-func (m *DnsManager) UseDnsPod() bool {
-	return m.dnsPodClient != nil
-}
-
-// 使用阿里云DNS
-func (m *DnsManager) UseAliyunDns() bool {
-	return m.aliyunDnsClient != nil
-}
-
-// GetDnsPodDomainList 获取DNSPod域名列表
-func (m *DnsManager) GetDnsPodDomainList() ([]DnsDomain, error) {
-	if m.dnsPodClient == nil {
-		return nil, fmt.Errorf("DNSPod客户端未初始化")
-	}
-	return m.dnsPodClient.GetDomainList()
-}
-
-// GetDnsPodRecordList 获取DNSPod记录列表
-func (m *DnsManager) GetDnsPodRecordList(domain, subDomain string) ([]DnsRecord, error) {
-	if m.dnsPodClient == nil {
-		return nil, fmt.Errorf("DNSPod客户端未初始化")
+// Provider 根据账号名称获取服务商，名称为空时使用默认账号
+func (m *DnsManager) Provider(name string) (Provider, error) {
+	if name == "" {
+		name = m.defaultName
 	}
-	return m.dnsPodClient.GetRecordList(domain, subDomain)
-}
-
-// CreateDnsPodRecord 创建DNSPod记录
-func (m *DnsManager) CreateDnsPodRecord(domainID, subDomain, recordType, value, recordLine string) (*DnsRecord, error) {
-	if m.dnsPodClient == nil {
-		return nil, fmt.Errorf("DNSPod客户端未初始化")
-	}
-	return m.dnsPodClient.CreateRecord(domainID, subDomain, recordType, value, recordLine)
-}
-
-// UpdateDnsPodRecord 更新DNSPod记录
-func (m *DnsManager) UpdateDnsPodRecord(recordID, domainID, subDomain, recordType, value, recordLine string) (*DnsRecord, error) {
-	if m.dnsPodClient == nil {
-		return nil, fmt.Errorf("DNSPod客户端未初始化")
-	}
-	return m.dnsPodClient.UpdateRecord(recordID, domainID, subDomain, recordType, value, recordLine)
-}
-
-// DeleteDnsPodRecord 删除DNSPod记录
-func (m *DnsManager) DeleteDnsPodRecord(recordID, domainID string) error {
-	if m.dnsPodClient == nil {
-		return fmt.Errorf("DNSPod客户端未初始化")
+	if err, ok := m.errs[name]; ok {
+		return nil, fmt.Errorf("DNS服务提供商 %s 配置错误: %v", name, err)
 	}
-	return m.dnsPodClient.DeleteRecord(recordID, domainID)
-}
-
-// SetDnsPodRecordStatus 设置DNSPod记录状态
-func (m *DnsManager) SetDnsPodRecordStatus(recordID, domainID, status string) error {
-	if m.dnsPodClient == nil {
-		return fmt.Errorf("DNSPod客户端未初始化")
+	provider, ok := m.providers[name]
+	if !ok {
+		return nil, errorf(ErrInvalidParam, "DNS服务提供商 %s 未配置", name)
 	}
-	return m.dnsPodClient.SetRecordStatus(recordID, domainID, status)
+	return provider, nil
 }
 
-// GetAliyunDomainList 获取阿里云域名列表
-func (m *DnsManager) GetAliyunDomainList(pageNumber, pageSize int) ([]AliyunDnsRecord, error) {
-	if m.aliyunDnsClient == nil {
-		return nil, fmt.Errorf("阿里云DNS客户端未初始化")
+// WithTimeout 按账号配置的超时时间派生context，名称为空时使用默认账号
+func (m *DnsManager) WithTimeout(ctx context.Context, name string) (context.Context, context.CancelFunc) {
+	if name == "" {
+		name = m.defaultName
 	}
-	return m.aliyunDnsClient.GetAliyunDomainList(pageNumber, pageSize)
-}
-
-// GetAliyunRecordList 获取阿里云记录列表
-func (m *DnsManager) GetAliyunRecordList(domainName, rrKeyWord string) ([]AliyunDnsRecord, error) {
-	if m.aliyunDnsClient == nil {
-		return nil, fmt.Errorf("阿里云DNS客户端未初始化")
+	timeout, ok := m.timeouts[name]
+	if !ok {
+		timeout = DefaultTimeout
 	}
-	return m.aliyunDnsClient.GetAliyunRecordList(domainName, rrKeyWord)
+	return context.WithTimeout(ctx, timeout)
 }
 
-// CreateAliyunRecord 创建阿里云记录
-func (m *DnsManager) CreateAliyunRecord(domainName, rr, recordType, value string, ttl int64) (*AliyunDnsRecord, error) {
-	if m.aliyunDnsClient == nil {
-		return nil, fmt.Errorf("阿里云DNS客户端未初始化")
+// Type 获取账号的服务商类型
+func (m *DnsManager) Type(name string) string {
+	if name == "" {
+		name = m.defaultName
 	}
-	return m.aliyunDnsClient.CreateAliyunRecord(domainName, rr, recordType, value, ttl)
-}
-
-// UpdateAliyunRecord 更新阿里云记录
-func (m *DnsManager) UpdateAliyunRecord(recordId, rr, recordType, value string, ttl int64) (*AliyunDnsRecord, error) {
-	if m.aliyunDnsClient == nil {
-		return nil, fmt.Errorf("阿里云DNS客户端未初始化")
+	for _, account := range m.accounts {
+		if account.Name == name {
+			return account.Type
+		}
 	}
-	return m.aliyunDnsClient.UpdateAliyunRecord(recordId, rr, recordType, value, ttl)
+	return ""
 }
 
-// DeleteAliyunRecord 删除阿里云记录
-func (m *DnsManager) DeleteAliyunRecord(recordId string) error {
-	if m.aliyunDnsClient == nil {
-		return fmt.Errorf("阿里云DNS客户端未初始化")
-	}
-	return m.aliyunDnsClient.DeleteAliyunRecord(recordId)
+// Accounts 已配置的账号列表
+func (m *DnsManager) Accounts() []Account {
+	return m.accounts
 }
 
-// SetAliyunRecordStatus 设置阿里云记录状态
-func (m *DnsManager) SetAliyunRecordStatus(recordId, status string) error {
-	if m.aliyunDnsClient == nil {
-		return fmt.Errorf("阿里云DNS客户端未初始化")
-	}
-	return m.aliyunDnsClient.SetAliyunRecordStatus(recordId, status)
+// DefaultName 默认账号名称
+func (m *DnsManager) DefaultName() string {
+	return m.defaultName
 }
@@ -0,0 +1,88 @@
+package dns
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Line 解析线路(视图)
+type Line struct {
+	ID     string `json:"id,omitempty"`
+	Code   string `json:"code"`             // 服务商接口中使用的线路值
+	Name   string `json:"name"`             // 线路名称，如 默认、电信
+	Parent string `json:"parent,omitempty"` // 上级线路的代码
+}
+
+// DefaultLineName 未指定线路时使用的线路名称
+const DefaultLineName = "默认"
+
+// LineLister 可选接口，服务商支持按线路解析时实现，返回域名可用的线路
+type LineLister interface {
+	GetLineList(ctx context.Context, domain string) ([]Line, error)
+}
+
+// ResolveLine 按线路代码或名称查找线路，返回服务商接口中使用的线路值
+//
+// 代码不区分大小写；默认、default和空字符串都表示默认线路
+func ResolveLine(lines []Line, line string) (string, error) {
+	if line == "" {
+		line = DefaultLineName
+	}
+	for _, l := range lines {
+		if l.Name == line || strings.EqualFold(l.Code, line) {
+			return l.Code, nil
+		}
+	}
+	if line == DefaultLineName || strings.EqualFold(line, "default") {
+		for _, l := range lines {
+			if l.Name == DefaultLineName || strings.EqualFold(l.Code, "default") {
+				return l.Code, nil
+			}
+		}
+	}
+	return "", errorf(ErrInvalidParam, "无效的线路: %s", line)
+}
+
+// LineCache 按账号和域名缓存线路列表，线路随域名等级变化，很少需要实时获取
+type LineCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]lineCacheEntry
+}
+
+type lineCacheEntry struct {
+	lines   []Line
+	expires time.Time
+}
+
+// NewLineCache 创建线路缓存，ttl为缓存有效期
+func NewLineCache(ttl time.Duration) *LineCache {
+	return &LineCache{
+		ttl:     ttl,
+		entries: make(map[string]lineCacheEntry),
+	}
+}
+
+// Get 获取线路列表，缓存不存在或已过期时从服务商获取
+func (c *LineCache) Get(ctx context.Context, account string, lister LineLister, domain string) ([]Line, error) {
+	key := account + "\x00" + strings.ToLower(strings.TrimSuffix(domain, "."))
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.lines, nil
+	}
+
+	lines, err := lister.GetLineList(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[key] = lineCacheEntry{lines: lines, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return lines, nil
+}
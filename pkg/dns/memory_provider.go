@@ -0,0 +1,622 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+func init() {
+	Register(ProviderMemory, func(cfg Config) (Provider, error) {
+		var domains []string
+		for _, domain := range strings.Split(cfg.GetDefault("DOMAINS", "example.com"), ",") {
+			if domain = strings.TrimSpace(domain); domain != "" {
+				domains = append(domains, domain)
+			}
+		}
+
+		var provider *MemoryProvider
+		if file := cfg.Get("FILE"); file != "" {
+			var err error
+			if provider, err = NewFileProvider(file, domains); err != nil {
+				return nil, err
+			}
+		} else {
+			provider = NewMemoryProvider(domains)
+		}
+
+		if rate, err := strconv.ParseFloat(cfg.Get("FAIL_RATE"), 64); err == nil {
+			provider.FailRate = rate
+		}
+		for _, method := range strings.Split(cfg.Get("FAIL_ON"), ",") {
+			if method = strings.TrimSpace(method); method != "" {
+				provider.FailOn[method] = true
+			}
+		}
+		if latency, err := strconv.Atoi(cfg.Get("LATENCY")); err == nil && latency > 0 {
+			provider.Latency = time.Duration(latency) * time.Millisecond
+		}
+		return provider, nil
+	})
+}
+
+// MemoryProvider 内存中的模拟服务商，用于开发和测试
+//
+// 记录ID和域名ID为递增的数字，与DNSPod类似；指定文件时每次修改后写入JSON文件，重启后保留数据。
+// FailRate和FailOn用于模拟服务商错误，Latency用于模拟网络延迟。
+type MemoryProvider struct {
+	mu      sync.Mutex
+	file    string
+	nextID  int64
+	domains []*memoryDomain
+	rand    *rand.Rand
+
+	FailRate float64         // 随机失败的概率，0到1之间
+	FailOn   map[string]bool // 总是失败的方法，如 CreateRecord
+	Latency  time.Duration   // 每次调用的延迟
+}
+
+// memoryDomain 域名及其记录
+type memoryDomain struct {
+	Domain   Domain    `json:"domain"`
+	Records  []Record  `json:"records"`
+	LockCode string    `json:"lock_code,omitempty"` // 锁定时的解锁码
+	LockEnd  time.Time `json:"lock_end,omitempty"`
+}
+
+// memoryState 持久化到文件的内容
+type memoryState struct {
+	NextID  int64           `json:"next_id"`
+	Domains []*memoryDomain `json:"domains"`
+}
+
+// NewMemoryProvider 创建内存服务商，每个域名预置两条NS记录
+func NewMemoryProvider(domains []string) *MemoryProvider {
+	p := &MemoryProvider{
+		nextID: 1000000000,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+		FailOn: make(map[string]bool),
+	}
+	for _, name := range domains {
+		p.addDomain(name)
+	}
+	return p
+}
+
+// NewFileProvider 创建以JSON文件保存数据的服务商，文件不存在时按domains初始化
+func NewFileProvider(file string, domains []string) (*MemoryProvider, error) {
+	p := NewMemoryProvider(nil)
+	p.file = file
+
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(file), os.ModePerm); err != nil {
+			return nil, err
+		}
+		for _, name := range domains {
+			p.addDomain(name)
+		}
+		return p, p.save()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state memoryState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("解析文件 %s 失败: %v", file, err)
+	}
+	if state.NextID > p.nextID {
+		p.nextID = state.NextID
+	}
+	p.domains = state.Domains
+	return p, nil
+}
+
+// newID 生成下一个ID
+func (p *MemoryProvider) newID() string {
+	p.nextID++
+	return strconv.FormatInt(p.nextID, 10)
+}
+
+// addDomain 添加域名和默认的NS记录
+func (p *MemoryProvider) addDomain(name string) *memoryDomain {
+	d := &memoryDomain{
+		Domain: Domain{
+			ID:     p.newID(),
+			Name:   strings.ToLower(strings.TrimSuffix(name, ".")),
+			Status: "enable",
+			Grade:  "DP_Free",
+		},
+	}
+	for _, ns := range []string{"ns1.memory.dns", "ns2.memory.dns"} {
+		d.Records = append(d.Records, Record{
+			ID:     p.newID(),
+			Name:   "@",
+			Type:   "NS",
+			Value:  ns,
+			TTL:    86400,
+			Line:   DefaultLineName,
+			Status: RecordStatusEnable,
+		})
+	}
+	p.domains = append(p.domains, d)
+	return d
+}
+
+// save 写入文件，未指定文件时不做任何事
+func (p *MemoryProvider) save() error {
+	if p.file == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(memoryState{NextID: p.nextID, Domains: p.domains}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// 先写临时文件再改名，避免写入中断时损坏数据
+	tmp, err := ioutil.TempFile(filepath.Dir(p.file), filepath.Base(p.file)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), p.file)
+}
+
+// simulate 模拟延迟和错误，在获取p.mu之前调用，延迟期间不阻塞其他调用
+func (p *MemoryProvider) simulate(ctx context.Context, method string) error {
+	if p.Latency > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(p.Latency):
+		}
+	}
+
+	p.mu.Lock()
+	fail := p.FailOn[method] || (p.FailRate > 0 && p.rand.Float64() < p.FailRate)
+	p.mu.Unlock()
+	if fail {
+		return fmt.Errorf("API Error: 模拟错误 %s", method)
+	}
+	return nil
+}
+
+// domain 根据域名或域名ID查找域名
+func (p *MemoryProvider) domain(domain string) (*memoryDomain, error) {
+	domain = strings.TrimSuffix(domain, ".")
+	for _, d := range p.domains {
+		if d.Domain.ID == domain || strings.EqualFold(d.Domain.Name, domain) {
+			return d, nil
+		}
+	}
+	return nil, &APIError{Kind: ErrNotFound, Message: fmt.Sprintf("域名 %s 不存在", domain)}
+}
+
+// writable 域名锁定期间不能修改记录
+func (d *memoryDomain) writable() error {
+	if d.LockCode != "" && time.Now().Before(d.LockEnd) {
+		return &APIError{Kind: ErrConflict, Message: fmt.Sprintf("域名 %s 已锁定", d.Domain.Name)}
+	}
+	return nil
+}
+
+// record 查找记录的下标
+func (d *memoryDomain) record(recordID string) (int, error) {
+	for i := range d.Records {
+		if d.Records[i].ID == recordID {
+			return i, nil
+		}
+	}
+	return -1, &APIError{Kind: ErrNotFound, Message: fmt.Sprintf("记录 %s 不存在", recordID)}
+}
+
+// duplicate 是否存在相同的记录，exceptID为正在修改的记录
+func (d *memoryDomain) duplicate(record Record, exceptID string) bool {
+	for _, r := range d.Records {
+		if r.ID != exceptID && strings.EqualFold(r.Name, record.Name) && r.Type == record.Type &&
+			r.Value == record.Value && r.Line == record.Line {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeMemoryRecord 校验记录并填充默认值
+func normalizeMemoryRecord(record Record) (Record, error) {
+	if record.Type == "" || record.Value == "" {
+		return record, &APIError{Kind: ErrInvalidParam, Message: "记录类型和记录值不能为空"}
+	}
+	record.Type = strings.ToUpper(record.Type)
+	if record.Name == "" {
+		record.Name = "@"
+	}
+	if record.TTL <= 0 {
+		record.TTL = 600
+	}
+	if record.Line == "" {
+		record.Line = DefaultLineName
+	}
+	if record.Status != RecordStatusDisable {
+		record.Status = RecordStatusEnable
+	}
+	return record, nil
+}
+
+// GetDomainList 获取域名列表
+func (p *MemoryProvider) GetDomainList(ctx context.Context) ([]Domain, error) {
+	if err := p.simulate(ctx, "GetDomainList"); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	result := make([]Domain, 0, len(p.domains))
+	for _, d := range p.domains {
+		domain := d.Domain
+		domain.RecordCount = len(d.Records)
+		result = append(result, domain)
+	}
+	return result, nil
+}
+
+// GetRecordList 获取记录列表
+func (p *MemoryProvider) GetRecordList(ctx context.Context, domain, subDomain string) ([]Record, error) {
+	if err := p.simulate(ctx, "GetRecordList"); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Record
+	for _, r := range d.Records {
+		if subDomain != "" && !strings.EqualFold(r.Name, subDomain) {
+			continue
+		}
+		r.Domain = d.Domain.Name
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+// GetRecord 获取单条记录
+func (p *MemoryProvider) GetRecord(ctx context.Context, domain, recordID string) (*Record, error) {
+	if err := p.simulate(ctx, "GetRecord"); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return nil, err
+	}
+	i, err := d.record(recordID)
+	if err != nil {
+		return nil, err
+	}
+
+	record := d.Records[i]
+	record.Domain = d.Domain.Name
+	return &record, nil
+}
+
+// CreateRecord 创建记录，相同的记录已存在时返回错误
+func (p *MemoryProvider) CreateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	if err := p.simulate(ctx, "CreateRecord"); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.writable(); err != nil {
+		return nil, err
+	}
+	record, err = normalizeMemoryRecord(record)
+	if err != nil {
+		return nil, err
+	}
+	if d.duplicate(record, "") {
+		return nil, &APIError{Kind: ErrAlreadyExists, Message: "记录已经存在"}
+	}
+
+	record.ID = p.newID()
+	record.Domain = ""
+	d.Records = append(d.Records, record)
+	if err := p.save(); err != nil {
+		return nil, err
+	}
+
+	record.Domain = d.Domain.Name
+	return &record, nil
+}
+
+// UpdateRecord 更新记录，未指定状态时保持原状态
+func (p *MemoryProvider) UpdateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	if err := p.simulate(ctx, "UpdateRecord"); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.writable(); err != nil {
+		return nil, err
+	}
+	i, err := d.record(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status == "" {
+		record.Status = d.Records[i].Status
+	}
+	if record.Remark == "" && !record.RemarkSet {
+		record.Remark = d.Records[i].Remark
+	}
+	record, err = normalizeMemoryRecord(record)
+	if err != nil {
+		return nil, err
+	}
+	if d.duplicate(record, record.ID) {
+		return nil, &APIError{Kind: ErrAlreadyExists, Message: "记录已经存在"}
+	}
+
+	record.Domain = ""
+	d.Records[i] = record
+	if err := p.save(); err != nil {
+		return nil, err
+	}
+
+	record.Domain = d.Domain.Name
+	return &record, nil
+}
+
+// DeleteRecord 删除记录
+func (p *MemoryProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	if err := p.simulate(ctx, "DeleteRecord"); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return err
+	}
+	if err := d.writable(); err != nil {
+		return err
+	}
+	i, err := d.record(recordID)
+	if err != nil {
+		return err
+	}
+
+	d.Records = append(d.Records[:i], d.Records[i+1:]...)
+	return p.save()
+}
+
+// SetRecordStatus 设置记录状态
+func (p *MemoryProvider) SetRecordStatus(ctx context.Context, domain, recordID, status string) error {
+	if err := p.simulate(ctx, "SetRecordStatus"); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if status != RecordStatusEnable && status != RecordStatusDisable {
+		return &APIError{Kind: ErrInvalidParam, Message: fmt.Sprintf("无效的记录状态 %s", status)}
+	}
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return err
+	}
+	if err := d.writable(); err != nil {
+		return err
+	}
+	i, err := d.record(recordID)
+	if err != nil {
+		return err
+	}
+
+	d.Records[i].Status = status
+	return p.save()
+}
+
+// memoryLines 模拟服务商的线路，与DNSPod免费版相同，接口中使用线路名称
+var memoryLines = []string{DefaultLineName, "电信", "联通", "移动", "教育网", "境内", "境外"}
+
+// GetLineList 获取域名可用的线路
+func (p *MemoryProvider) GetLineList(ctx context.Context, domain string) ([]Line, error) {
+	if err := p.simulate(ctx, "GetLineList"); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, err := p.domain(domain); err != nil {
+		return nil, err
+	}
+
+	lines := make([]Line, 0, len(memoryLines))
+	for _, name := range memoryLines {
+		lines = append(lines, Line{Code: name, Name: name})
+	}
+	return lines, nil
+}
+
+// CreateDomain 添加域名，预置两条NS记录
+func (p *MemoryProvider) CreateDomain(ctx context.Context, name string) (*Domain, error) {
+	if err := p.simulate(ctx, "CreateDomain"); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, err := p.domain(name); err == nil {
+		return nil, &APIError{Kind: ErrAlreadyExists, Message: fmt.Sprintf("域名 %s 已经存在", name)}
+	}
+
+	d := p.addDomain(name)
+	if err := p.save(); err != nil {
+		return nil, err
+	}
+
+	domain := d.Domain
+	domain.RecordCount = len(d.Records)
+	return &domain, nil
+}
+
+// DeleteDomain 删除域名及其记录
+func (p *MemoryProvider) DeleteDomain(ctx context.Context, domain string) error {
+	if err := p.simulate(ctx, "DeleteDomain"); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return err
+	}
+	if err := d.writable(); err != nil {
+		return err
+	}
+
+	for i := range p.domains {
+		if p.domains[i] == d {
+			p.domains = append(p.domains[:i], p.domains[i+1:]...)
+			break
+		}
+	}
+	return p.save()
+}
+
+// SetDomainStatus 设置域名状态
+func (p *MemoryProvider) SetDomainStatus(ctx context.Context, domain, status string) error {
+	if err := p.simulate(ctx, "SetDomainStatus"); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if status != RecordStatusEnable && status != RecordStatusDisable {
+		return &APIError{Kind: ErrInvalidParam, Message: fmt.Sprintf("无效的域名状态 %s", status)}
+	}
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return err
+	}
+
+	d.Domain.Status = status
+	return p.save()
+}
+
+// SetDomainRemark 设置域名备注
+func (p *MemoryProvider) SetDomainRemark(ctx context.Context, domain, remark string) error {
+	if err := p.simulate(ctx, "SetDomainRemark"); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return err
+	}
+
+	d.Domain.Remark = remark
+	return p.save()
+}
+
+// LockDomain 锁定域名，返回随机生成的解锁码
+func (p *MemoryProvider) LockDomain(ctx context.Context, domain string, days int) (string, error) {
+	if err := p.simulate(ctx, "LockDomain"); err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if days <= 0 {
+		return "", &APIError{Kind: ErrInvalidParam, Message: "锁定天数必须大于0"}
+	}
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return "", err
+	}
+	if err := d.writable(); err != nil {
+		return "", err
+	}
+
+	d.LockCode = strconv.FormatInt(p.rand.Int63(), 36)
+	d.LockEnd = time.Now().AddDate(0, 0, days)
+	if err := p.save(); err != nil {
+		return "", err
+	}
+	return d.LockCode, nil
+}
+
+// UnlockDomain 使用解锁码解锁域名
+func (p *MemoryProvider) UnlockDomain(ctx context.Context, domain, lockCode string) error {
+	if err := p.simulate(ctx, "UnlockDomain"); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	d, err := p.domain(domain)
+	if err != nil {
+		return err
+	}
+	if d.LockCode == "" || lockCode != d.LockCode {
+		return &APIError{Kind: ErrInvalidParam, Message: "解锁码错误"}
+	}
+
+	d.LockCode = ""
+	d.LockEnd = time.Time{}
+	return p.save()
+}
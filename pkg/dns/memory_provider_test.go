@@ -0,0 +1,43 @@
+package dns
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryProviderLatencyConcurrent(t *testing.T) {
+	p := NewMemoryProvider([]string{"example.com"})
+	p.Latency = 100 * time.Millisecond
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.GetRecordList(context.Background(), "example.com", ""); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 延迟期间不持有锁，5个并发调用的总耗时接近一次延迟
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("5个并发调用耗时 %v，延迟被串行化", elapsed)
+	}
+}
+
+func TestMemoryProviderFailOn(t *testing.T) {
+	p := NewMemoryProvider([]string{"example.com"})
+	p.FailOn["CreateRecord"] = true
+
+	if _, err := p.CreateRecord(context.Background(), "example.com", Record{Name: "www", Type: "A", Value: "192.0.2.1"}); err == nil {
+		t.Error("FailOn中的方法应返回错误")
+	}
+	if _, err := p.GetRecordList(context.Background(), "example.com", ""); err != nil {
+		t.Errorf("其他方法不受影响: %v", err)
+	}
+}
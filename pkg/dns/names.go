@@ -0,0 +1,72 @@
+package dns
+
+import "strings"
+
+// toFQDN 将主机记录转换为完整域名(不带结尾的点)，@或空表示域名本身
+func toFQDN(name, zone string) string {
+	name = strings.TrimSuffix(name, ".")
+	zone = strings.TrimSuffix(zone, ".")
+	if name == "" || name == "@" {
+		return zone
+	}
+	if strings.EqualFold(name, zone) || strings.HasSuffix(strings.ToLower(name), "."+strings.ToLower(zone)) {
+		return name
+	}
+	return name + "." + zone
+}
+
+// toRelativeName 将完整域名转换为主机记录，域名本身返回@
+func toRelativeName(fqdn, zone string) string {
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	zone = strings.TrimSuffix(zone, ".")
+	if strings.EqualFold(fqdn, zone) {
+		return "@"
+	}
+	suffix := "." + strings.ToLower(zone)
+	if strings.HasSuffix(strings.ToLower(fqdn), suffix) {
+		return fqdn[:len(fqdn)-len(suffix)]
+	}
+	return fqdn
+}
+
+// quoteTXT 将TXT记录值转换为区域文件格式，超过255字节时拆分为多个字符串
+func quoteTXT(value string) string {
+	var parts []string
+	for len(value) > 255 {
+		parts = append(parts, value[:255])
+		value = value[255:]
+	}
+	parts = append(parts, value)
+
+	for i, part := range parts {
+		part = strings.Replace(part, `\`, `\\`, -1)
+		part = strings.Replace(part, `"`, `\"`, -1)
+		parts[i] = `"` + part + `"`
+	}
+	return strings.Join(parts, " ")
+}
+
+// unquoteTXT 将区域文件格式的TXT记录值还原，多个字符串直接拼接
+func unquoteTXT(value string) string {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, `"`) {
+		return value
+	}
+
+	var b strings.Builder
+	inQuote, escaped := false, false
+	for _, r := range value {
+		switch {
+		case escaped:
+			b.WriteRune(r)
+			escaped = false
+		case r == '\\' && inQuote:
+			escaped = true
+		case r == '"':
+			inQuote = !inQuote
+		case inQuote:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,157 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PowerDNSClient PowerDNS Authoritative HTTP API客户端，使用X-API-Key认证
+type PowerDNSClient struct {
+	BaseURL  string // API地址，如 http://127.0.0.1:8081
+	APIKey   string
+	ServerID string // 服务器ID，默认为localhost
+	HTTP     *HTTPClient
+}
+
+// PowerDNSZone 区域信息，列表接口不返回RRSets
+type PowerDNSZone struct {
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Kind        string          `json:"kind"` // Native, Master, Slave
+	Serial      uint32          `json:"serial"`
+	DNSSec      bool            `json:"dnssec"`
+	Account     string          `json:"account"`
+	Nameservers []string        `json:"nameservers,omitempty"`
+	RRSets      []PowerDNSRRSet `json:"rrsets,omitempty"`
+}
+
+// PowerDNSRRSet 记录集，同名同类型的记录共享TTL和注释
+type PowerDNSRRSet struct {
+	Name       string            `json:"name"`
+	Type       string            `json:"type"`
+	TTL        int64             `json:"ttl,omitempty"`
+	ChangeType string            `json:"changetype,omitempty"` // REPLACE, DELETE
+	Records    []PowerDNSRecord  `json:"records"`
+	Comments   []PowerDNSComment `json:"comments"` // 为null时PowerDNS不修改备注，为[]时清除
+}
+
+// PowerDNSRecord 记录集中的一条记录
+type PowerDNSRecord struct {
+	Content  string `json:"content"`
+	Disabled bool   `json:"disabled"`
+}
+
+// PowerDNSComment 记录集注释
+type PowerDNSComment struct {
+	Content    string `json:"content"`
+	Account    string `json:"account"`
+	ModifiedAt int64  `json:"modified_at,omitempty"`
+}
+
+// NewPowerDNSClient 创建PowerDNS客户端，serverID为空时使用localhost
+func NewPowerDNSClient(baseURL, apiKey, serverID string) *PowerDNSClient {
+	if serverID == "" {
+		serverID = "localhost"
+	}
+	return &PowerDNSClient{
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		APIKey:   apiKey,
+		ServerID: serverID,
+		HTTP:     NewHTTPClient(nil),
+	}
+}
+
+// makeRequest 发起API请求，result不为nil时解析响应
+func (c *PowerDNSClient) makeRequest(ctx context.Context, method, path string, query url.Values, body interface{}, result interface{}) error {
+	urlStr := c.BaseURL + "/api/v1/servers/" + url.PathEscape(c.ServerID) + path
+	if len(query) > 0 {
+		urlStr += "?" + query.Encode()
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	// POST创建区域不是幂等的，PATCH整体替换记录集可以重试
+	resp, data, err := c.HTTP.Do(ctx, method != "POST", func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, urlStr, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-API-Key", c.APIKey)
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return &APIError{
+				Kind:       powerDNSErrorKind(resp.StatusCode, apiErr.Error),
+				Code:       strconv.Itoa(resp.StatusCode),
+				Message:    apiErr.Error,
+				StatusCode: resp.StatusCode,
+			}
+		}
+		return fmt.Errorf("API请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(data))
+	}
+
+	if result != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(data))
+		}
+	}
+	return nil
+}
+
+// powerDNSErrorKind PowerDNS没有错误码，按HTTP状态码归类，创建已存在的区域时返回409
+func powerDNSErrorKind(statusCode int, message string) error {
+	if statusCode == http.StatusConflict && strings.Contains(message, "exists") {
+		return ErrAlreadyExists
+	}
+	return kindFromStatus(statusCode)
+}
+
+// GetZoneList 获取全部区域
+func (c *PowerDNSClient) GetZoneList(ctx context.Context) ([]PowerDNSZone, error) {
+	var zones []PowerDNSZone
+	if err := c.makeRequest(ctx, "GET", "/zones", nil, nil, &zones); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// GetZone 获取区域详情及全部记录集
+func (c *PowerDNSClient) GetZone(ctx context.Context, zoneID string) (*PowerDNSZone, error) {
+	var zone PowerDNSZone
+	if err := c.makeRequest(ctx, "GET", "/zones/"+url.PathEscape(zoneID), nil, nil, &zone); err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// PatchRRSets 修改记录集，REPLACE整体替换记录集，DELETE删除记录集
+func (c *PowerDNSClient) PatchRRSets(ctx context.Context, zoneID string, rrsets []PowerDNSRRSet) error {
+	body := map[string]interface{}{
+		"rrsets": rrsets,
+	}
+	return c.makeRequest(ctx, "PATCH", "/zones/"+url.PathEscape(zoneID), nil, body, nil)
+}
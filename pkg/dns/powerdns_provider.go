@@ -0,0 +1,406 @@
+package dns
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(ProviderPowerDNS, func(cfg Config) (Provider, error) {
+		baseURL := cfg.Get("BASE_URL")
+		apiKey := cfg.Get("API_KEY")
+		if baseURL == "" || apiKey == "" {
+			return nil, fmt.Errorf("PowerDNS API地址或API Key未配置")
+		}
+		client := NewPowerDNSClient(baseURL, apiKey, cfg.Get("SERVER_ID"))
+		client.HTTP.Configure(cfg)
+		return NewPowerDNSProvider(client), nil
+	})
+}
+
+// PowerDNSProvider 将PowerDNSClient适配为Provider
+//
+// PowerDNS以记录集(名称+类型)为单位修改，TTL和注释属于记录集，
+// 这里每条记录对应一条统一记录，记录ID由名称、类型和记录内容编码而成，
+// 记录集的第一条注释对应Remark，记录的disabled对应状态。
+type PowerDNSProvider struct {
+	client *PowerDNSClient
+}
+
+// NewPowerDNSProvider 创建PowerDNS服务商
+func NewPowerDNSProvider(client *PowerDNSClient) *PowerDNSProvider {
+	return &PowerDNSProvider{client: client}
+}
+
+// powerDNSRecordKey 统一记录ID对应的记录集和内容
+type powerDNSRecordKey struct {
+	Name    string
+	Type    string
+	Content string
+}
+
+// encode 编码为可用于URL的记录ID
+func (k powerDNSRecordKey) encode() string {
+	raw := strings.Join([]string{k.Name, k.Type, k.Content}, "\x00")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// sameSet 是否属于同一个记录集
+func (k powerDNSRecordKey) sameSet(other powerDNSRecordKey) bool {
+	return strings.EqualFold(k.Name, other.Name) && k.Type == other.Type
+}
+
+// decodePowerDNSRecordID 解析记录ID
+func decodePowerDNSRecordID(id string) (powerDNSRecordKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return powerDNSRecordKey{}, errorf(ErrInvalidParam, "无效的PowerDNS记录ID: %s", id)
+	}
+	parts := strings.Split(string(raw), "\x00")
+	if len(parts) != 3 {
+		return powerDNSRecordKey{}, errorf(ErrInvalidParam, "无效的PowerDNS记录ID: %s", id)
+	}
+	return powerDNSRecordKey{Name: parts[0], Type: parts[1], Content: parts[2]}, nil
+}
+
+// powerDNSZoneID 区域ID即带结尾点的区域名称
+func powerDNSZoneID(domain string) string {
+	return strings.TrimSuffix(domain, ".") + "."
+}
+
+// findPowerDNSRRSet 在区域中查找记录集，不存在时返回nil
+func findPowerDNSRRSet(zone *PowerDNSZone, key powerDNSRecordKey) *PowerDNSRRSet {
+	for i := range zone.RRSets {
+		if strings.EqualFold(zone.RRSets[i].Name, key.Name) && zone.RRSets[i].Type == key.Type {
+			return &zone.RRSets[i]
+		}
+	}
+	return nil
+}
+
+// findPowerDNSRecord 查找包含记录的记录集，记录集中没有该内容(如记录ID已随值变化)时返回nil
+func findPowerDNSRecord(zone *PowerDNSZone, key powerDNSRecordKey) *PowerDNSRRSet {
+	set := findPowerDNSRRSet(zone, key)
+	if set == nil {
+		return nil
+	}
+	for _, r := range set.Records {
+		if r.Content == key.Content {
+			return set
+		}
+	}
+	return nil
+}
+
+// GetDomainList 获取域名列表
+func (p *PowerDNSProvider) GetDomainList(ctx context.Context) ([]Domain, error) {
+	zones, err := p.client.GetZoneList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Domain, 0, len(zones))
+	for _, z := range zones {
+		result = append(result, Domain{
+			ID:     z.ID,
+			Name:   strings.TrimSuffix(z.Name, "."),
+			Status: "enable",
+			Grade:  strings.ToLower(z.Kind),
+			Remark: z.Account,
+		})
+	}
+	return result, nil
+}
+
+// GetRecordList 获取记录列表
+func (p *PowerDNSProvider) GetRecordList(ctx context.Context, domain, subDomain string) ([]Record, error) {
+	zone, err := p.client.GetZone(ctx, powerDNSZoneID(domain))
+	if err != nil {
+		return nil, err
+	}
+	zoneName := strings.TrimSuffix(zone.Name, ".")
+
+	name := ""
+	if subDomain != "" {
+		name = toFQDN(subDomain, zoneName) + "."
+	}
+
+	var result []Record
+	for _, set := range zone.RRSets {
+		if set.Type == "SOA" {
+			continue
+		}
+		if name != "" && !strings.EqualFold(set.Name, name) {
+			continue
+		}
+		result = append(result, fromPowerDNSRRSet(zoneName, set)...)
+	}
+	return result, nil
+}
+
+// CreateRecord 创建记录，记录集已存在时追加记录
+func (p *PowerDNSProvider) CreateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	zone, err := p.client.GetZone(ctx, powerDNSZoneID(domain))
+	if err != nil {
+		return nil, err
+	}
+	zoneName := strings.TrimSuffix(zone.Name, ".")
+
+	key := powerDNSKey(zoneName, record)
+	set := powerDNSAddRecord(findPowerDNSRRSet(zone, key), key, record)
+	if err := p.client.PatchRRSets(ctx, zone.ID, []PowerDNSRRSet{*set}); err != nil {
+		return nil, err
+	}
+
+	return powerDNSResult(zoneName, key, record), nil
+}
+
+// UpdateRecord 更新记录，记录集变化时在同一个PATCH中移出旧记录并写入新记录
+func (p *PowerDNSProvider) UpdateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	oldKey, err := decodePowerDNSRecordID(record.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	zone, err := p.client.GetZone(ctx, powerDNSZoneID(domain))
+	if err != nil {
+		return nil, err
+	}
+	zoneName := strings.TrimSuffix(zone.Name, ".")
+
+	oldSet := findPowerDNSRecord(zone, oldKey)
+	if oldSet == nil {
+		return nil, errorf(ErrNotFound, "PowerDNS记录 %s 不存在", record.ID)
+	}
+
+	// 未指定状态时保持原状态
+	if record.Status == "" {
+		record.Status = powerDNSStatus(oldSet, oldKey.Content)
+	}
+
+	key := powerDNSKey(zoneName, record)
+	var rrsets []PowerDNSRRSet
+	if key.sameSet(oldKey) {
+		rrsets = append(rrsets, *powerDNSAddRecord(powerDNSRemoveRecord(oldSet, oldKey.Content), key, record))
+	} else {
+		rrsets = append(rrsets, powerDNSRemoveChange(oldSet, oldKey.Content))
+		rrsets = append(rrsets, *powerDNSAddRecord(findPowerDNSRRSet(zone, key), key, record))
+	}
+
+	if err := p.client.PatchRRSets(ctx, zone.ID, rrsets); err != nil {
+		return nil, err
+	}
+
+	return powerDNSResult(zoneName, key, record), nil
+}
+
+// DeleteRecord 删除记录，记录集只剩这一条时删除整个记录集
+func (p *PowerDNSProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	key, err := decodePowerDNSRecordID(recordID)
+	if err != nil {
+		return err
+	}
+
+	zone, err := p.client.GetZone(ctx, powerDNSZoneID(domain))
+	if err != nil {
+		return err
+	}
+
+	set := findPowerDNSRecord(zone, key)
+	if set == nil {
+		return errorf(ErrNotFound, "PowerDNS记录 %s 不存在", recordID)
+	}
+
+	return p.client.PatchRRSets(ctx, zone.ID, []PowerDNSRRSet{powerDNSRemoveChange(set, key.Content)})
+}
+
+// SetRecordStatus 设置记录状态，对应PowerDNS记录的disabled
+func (p *PowerDNSProvider) SetRecordStatus(ctx context.Context, domain, recordID, status string) error {
+	key, err := decodePowerDNSRecordID(recordID)
+	if err != nil {
+		return err
+	}
+
+	zone, err := p.client.GetZone(ctx, powerDNSZoneID(domain))
+	if err != nil {
+		return err
+	}
+
+	set := findPowerDNSRRSet(zone, key)
+	if set == nil {
+		return errorf(ErrNotFound, "PowerDNS记录 %s 不存在", recordID)
+	}
+
+	found := false
+	result := powerDNSReplace(set)
+	for i := range result.Records {
+		if result.Records[i].Content == key.Content {
+			result.Records[i].Disabled = status == RecordStatusDisable
+			found = true
+		}
+	}
+	if !found {
+		return errorf(ErrNotFound, "PowerDNS记录 %s 不存在", recordID)
+	}
+
+	return p.client.PatchRRSets(ctx, zone.ID, []PowerDNSRRSet{*result})
+}
+
+// powerDNSStatus 记录集中内容为content的记录的状态
+func powerDNSStatus(set *PowerDNSRRSet, content string) string {
+	for _, r := range set.Records {
+		if r.Content == content && r.Disabled {
+			return RecordStatusDisable
+		}
+	}
+	return RecordStatusEnable
+}
+
+// powerDNSKey 根据统一记录计算记录集和内容
+func powerDNSKey(zone string, record Record) powerDNSRecordKey {
+	return powerDNSRecordKey{
+		Name:    toFQDN(record.Name, zone) + ".",
+		Type:    strings.ToUpper(record.Type),
+		Content: powerDNSContent(record),
+	}
+}
+
+// powerDNSContent 统一记录值转换为PowerDNS记录内容，主机名需要带结尾的点
+func powerDNSContent(record Record) string {
+	value := record.Value
+	switch strings.ToUpper(record.Type) {
+	case "CNAME", "NS", "PTR", "ALIAS", "DNAME":
+		return strings.TrimSuffix(value, ".") + "."
+	case "MX":
+		return fmt.Sprintf("%d %s.", record.Priority, strings.TrimSuffix(value, "."))
+	case "SRV":
+		// 优先级 权重 端口 目标
+		fields := strings.Fields(value)
+		if len(fields) == 4 {
+			fields[3] = strings.TrimSuffix(fields[3], ".") + "."
+			return strings.Join(fields, " ")
+		}
+	case "TXT", "SPF":
+		return quoteTXT(value)
+	}
+	return value
+}
+
+// powerDNSReplace 复制记录集并标记为REPLACE
+func powerDNSReplace(set *PowerDNSRRSet) *PowerDNSRRSet {
+	result := PowerDNSRRSet{
+		Name:       set.Name,
+		Type:       set.Type,
+		TTL:        set.TTL,
+		ChangeType: "REPLACE",
+		Records:    append([]PowerDNSRecord(nil), set.Records...),
+	}
+	return &result
+}
+
+// powerDNSAddRecord 将记录加入记录集，记录集不存在时新建
+func powerDNSAddRecord(set *PowerDNSRRSet, key powerDNSRecordKey, record Record) *PowerDNSRRSet {
+	result := &PowerDNSRRSet{Name: key.Name, Type: key.Type, ChangeType: "REPLACE"}
+	if set != nil {
+		result = powerDNSReplace(set)
+	}
+	if record.TTL > 0 {
+		result.TTL = record.TTL
+	}
+	if result.TTL <= 0 {
+		result.TTL = 600
+	}
+	if record.Remark != "" {
+		result.Comments = []PowerDNSComment{{Content: record.Remark}}
+	} else if record.RemarkSet {
+		result.Comments = []PowerDNSComment{}
+	}
+
+	records := result.Records[:0]
+	for _, r := range result.Records {
+		if r.Content != key.Content {
+			records = append(records, r)
+		}
+	}
+	result.Records = append(records, PowerDNSRecord{
+		Content:  key.Content,
+		Disabled: record.Status == RecordStatusDisable,
+	})
+	return result
+}
+
+// powerDNSRemoveRecord 返回去掉指定内容后的记录集副本
+func powerDNSRemoveRecord(set *PowerDNSRRSet, content string) *PowerDNSRRSet {
+	result := powerDNSReplace(set)
+	result.Records = nil
+	for _, r := range set.Records {
+		if r.Content != content {
+			result.Records = append(result.Records, r)
+		}
+	}
+	return result
+}
+
+// powerDNSRemoveChange 移出记录的变更，没有剩余记录时删除记录集
+func powerDNSRemoveChange(set *PowerDNSRRSet, content string) PowerDNSRRSet {
+	remaining := powerDNSRemoveRecord(set, content)
+	if len(remaining.Records) == 0 {
+		return PowerDNSRRSet{Name: set.Name, Type: set.Type, ChangeType: "DELETE"}
+	}
+	return *remaining
+}
+
+// powerDNSResult 构建写入后的统一记录
+func powerDNSResult(zone string, key powerDNSRecordKey, record Record) *Record {
+	record.ID = key.encode()
+	record.Domain = zone
+	record.Name = toRelativeName(key.Name, zone)
+	record.Type = key.Type
+	if record.Status != RecordStatusDisable {
+		record.Status = RecordStatusEnable
+	}
+	return &record
+}
+
+// fromPowerDNSRRSet 将记录集展开为统一记录
+func fromPowerDNSRRSet(zone string, set PowerDNSRRSet) []Record {
+	base := Record{
+		Domain: zone,
+		Name:   toRelativeName(set.Name, zone),
+		Type:   set.Type,
+		TTL:    set.TTL,
+	}
+	if len(set.Comments) > 0 {
+		base.Remark = set.Comments[0].Content
+	}
+
+	key := powerDNSRecordKey{Name: set.Name, Type: set.Type}
+	records := make([]Record, 0, len(set.Records))
+	for _, r := range set.Records {
+		record := base
+		key.Content = r.Content
+		record.ID = key.encode()
+		record.Value = r.Content
+		record.Status = RecordStatusEnable
+		if r.Disabled {
+			record.Status = RecordStatusDisable
+		}
+
+		switch set.Type {
+		case "CNAME", "NS", "PTR", "ALIAS", "DNAME":
+			record.Value = strings.TrimSuffix(r.Content, ".")
+		case "TXT", "SPF":
+			record.Value = unquoteTXT(r.Content)
+		case "MX":
+			if parts := strings.SplitN(r.Content, " ", 2); len(parts) == 2 {
+				record.Priority, _ = strconv.ParseInt(parts[0], 10, 64)
+				record.Value = strings.TrimSuffix(parts[1], ".")
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
@@ -0,0 +1,313 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testPowerDNSKey = "test-api-key"
+
+// fakePowerDNSChange PATCH请求中的记录集变更，records和comments为null时不修改，与PowerDNS一致
+type fakePowerDNSChange struct {
+	Name       string             `json:"name"`
+	Type       string             `json:"type"`
+	TTL        int64              `json:"ttl"`
+	ChangeType string             `json:"changetype"`
+	Records    *[]PowerDNSRecord  `json:"records"`
+	Comments   *[]PowerDNSComment `json:"comments"`
+}
+
+// fakePowerDNS 进程内的PowerDNS Authoritative HTTP API
+type fakePowerDNS struct {
+	mu    sync.Mutex
+	zones map[string]*PowerDNSZone // 按区域ID
+}
+
+func (f *fakePowerDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("X-API-Key") != testPowerDNSKey {
+		f.fail(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/servers/localhost/zones")
+	if path == r.URL.Path {
+		f.fail(w, http.StatusNotFound, "Not Found")
+		return
+	}
+
+	if path == "" && r.Method == http.MethodGet {
+		zones := []PowerDNSZone{}
+		for _, z := range f.zones {
+			zone := *z
+			zone.RRSets = nil
+			zones = append(zones, zone)
+		}
+		f.write(w, http.StatusOK, zones)
+		return
+	}
+
+	zone, ok := f.zones[strings.TrimPrefix(path, "/")]
+	if !ok {
+		f.fail(w, http.StatusNotFound, "Could not find domain '"+strings.TrimPrefix(path, "/")+"'")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		f.write(w, http.StatusOK, zone)
+	case http.MethodPatch:
+		var body struct {
+			RRSets []fakePowerDNSChange `json:"rrsets"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			f.fail(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		for _, change := range body.RRSets {
+			f.patch(zone, change)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.fail(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+	}
+}
+
+// patch 应用一个记录集变更，记录为空时删除记录集
+func (f *fakePowerDNS) patch(zone *PowerDNSZone, change fakePowerDNSChange) {
+	index := -1
+	for i, set := range zone.RRSets {
+		if strings.EqualFold(set.Name, change.Name) && set.Type == change.Type {
+			index = i
+		}
+	}
+	if change.ChangeType == "DELETE" {
+		if index >= 0 {
+			zone.RRSets = append(zone.RRSets[:index], zone.RRSets[index+1:]...)
+		}
+		return
+	}
+
+	if index < 0 {
+		zone.RRSets = append(zone.RRSets, PowerDNSRRSet{Name: change.Name, Type: change.Type})
+		index = len(zone.RRSets) - 1
+	}
+	set := &zone.RRSets[index]
+	set.TTL = change.TTL
+	if change.Records != nil {
+		set.Records = *change.Records
+	}
+	if change.Comments != nil {
+		set.Comments = *change.Comments
+	}
+	if len(set.Records) == 0 {
+		zone.RRSets = append(zone.RRSets[:index], zone.RRSets[index+1:]...)
+	}
+}
+
+func (f *fakePowerDNS) fail(w http.ResponseWriter, status int, message string) {
+	f.write(w, status, map[string]string{"error": message})
+}
+
+func (f *fakePowerDNS) write(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// set 查找记录集，不存在时返回nil
+func (f *fakePowerDNS) set(name, recordType string) *PowerDNSRRSet {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, zone := range f.zones {
+		for i := range zone.RRSets {
+			if zone.RRSets[i].Name == name && zone.RRSets[i].Type == recordType {
+				set := zone.RRSets[i]
+				return &set
+			}
+		}
+	}
+	return nil
+}
+
+// newTestPowerDNSProvider 启动假的PowerDNS API，返回连接到它的服务商
+func newTestPowerDNSProvider(t *testing.T) (*fakePowerDNS, *PowerDNSProvider) {
+	t.Helper()
+	f := &fakePowerDNS{zones: map[string]*PowerDNSZone{
+		"example.com.": {
+			ID:   "example.com.",
+			Name: "example.com.",
+			Kind: "Native",
+			RRSets: []PowerDNSRRSet{
+				{Name: "example.com.", Type: "SOA", TTL: 3600, Records: []PowerDNSRecord{{Content: "ns1.example.com. admin.example.com. 1 10800 3600 604800 3600"}}},
+				{Name: "example.com.", Type: "NS", TTL: 3600, Records: []PowerDNSRecord{{Content: "ns1.example.com."}}},
+				{Name: "www.example.com.", Type: "A", TTL: 600, Records: []PowerDNSRecord{{Content: "192.0.2.1"}},
+					Comments: []PowerDNSComment{{Content: "web server"}}},
+			},
+		},
+	}}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	client := NewPowerDNSClient(srv.URL, testPowerDNSKey, "")
+	client.HTTP = newTestHTTPClient(nil)
+	return f, NewPowerDNSProvider(client)
+}
+
+func TestPowerDNSProvider(t *testing.T) {
+	f, p := newTestPowerDNSProvider(t)
+	ctx := context.Background()
+
+	domains, err := p.GetDomainList(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 1 || domains[0].Name != "example.com" || domains[0].Grade != "native" {
+		t.Errorf("域名列表 = %+v", domains)
+	}
+
+	// 记录列表不含SOA
+	records, err := p.GetRecordList(ctx, "example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || findRecord(records, "@", "SOA") != nil {
+		t.Errorf("记录列表 = %+v", records)
+	}
+	if r := findRecord(records, "www", "A"); r == nil || r.Remark != "web server" || r.Status != RecordStatusEnable {
+		t.Errorf("www的A记录 = %+v", r)
+	}
+
+	// 创建：同一记录集追加记录
+	first, err := p.CreateRecord(ctx, "example.com", Record{Name: "api", Type: "A", Value: "192.0.2.10", TTL: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := p.CreateRecord(ctx, "example.com", Record{Name: "api", Type: "A", Value: "192.0.2.11", TTL: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set := f.set("api.example.com.", "A"); set == nil || len(set.Records) != 2 || set.TTL != 300 {
+		t.Fatalf("api的记录集 = %+v", set)
+	}
+	mx, err := p.CreateRecord(ctx, "example.com", Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 10, TTL: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set := f.set("example.com.", "MX"); set == nil || set.Records[0].Content != "10 mail.example.com." {
+		t.Errorf("MX记录集 = %+v", set)
+	}
+
+	// 暂停后修改值，未指定状态时保持暂停
+	if err := p.SetRecordStatus(ctx, "example.com", first.ID, RecordStatusDisable); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := p.UpdateRecord(ctx, "example.com", Record{ID: first.ID, Name: "api", Type: "A", Value: "192.0.2.20", TTL: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID == first.ID || updated.Status != RecordStatusDisable {
+		t.Errorf("修改后的记录 = %+v, 期望新的记录ID且保持暂停", updated)
+	}
+	set := f.set("api.example.com.", "A")
+	if set == nil || len(set.Records) != 2 {
+		t.Fatalf("修改后api的记录集 = %+v", set)
+	}
+	for _, r := range set.Records {
+		if want := r.Content == "192.0.2.20"; r.Disabled != want {
+			t.Errorf("记录 %s 的disabled = %v", r.Content, r.Disabled)
+		}
+	}
+
+	// 指定状态时按指定的状态修改
+	updated, err = p.UpdateRecord(ctx, "example.com", Record{ID: updated.ID, Name: "api", Type: "A", Value: "192.0.2.20", TTL: 300, Status: RecordStatusEnable})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != RecordStatusEnable || f.set("api.example.com.", "A").Records[1].Disabled {
+		t.Errorf("启用后的记录 = %+v", updated)
+	}
+
+	// 修改名称时从旧记录集移出，写入新记录集
+	moved, err := p.UpdateRecord(ctx, "example.com", Record{ID: second.ID, Name: "api2", Type: "A", Value: "192.0.2.11", TTL: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set := f.set("api.example.com.", "A"); set == nil || len(set.Records) != 1 {
+		t.Errorf("移出后api的记录集 = %+v", set)
+	}
+	if set := f.set("api2.example.com.", "A"); set == nil || len(set.Records) != 1 {
+		t.Errorf("api2的记录集 = %+v", set)
+	}
+
+	// 删除最后一条记录时删除记录集
+	if err := p.DeleteRecord(ctx, "example.com", moved.ID); err != nil {
+		t.Fatal(err)
+	}
+	if set := f.set("api2.example.com.", "A"); set != nil {
+		t.Errorf("删除后api2的记录集 = %+v", set)
+	}
+	if err := p.DeleteRecord(ctx, "example.com", mx.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// 记录ID已过期(值已修改)时返回ErrNotFound
+	if err := p.DeleteRecord(ctx, "example.com", second.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("删除不存在的记录返回 %v", err)
+	}
+	if _, err := p.GetRecordList(ctx, "example.org", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("不存在的区域返回 %v", err)
+	}
+}
+
+func TestPowerDNSAuthFailed(t *testing.T) {
+	_, p := newTestPowerDNSProvider(t)
+	p.client.APIKey = "wrong-key"
+	if _, err := p.GetDomainList(context.Background()); !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("API Key错误时返回 %v, 期望ErrAuthFailed", err)
+	}
+}
+
+func TestPowerDNSRecordRemark(t *testing.T) {
+	f, p := newTestPowerDNSProvider(t)
+	ctx := context.Background()
+
+	records, err := p.GetRecordList(ctx, "example.com", "www")
+	if err != nil {
+		t.Fatal(err)
+	}
+	www := findRecord(records, "www", "A")
+	if www == nil {
+		t.Fatalf("记录列表 = %+v", records)
+	}
+
+	// 未指定备注时保留记录集的注释
+	updated, err := p.UpdateRecord(ctx, "example.com", Record{ID: www.ID, Name: "www", Type: "A", Value: "192.0.2.2", TTL: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set := f.set("www.example.com.", "A"); set == nil || len(set.Comments) != 1 || set.Comments[0].Content != "web server" {
+		t.Errorf("未指定备注时的记录集 = %+v", set)
+	}
+
+	// 指定空备注时清除注释
+	if _, err := p.UpdateRecord(ctx, "example.com", Record{ID: updated.ID, Name: "www", Type: "A", Value: "192.0.2.2", TTL: 600, RemarkSet: true}); err != nil {
+		t.Fatal(err)
+	}
+	if set := f.set("www.example.com.", "A"); set == nil || len(set.Comments) != 0 {
+		t.Errorf("清除备注后的记录集 = %+v", set)
+	}
+	records, err = p.GetRecordList(ctx, "example.com", "www")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := findRecord(records, "www", "A"); r == nil || r.Remark != "" {
+		t.Errorf("清除备注后的记录 = %+v", r)
+	}
+}
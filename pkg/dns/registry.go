@@ -0,0 +1,75 @@
+package dns
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Config 服务商账号配置
+type Config struct {
+	Name    string            // 账号名称，如 aliyun-prod、dnspod-cn
+	Type    string            // 服务商类型，如 aliyun、dns_pod
+	Options map[string]string // 其余配置项，键为大写，如 TOKEN、ACCESS_KEY_ID
+}
+
+// Get 获取配置项，不存在时返回空字符串
+func (c Config) Get(key string) string {
+	return c.Options[strings.ToUpper(key)]
+}
+
+// GetDefault 获取配置项，不存在或为空时返回默认值
+func (c Config) GetDefault(key, def string) string {
+	if v := c.Get(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// Factory 根据账号配置创建服务商
+type Factory func(cfg Config) (Provider, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register 注册服务商类型，通常在各实现的init中调用
+func Register(typ string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	if factory == nil {
+		panic("dns: Register factory is nil")
+	}
+	if _, dup := factories[typ]; dup {
+		panic("dns: Register called twice for type " + typ)
+	}
+	factories[typ] = factory
+}
+
+// Types 已注册的服务商类型
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	types := make([]string, 0, len(factories))
+	for typ := range factories {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NewProvider 根据账号配置创建服务商
+func NewProvider(cfg Config) (Provider, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[cfg.Type]
+	factoriesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("未知的DNS服务商类型: %s", cfg.Type)
+	}
+	return factory(cfg)
+}
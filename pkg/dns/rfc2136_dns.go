@@ -0,0 +1,165 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+// RFC2136Client RFC 2136动态更新客户端，使用TSIG认证，通过AXFR读取区域
+type RFC2136Client struct {
+	Server        string // 服务器地址，如 127.0.0.1:53
+	Net           string // 更新请求使用的协议，tcp或udp
+	TsigKey       string // TSIG密钥名称
+	TsigAlgorithm string // TSIG算法，如 hmac-sha256
+	TsigSecret    string // TSIG密钥，base64编码
+	Timeout       time.Duration
+}
+
+// tsigAlgorithms 支持的TSIG算法
+var tsigAlgorithms = map[string]string{
+	"hmac-md5":    mdns.HmacMD5,
+	"hmac-sha1":   mdns.HmacSHA1,
+	"hmac-sha224": mdns.HmacSHA224,
+	"hmac-sha256": mdns.HmacSHA256,
+	"hmac-sha384": mdns.HmacSHA384,
+	"hmac-sha512": mdns.HmacSHA512,
+}
+
+// NewRFC2136Client 创建RFC 2136客户端，服务器地址未指定端口时使用53
+func NewRFC2136Client(server, tsigKey, tsigAlgorithm, tsigSecret string) (*RFC2136Client, error) {
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	if tsigAlgorithm == "" {
+		tsigAlgorithm = "hmac-sha256"
+	}
+	algorithm, ok := tsigAlgorithms[strings.ToLower(strings.TrimSuffix(tsigAlgorithm, "."))]
+	if !ok {
+		return nil, fmt.Errorf("不支持的TSIG算法: %s", tsigAlgorithm)
+	}
+
+	client := &RFC2136Client{
+		Server:        server,
+		Net:           "tcp",
+		TsigAlgorithm: algorithm,
+		TsigSecret:    tsigSecret,
+		Timeout:       10 * time.Second,
+	}
+	if tsigKey != "" {
+		client.TsigKey = mdns.Fqdn(strings.ToLower(tsigKey))
+	}
+	return client, nil
+}
+
+// tsigSecrets TSIG密钥表，未配置TSIG时返回nil
+func (c *RFC2136Client) tsigSecrets() map[string]string {
+	if c.TsigKey == "" {
+		return nil
+	}
+	return map[string]string{c.TsigKey: c.TsigSecret}
+}
+
+// setTsig 为请求附加TSIG签名
+func (c *RFC2136Client) setTsig(m *mdns.Msg) {
+	if c.TsigKey != "" {
+		m.SetTsig(c.TsigKey, c.TsigAlgorithm, 300, time.Now().Unix())
+	}
+}
+
+// Transfer 通过AXFR读取区域的全部记录，不含SOA和DNSSEC记录
+func (c *RFC2136Client) Transfer(ctx context.Context, zone string) ([]mdns.RR, error) {
+	m := new(mdns.Msg)
+	m.SetAxfr(mdns.Fqdn(zone))
+	c.setTsig(m)
+
+	// Transfer不支持context，自行建立连接，ctx结束时关闭连接使读取立即返回
+	dialer := &net.Dialer{Timeout: c.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.Server)
+	if err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	t := &mdns.Transfer{
+		Conn:         &mdns.Conn{Conn: conn},
+		ReadTimeout:  c.Timeout,
+		WriteTimeout: c.Timeout,
+		TsigSecret:   c.tsigSecrets(),
+	}
+	env, err := t.In(m, c.Server)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	var records []mdns.RR
+	for e := range env {
+		if e.Error != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("AXFR %s 失败: %v", zone, e.Error)
+		}
+		for _, rr := range e.RR {
+			switch rr.Header().Rrtype {
+			case mdns.TypeSOA, mdns.TypeRRSIG, mdns.TypeNSEC, mdns.TypeNSEC3, mdns.TypeNSEC3PARAM, mdns.TypeDNSKEY:
+				continue
+			}
+			records = append(records, rr)
+		}
+	}
+	return records, nil
+}
+
+// Update 发送动态更新，先删除remove中的记录再添加insert中的记录，在服务器端原子执行
+func (c *RFC2136Client) Update(ctx context.Context, zone string, remove, insert []mdns.RR) error {
+	m := new(mdns.Msg)
+	m.SetUpdate(mdns.Fqdn(zone))
+	if len(remove) > 0 {
+		m.Remove(remove)
+	}
+	if len(insert) > 0 {
+		m.Insert(insert)
+	}
+	c.setTsig(m)
+
+	client := &mdns.Client{
+		Net:        c.Net,
+		Timeout:    c.Timeout,
+		TsigSecret: c.tsigSecrets(),
+	}
+	r, _, err := client.ExchangeContext(ctx, m, c.Server)
+	if err != nil {
+		return err
+	}
+	if r.Rcode != mdns.RcodeSuccess {
+		return &APIError{
+			Kind:    rfc2136ErrorKind(r.Rcode),
+			Code:    mdns.RcodeToString[r.Rcode],
+			Message: fmt.Sprintf("DNS UPDATE %s 失败", zone),
+		}
+	}
+	return nil
+}
+
+// rfc2136ErrorKind DNS UPDATE响应码对应的错误类别，TSIG校验失败时服务器返回NOTAUTH
+func rfc2136ErrorKind(rcode int) error {
+	switch rcode {
+	case mdns.RcodeNameError, mdns.RcodeNXRrset, mdns.RcodeNotZone:
+		return ErrNotFound
+	case mdns.RcodeYXDomain, mdns.RcodeYXRrset:
+		return ErrAlreadyExists
+	case mdns.RcodeNotAuth, mdns.RcodeRefused:
+		return ErrAuthFailed
+	case mdns.RcodeFormatError:
+		return ErrInvalidParam
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package dns
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+func init() {
+	Register(ProviderRFC2136, func(cfg Config) (Provider, error) {
+		server := cfg.Get("SERVER")
+		if server == "" {
+			return nil, fmt.Errorf("RFC 2136服务器地址未配置")
+		}
+
+		var zones []string
+		for _, zone := range strings.Split(cfg.Get("ZONE"), ",") {
+			if zone = strings.TrimSpace(zone); zone != "" {
+				zones = append(zones, strings.TrimSuffix(zone, "."))
+			}
+		}
+		if len(zones) == 0 {
+			return nil, fmt.Errorf("RFC 2136区域未配置")
+		}
+
+		client, err := NewRFC2136Client(server, cfg.Get("TSIG_KEY"), cfg.Get("TSIG_ALGORITHM"), cfg.Get("TSIG_SECRET"))
+		if err != nil {
+			return nil, err
+		}
+		client.Net = cfg.GetDefault("NET", "tcp")
+		if timeout, err := strconv.Atoi(cfg.Get("TIMEOUT")); err == nil && timeout > 0 {
+			client.Timeout = time.Duration(timeout) * time.Second
+		}
+		return NewRFC2136Provider(client, zones), nil
+	})
+}
+
+// RFC2136Provider 基于RFC 2136动态更新的服务商，适用于自建的BIND、Knot、PowerDNS等
+//
+// DNS协议中记录没有ID，记录ID由名称、类型和记录数据编码而成；记录不支持暂停
+type RFC2136Provider struct {
+	client *RFC2136Client
+	zones  []string
+}
+
+// NewRFC2136Provider 创建RFC 2136服务商
+func NewRFC2136Provider(client *RFC2136Client, zones []string) *RFC2136Provider {
+	return &RFC2136Provider{client: client, zones: zones}
+}
+
+// zone 检查域名是否为已配置的区域
+func (p *RFC2136Provider) zone(domain string) (string, error) {
+	domain = strings.TrimSuffix(domain, ".")
+	for _, zone := range p.zones {
+		if strings.EqualFold(zone, domain) {
+			return zone, nil
+		}
+	}
+	return "", errorf(ErrNotFound, "区域 %s 未配置", domain)
+}
+
+// GetDomainList 获取已配置的区域
+func (p *RFC2136Provider) GetDomainList(ctx context.Context) ([]Domain, error) {
+	result := make([]Domain, 0, len(p.zones))
+	for _, zone := range p.zones {
+		result = append(result, Domain{
+			ID:     zone,
+			Name:   zone,
+			Status: "enable",
+		})
+	}
+	return result, nil
+}
+
+// GetRecordList 通过AXFR获取记录列表
+func (p *RFC2136Provider) GetRecordList(ctx context.Context, domain, subDomain string) ([]Record, error) {
+	zone, err := p.zone(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	rrs, err := p.client.Transfer(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	name := ""
+	if subDomain != "" {
+		name = mdns.Fqdn(toFQDN(subDomain, zone))
+	}
+
+	var result []Record
+	for _, rr := range rrs {
+		if name != "" && !strings.EqualFold(rr.Header().Name, name) {
+			continue
+		}
+		result = append(result, fromRR(zone, rr))
+	}
+	return result, nil
+}
+
+// CreateRecord 添加记录
+func (p *RFC2136Provider) CreateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	zone, err := p.zone(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	rr, err := toRR(zone, record)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.client.Update(ctx, zone, nil, []mdns.RR{rr}); err != nil {
+		return nil, err
+	}
+
+	result := fromRR(zone, rr)
+	return &result, nil
+}
+
+// UpdateRecord 在同一个UPDATE请求中删除旧记录并添加新记录
+func (p *RFC2136Provider) UpdateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	zone, err := p.zone(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := decodeRRID(record.ID)
+	if err != nil {
+		return nil, err
+	}
+	rr, err := toRR(zone, record)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.client.Update(ctx, zone, []mdns.RR{old}, []mdns.RR{rr}); err != nil {
+		return nil, err
+	}
+
+	result := fromRR(zone, rr)
+	return &result, nil
+}
+
+// DeleteRecord 删除记录
+func (p *RFC2136Provider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	zone, err := p.zone(domain)
+	if err != nil {
+		return err
+	}
+
+	old, err := decodeRRID(recordID)
+	if err != nil {
+		return err
+	}
+	return p.client.Update(ctx, zone, []mdns.RR{old}, nil)
+}
+
+// SetRecordStatus DNS协议中的记录没有启用/暂停状态
+func (p *RFC2136Provider) SetRecordStatus(ctx context.Context, domain, recordID, status string) error {
+	return errorf(ErrUnsupported, "RFC 2136不支持设置记录状态")
+}
+
+// rrData 记录数据部分，即去掉名称、TTL、类别和类型后的内容
+func rrData(rr mdns.RR) string {
+	return strings.TrimSpace(strings.TrimPrefix(rr.String(), rr.Header().String()))
+}
+
+// encodeRRID 由名称、类型和记录数据编码记录ID
+func encodeRRID(rr mdns.RR) string {
+	raw := strings.Join([]string{
+		strings.ToLower(rr.Header().Name),
+		mdns.TypeToString[rr.Header().Rrtype],
+		rrData(rr),
+	}, "\x00")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeRRID 解析记录ID
+func decodeRRID(id string) (mdns.RR, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return nil, errorf(ErrInvalidParam, "无效的记录ID: %s", id)
+	}
+	parts := strings.Split(string(raw), "\x00")
+	if len(parts) != 3 {
+		return nil, errorf(ErrInvalidParam, "无效的记录ID: %s", id)
+	}
+
+	rr, err := mdns.NewRR(fmt.Sprintf("%s 0 IN %s %s", parts[0], parts[1], parts[2]))
+	if err != nil || rr == nil {
+		return nil, errorf(ErrInvalidParam, "无效的记录ID: %s", id)
+	}
+	return rr, nil
+}
+
+// toRR 统一记录转换为资源记录
+func toRR(zone string, record Record) (mdns.RR, error) {
+	ttl := record.TTL
+	if ttl <= 0 {
+		ttl = 600
+	}
+
+	value := record.Value
+	switch strings.ToUpper(record.Type) {
+	case "CNAME", "NS", "PTR", "DNAME":
+		value = mdns.Fqdn(value)
+	case "MX":
+		value = fmt.Sprintf("%d %s", record.Priority, mdns.Fqdn(value))
+	case "SRV":
+		// 优先级 权重 端口 目标
+		fields := strings.Fields(value)
+		if len(fields) == 4 {
+			fields[3] = mdns.Fqdn(fields[3])
+			value = strings.Join(fields, " ")
+		}
+	case "TXT", "SPF":
+		value = quoteTXT(value)
+	}
+
+	line := fmt.Sprintf("%s %d IN %s %s", mdns.Fqdn(toFQDN(record.Name, zone)), ttl, strings.ToUpper(record.Type), value)
+	rr, err := mdns.NewRR(line)
+	if err != nil {
+		return nil, errorf(ErrInvalidParam, "记录格式错误: %v", err)
+	}
+	if rr == nil {
+		return nil, errorf(ErrInvalidParam, "记录格式错误: %s", line)
+	}
+	return rr, nil
+}
+
+// fromRR 资源记录转换为统一记录
+func fromRR(zone string, rr mdns.RR) Record {
+	h := rr.Header()
+	record := Record{
+		ID:     encodeRRID(rr),
+		Domain: zone,
+		Name:   toRelativeName(h.Name, zone),
+		Type:   mdns.TypeToString[h.Rrtype],
+		Value:  rrData(rr),
+		TTL:    int64(h.Ttl),
+		Status: RecordStatusEnable,
+	}
+
+	switch v := rr.(type) {
+	case *mdns.MX:
+		record.Priority = int64(v.Preference)
+		record.Value = strings.TrimSuffix(v.Mx, ".")
+	case *mdns.CNAME:
+		record.Value = strings.TrimSuffix(v.Target, ".")
+	case *mdns.NS:
+		record.Value = strings.TrimSuffix(v.Ns, ".")
+	case *mdns.PTR:
+		record.Value = strings.TrimSuffix(v.Ptr, ".")
+	case *mdns.TXT:
+		record.Value = strings.Join(v.Txt, "")
+	case *mdns.SPF:
+		record.Value = strings.Join(v.Txt, "")
+	}
+	return record
+}
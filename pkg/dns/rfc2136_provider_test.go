@@ -0,0 +1,317 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mdns "github.com/miekg/dns"
+)
+
+const (
+	testTsigKey    = "test-key."
+	testTsigSecret = "c2VjcmV0LWtleS1mb3ItdGVzdGluZy0xMjM0NTY="
+)
+
+// testZone 进程内的权威服务器，支持TSIG认证的AXFR和RFC 2136动态更新
+type testZone struct {
+	mu  sync.Mutex
+	soa mdns.RR
+	rrs []mdns.RR
+}
+
+func (z *testZone) ServeDNS(w mdns.ResponseWriter, r *mdns.Msg) {
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		m := new(mdns.Msg)
+		m.SetRcode(r, mdns.RcodeNotAuth)
+		w.WriteMsg(m)
+		return
+	}
+	tsig := r.IsTsig()
+
+	if r.Opcode == mdns.OpcodeUpdate {
+		z.update(r.Ns)
+		m := new(mdns.Msg)
+		m.SetReply(r)
+		m.SetTsig(tsig.Hdr.Name, tsig.Algorithm, tsig.Fudge, time.Now().Unix())
+		w.WriteMsg(m)
+		return
+	}
+
+	if len(r.Question) == 1 && r.Question[0].Qtype == mdns.TypeAXFR {
+		z.mu.Lock()
+		rrs := append([]mdns.RR{z.soa}, z.rrs...)
+		rrs = append(rrs, z.soa)
+		z.mu.Unlock()
+
+		ch := make(chan *mdns.Envelope, 1)
+		ch <- &mdns.Envelope{RR: rrs}
+		close(ch)
+		if err := new(mdns.Transfer).Out(w, r, ch); err != nil {
+			w.Close()
+		}
+		return
+	}
+
+	m := new(mdns.Msg)
+	m.SetRcode(r, mdns.RcodeNotImplemented)
+	w.WriteMsg(m)
+}
+
+// update 按RFC 2136第2.5节处理更新段：类别NONE删除单条记录，ANY删除记录集，其他为添加
+func (z *testZone) update(ns []mdns.RR) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	for _, rr := range ns {
+		h := rr.Header()
+		switch h.Class {
+		case mdns.ClassNONE:
+			z.remove(func(existing mdns.RR) bool { return sameRR(existing, rr) })
+		case mdns.ClassANY:
+			z.remove(func(existing mdns.RR) bool {
+				return strings.EqualFold(existing.Header().Name, h.Name) && existing.Header().Rrtype == h.Rrtype
+			})
+		default:
+			z.remove(func(existing mdns.RR) bool { return sameRR(existing, rr) })
+			z.rrs = append(z.rrs, mdns.Copy(rr))
+		}
+	}
+}
+
+func (z *testZone) remove(match func(mdns.RR) bool) {
+	kept := z.rrs[:0]
+	for _, rr := range z.rrs {
+		if !match(rr) {
+			kept = append(kept, rr)
+		}
+	}
+	z.rrs = kept
+}
+
+func sameRR(a, b mdns.RR) bool {
+	return strings.EqualFold(a.Header().Name, b.Header().Name) && a.Header().Rrtype == b.Header().Rrtype &&
+		strings.EqualFold(rrData(a), rrData(b))
+}
+
+func (z *testZone) has(t *testing.T, line string) bool {
+	t.Helper()
+	want, err := mdns.NewRR(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	for _, rr := range z.rrs {
+		if sameRR(rr, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// startTestZone 在127.0.0.1的随机端口启动服务器，返回区域和服务器地址
+func startTestZone(t *testing.T) (*testZone, string) {
+	t.Helper()
+	z := &testZone{}
+	for _, line := range []string{
+		"example.com. 3600 IN SOA ns1.example.com. admin.example.com. 1 7200 3600 1209600 300",
+		"example.com. 3600 IN NS ns1.example.com.",
+		"www.example.com. 600 IN A 192.0.2.1",
+	} {
+		rr, err := mdns.NewRR(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rr.Header().Rrtype == mdns.TypeSOA {
+			z.soa = rr
+			continue
+		}
+		z.rrs = append(z.rrs, rr)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	srv := &mdns.Server{
+		Listener:          l,
+		Handler:           z,
+		TsigSecret:        map[string]string{testTsigKey: testTsigSecret},
+		NotifyStartedFunc: func() { close(started) },
+		// 默认的MsgAcceptFunc拒绝动态更新
+		MsgAcceptFunc: func(dh mdns.Header) mdns.MsgAcceptAction { return mdns.MsgAccept },
+	}
+	go srv.ActivateAndServe()
+	<-started
+	t.Cleanup(func() { srv.Shutdown() })
+	return z, l.Addr().String()
+}
+
+func newTestRFC2136Provider(t *testing.T, addr, secret string) *RFC2136Provider {
+	t.Helper()
+	client, err := NewRFC2136Client(addr, "test-key", "hmac-sha256", secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewRFC2136Provider(client, []string{"example.com"})
+}
+
+// findRecord 在记录列表中按名称和类型查找
+func findRecord(records []Record, name, recordType string) *Record {
+	for i := range records {
+		if records[i].Name == name && records[i].Type == recordType {
+			return &records[i]
+		}
+	}
+	return nil
+}
+
+func TestRFC2136Provider(t *testing.T) {
+	z, addr := startTestZone(t)
+	p := newTestRFC2136Provider(t, addr, testTsigSecret)
+	ctx := context.Background()
+
+	// AXFR读取区域，不含SOA
+	records, err := p.GetRecordList(ctx, "example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("AXFR返回 %d 条记录，期望2条: %+v", len(records), records)
+	}
+	if r := findRecord(records, "www", "A"); r == nil || r.Value != "192.0.2.1" || r.TTL != 600 {
+		t.Errorf("www的A记录 = %+v", r)
+	}
+	if findRecord(records, "@", "SOA") != nil {
+		t.Error("AXFR结果不应包含SOA记录")
+	}
+
+	// 创建
+	created, err := p.CreateRecord(ctx, "example.com", Record{Name: "api", Type: "A", Value: "192.0.2.10", TTL: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !z.has(t, "api.example.com. 300 IN A 192.0.2.10") {
+		t.Fatal("服务器中没有创建的记录")
+	}
+	mx, err := p.CreateRecord(ctx, "example.com", Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 10, TTL: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txt, err := p.CreateRecord(ctx, "example.com", Record{Name: "@", Type: "TXT", Value: "v=spf1 include:example.net ~all", TTL: 600})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 列表中的记录ID与创建时返回的一致
+	records, err = p.GetRecordList(ctx, "example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []*Record{created, mx, txt} {
+		got := findRecord(records, want.Name, want.Type)
+		if got == nil || got.ID != want.ID {
+			t.Errorf("%s %s: 列表中的记录 = %+v, 期望ID %s", want.Name, want.Type, got, want.ID)
+		}
+	}
+	if r := findRecord(records, "@", "MX"); r == nil || r.Priority != 10 || r.Value != "mail.example.com" {
+		t.Errorf("MX记录 = %+v", r)
+	}
+	if r := findRecord(records, "@", "TXT"); r == nil || r.Value != "v=spf1 include:example.net ~all" {
+		t.Errorf("TXT记录 = %+v", r)
+	}
+
+	// 修改：同一个UPDATE中删除旧值并添加新值
+	updated, err := p.UpdateRecord(ctx, "example.com", Record{ID: created.ID, Name: "api", Type: "A", Value: "192.0.2.20", TTL: 300})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ID == created.ID {
+		t.Error("记录数据变化后记录ID应随之变化")
+	}
+	if z.has(t, "api.example.com. 300 IN A 192.0.2.10") || !z.has(t, "api.example.com. 300 IN A 192.0.2.20") {
+		t.Error("修改后服务器中的记录不正确")
+	}
+
+	// 删除
+	if err := p.DeleteRecord(ctx, "example.com", updated.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.DeleteRecord(ctx, "example.com", txt.ID); err != nil {
+		t.Fatal(err)
+	}
+	records, err = p.GetRecordList(ctx, "example.com", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findRecord(records, "api", "A") != nil || findRecord(records, "@", "TXT") != nil {
+		t.Errorf("删除后仍有记录: %+v", records)
+	}
+
+	// 按主机记录过滤
+	records, err = p.GetRecordList(ctx, "example.com", "www")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Name != "www" {
+		t.Errorf("按主机记录过滤 = %+v", records)
+	}
+
+	if _, err := p.GetRecordList(ctx, "example.org", ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("未配置的区域返回 %v, 期望ErrNotFound", err)
+	}
+}
+
+func TestRFC2136TsigRejected(t *testing.T) {
+	z, addr := startTestZone(t)
+	p := newTestRFC2136Provider(t, addr, "d3Jvbmctc2VjcmV0LWtleS0xMjM0NTY3ODkwYWI=")
+
+	_, err := p.CreateRecord(context.Background(), "example.com", Record{Name: "api", Type: "A", Value: "192.0.2.10", TTL: 300})
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Errorf("TSIG密钥错误时返回 %v, 期望ErrAuthFailed", err)
+	}
+	if z.has(t, "api.example.com. 300 IN A 192.0.2.10") {
+		t.Error("TSIG校验失败的更新不应生效")
+	}
+}
+
+func TestRRIDRoundTrip(t *testing.T) {
+	tests := []Record{
+		{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600},
+		{Name: "@", Type: "AAAA", Value: "2001:db8::1", TTL: 600},
+		{Name: "blog", Type: "CNAME", Value: "www.example.com", TTL: 600},
+		{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 20, TTL: 600},
+		{Name: "@", Type: "TXT", Value: "hello world \"quoted\"", TTL: 600},
+		{Name: "_sip._tcp", Type: "SRV", Value: "10 5 5060 sip.example.com", TTL: 600},
+		{Name: "@", Type: "CAA", Value: "0 issue \"letsencrypt.org\"", TTL: 600},
+	}
+	for _, record := range tests {
+		t.Run(record.Type, func(t *testing.T) {
+			rr, err := toRR("example.com", record)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := fromRR("example.com", rr)
+
+			decoded, err := decodeRRID(result.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameRR(decoded, rr) {
+				t.Errorf("解析记录ID = %s, 期望 %s", decoded, rr)
+			}
+			if id := encodeRRID(decoded); id != result.ID {
+				t.Errorf("重新编码的记录ID = %s, 期望 %s", id, result.ID)
+			}
+		})
+	}
+
+	if _, err := decodeRRID("not-base64!"); !errors.Is(err, ErrInvalidParam) {
+		t.Errorf("无效的记录ID返回 %v", err)
+	}
+}
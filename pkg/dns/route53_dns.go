@@ -0,0 +1,488 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Route53BaseURL Route 53 API地址
+const Route53BaseURL = "https://route53.amazonaws.com"
+
+const route53Namespace = "https://route53.amazonaws.com/doc/2013-04-01/"
+
+// Route53Client AWS Route 53 API客户端
+type Route53Client struct {
+	AccessKeyId     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string // 签名使用的区域，Route 53为全局服务，固定为us-east-1
+	BaseURL         string
+	HTTP            *HTTPClient
+}
+
+// Route53HostedZone 托管区域
+type Route53HostedZone struct {
+	Id                     string `xml:"Id"`
+	Name                   string `xml:"Name"`
+	CallerReference        string `xml:"CallerReference"`
+	ResourceRecordSetCount int    `xml:"ResourceRecordSetCount"`
+	Config                 struct {
+		Comment     string `xml:"Comment"`
+		PrivateZone bool   `xml:"PrivateZone"`
+	} `xml:"Config"`
+}
+
+// Route53ResourceRecord 记录值
+type Route53ResourceRecord struct {
+	Value string `xml:"Value"`
+}
+
+// Route53AliasTarget 别名目标
+type Route53AliasTarget struct {
+	HostedZoneId         string `xml:"HostedZoneId"`
+	DNSName              string `xml:"DNSName"`
+	EvaluateTargetHealth bool   `xml:"EvaluateTargetHealth"`
+}
+
+// Route53ResourceRecordSet 记录集，字段顺序与API的XML结构一致
+type Route53ResourceRecordSet struct {
+	Name            string                  `xml:"Name"`
+	Type            string                  `xml:"Type"`
+	SetIdentifier   string                  `xml:"SetIdentifier,omitempty"`
+	Weight          *int64                  `xml:"Weight,omitempty"`
+	Region          string                  `xml:"Region,omitempty"`
+	TTL             *int64                  `xml:"TTL,omitempty"`
+	ResourceRecords []Route53ResourceRecord `xml:"ResourceRecords>ResourceRecord,omitempty"`
+	AliasTarget     *Route53AliasTarget     `xml:"AliasTarget,omitempty"`
+}
+
+// Route53Change 单个变更
+type Route53Change struct {
+	Action            string                   `xml:"Action"` // CREATE, DELETE, UPSERT
+	ResourceRecordSet Route53ResourceRecordSet `xml:"ResourceRecordSet"`
+}
+
+// Route53ChangeInfo 变更状态
+type Route53ChangeInfo struct {
+	Id          string `xml:"Id"`
+	Status      string `xml:"Status"` // PENDING, INSYNC
+	SubmittedAt string `xml:"SubmittedAt"`
+	Comment     string `xml:"Comment"`
+}
+
+// route53ErrorResponse Route 53错误响应
+type route53ErrorResponse struct {
+	Error struct {
+		Type    string `xml:"Type"`
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	} `xml:"Error"`
+	Messages  []string `xml:"Messages>Message"`
+	RequestId string   `xml:"RequestId"`
+}
+
+// route53Throttled Route 53限流时返回Throttling，上一个变更未完成时返回PriorRequestNotComplete
+func route53Throttled(statusCode int, body []byte) bool {
+	var errResp route53ErrorResponse
+	if statusCode < 400 || xml.Unmarshal(body, &errResp) != nil {
+		return false
+	}
+	return route53ErrorKind(errResp.Error.Code, errResp.Error.Message) == ErrThrottled
+}
+
+// route53ErrorKind Route 53错误码对应的错误类别，InvalidChangeBatch需要根据错误信息区分
+func route53ErrorKind(code, message string) error {
+	switch code {
+	case "Throttling", "PriorRequestNotComplete":
+		return ErrThrottled
+	case "NoSuchHostedZone", "NoSuchChange", "NoSuchHealthCheck":
+		return ErrNotFound
+	case "InvalidClientTokenId", "SignatureDoesNotMatch", "IncompleteSignature", "AccessDenied",
+		"AccessDeniedException", "MissingAuthenticationToken", "ExpiredToken":
+		return ErrAuthFailed
+	case "HostedZoneAlreadyExists", "ConflictingDomainExists":
+		return ErrAlreadyExists
+	case "LimitsExceeded", "TooManyHostedZones":
+		return ErrQuotaExceeded
+	case "InvalidInput", "InvalidArgument", "InvalidDomainName", "ValidationError":
+		return ErrInvalidParam
+	case "InvalidChangeBatch":
+		switch {
+		case strings.Contains(message, "already exists"):
+			return ErrAlreadyExists
+		case strings.Contains(message, "not found"):
+			return ErrNotFound
+		case strings.Contains(message, "conflicts"):
+			return ErrConflict
+		}
+		return ErrInvalidParam
+	}
+	return nil
+}
+
+// NewRoute53Client 创建Route 53客户端，baseURL为空时使用官方地址
+func NewRoute53Client(accessKeyId, secretAccessKey, sessionToken, baseURL string) *Route53Client {
+	if baseURL == "" {
+		baseURL = Route53BaseURL
+	}
+	return &Route53Client{
+		AccessKeyId:     accessKeyId,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+		Region:          "us-east-1",
+		BaseURL:         strings.TrimRight(baseURL, "/"),
+		HTTP:            NewHTTPClient(route53Throttled),
+	}
+}
+
+// sign 计算AWS Signature Version 4签名，返回Authorization头
+func (c *Route53Client) sign(req *http.Request, payload []byte, now time.Time) string {
+	return signV4(req, payload, now, c.AccessKeyId, c.SecretAccessKey, c.SessionToken, c.Region, "route53")
+}
+
+// signV4 按AWS Signature Version 4对请求签名，设置X-Amz-Date等请求头并返回Authorization头
+func signV4(req *http.Request, payload []byte, now time.Time, accessKeyId, secretAccessKey, sessionToken, region, service string) string {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := now.UTC().Format("20060102")
+
+	// 规范化查询字符串，空格编码为%20
+	query := req.URL.Query()
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var queryStr string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			if queryStr != "" {
+				queryStr += "&"
+			}
+			queryStr += awsEscape(k) + "=" + awsEscape(v)
+		}
+	}
+
+	// 参与签名的请求头
+	headers := map[string]string{
+		"host":       req.URL.Host,
+		"x-amz-date": amzDate,
+	}
+	if sessionToken != "" {
+		headers["x-amz-security-token"] = sessionToken
+	}
+	var headerNames []string
+	for k := range headers {
+		headerNames = append(headerNames, k)
+	}
+	sort.Strings(headerNames)
+
+	var canonicalHeaders string
+	for _, k := range headerNames {
+		canonicalHeaders += k + ":" + strings.TrimSpace(headers[k]) + "\n"
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		queryStr,
+		canonicalHeaders,
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	// 构建待签名字符串
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	// 逐级派生签名密钥
+	key := hmacSHA256([]byte("AWS4"+secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", sessionToken)
+	}
+
+	return fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		accessKeyId, scope, signedHeaders, signature)
+}
+
+// hmacSHA256 计算HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsEscape 按RFC 3986编码
+func awsEscape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+// makeRequest 发起Route 53 API请求，result不为nil时解析XML响应
+func (c *Route53Client) makeRequest(ctx context.Context, method, path string, query url.Values, body interface{}, result interface{}) error {
+	var payload []byte
+	if body != nil {
+		data, err := xml.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = append([]byte(xml.Header), data...)
+	}
+
+	urlStr := c.BaseURL + "/2013-04-01" + path
+	if len(query) > 0 {
+		urlStr += "?" + query.Encode()
+	}
+
+	// ChangeResourceRecordSets为POST，重复提交DELETE会失败，不盲目重试；每次尝试重新签名
+	resp, data, err := c.HTTP.Do(ctx, method != "POST", func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, urlStr, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/xml")
+		}
+		req.Header.Set("Authorization", c.sign(req, payload, time.Now()))
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		var errResp route53ErrorResponse
+		if xml.Unmarshal(data, &errResp) == nil {
+			code, message := errResp.Error.Code, errResp.Error.Message
+			if code == "" && len(errResp.Messages) > 0 {
+				code, message = "InvalidChangeBatch", strings.Join(errResp.Messages, "; ")
+			}
+			if code != "" {
+				kind := route53ErrorKind(code, message)
+				if kind == nil {
+					kind = kindFromStatus(resp.StatusCode)
+				}
+				requestID := errResp.RequestId
+				if requestID == "" {
+					requestID = resp.Header.Get("X-Amzn-Requestid")
+				}
+				return &APIError{
+					Kind:       kind,
+					Code:       code,
+					Message:    message,
+					RequestID:  requestID,
+					StatusCode: resp.StatusCode,
+				}
+			}
+		}
+		return fmt.Errorf("API请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(data))
+	}
+
+	if result != nil {
+		if err := xml.Unmarshal(data, result); err != nil {
+			return fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(data))
+		}
+	}
+	return nil
+}
+
+// ListHostedZones 获取全部托管区域
+func (c *Route53Client) ListHostedZones(ctx context.Context) ([]Route53HostedZone, error) {
+	var zones []Route53HostedZone
+	marker := ""
+	for {
+		query := url.Values{}
+		query.Set("maxitems", "100")
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		var result struct {
+			HostedZones []Route53HostedZone `xml:"HostedZones>HostedZone"`
+			IsTruncated bool                `xml:"IsTruncated"`
+			NextMarker  string              `xml:"NextMarker"`
+		}
+		if err := c.makeRequest(ctx, "GET", "/hostedzone", query, nil, &result); err != nil {
+			return nil, err
+		}
+		zones = append(zones, result.HostedZones...)
+
+		if !result.IsTruncated || result.NextMarker == "" {
+			break
+		}
+		marker = result.NextMarker
+	}
+	return zones, nil
+}
+
+// GetHostedZoneByName 根据域名查找托管区域
+func (c *Route53Client) GetHostedZoneByName(ctx context.Context, name string) (*Route53HostedZone, error) {
+	name = strings.TrimSuffix(name, ".") + "."
+	query := url.Values{}
+	query.Set("dnsname", name)
+	query.Set("maxitems", "1")
+
+	var result struct {
+		HostedZones []Route53HostedZone `xml:"HostedZones>HostedZone"`
+	}
+	if err := c.makeRequest(ctx, "GET", "/hostedzonesbyname", query, nil, &result); err != nil {
+		return nil, err
+	}
+	if len(result.HostedZones) == 0 || !strings.EqualFold(result.HostedZones[0].Name, name) {
+		return nil, errorf(ErrNotFound, "Route 53托管区域 %s 不存在", name)
+	}
+	return &result.HostedZones[0], nil
+}
+
+// GetHostedZone 根据ID获取托管区域
+func (c *Route53Client) GetHostedZone(ctx context.Context, zoneID string) (*Route53HostedZone, error) {
+	var result struct {
+		HostedZone Route53HostedZone `xml:"HostedZone"`
+	}
+	if err := c.makeRequest(ctx, "GET", "/hostedzone/"+route53ZoneID(zoneID), nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.HostedZone, nil
+}
+
+// ListResourceRecordSets 获取记录集，name不为空时只返回该名称下的记录集
+func (c *Route53Client) ListResourceRecordSets(ctx context.Context, zoneID, name string) ([]Route53ResourceRecordSet, error) {
+	var sets []Route53ResourceRecordSet
+	if name != "" {
+		name = strings.TrimSuffix(name, ".") + "."
+	}
+
+	nextName, nextType, nextIdentifier := name, "", ""
+	for {
+		query := url.Values{}
+		query.Set("maxitems", "300")
+		if nextName != "" {
+			query.Set("name", nextName)
+		}
+		if nextType != "" {
+			query.Set("type", nextType)
+		}
+		if nextIdentifier != "" {
+			query.Set("identifier", nextIdentifier)
+		}
+
+		var result struct {
+			ResourceRecordSets   []Route53ResourceRecordSet `xml:"ResourceRecordSets>ResourceRecordSet"`
+			IsTruncated          bool                       `xml:"IsTruncated"`
+			NextRecordName       string                     `xml:"NextRecordName"`
+			NextRecordType       string                     `xml:"NextRecordType"`
+			NextRecordIdentifier string                     `xml:"NextRecordIdentifier"`
+		}
+		if err := c.makeRequest(ctx, "GET", "/hostedzone/"+route53ZoneID(zoneID)+"/rrset", query, nil, &result); err != nil {
+			return nil, err
+		}
+
+		// 按名称查询时，API从该名称开始按顺序返回，遇到其他名称即可结束
+		for _, set := range result.ResourceRecordSets {
+			set.Name = route53UnescapeName(set.Name)
+			if name != "" && !strings.EqualFold(set.Name, name) {
+				return sets, nil
+			}
+			sets = append(sets, set)
+		}
+
+		if !result.IsTruncated {
+			break
+		}
+		nextName, nextType, nextIdentifier = result.NextRecordName, result.NextRecordType, result.NextRecordIdentifier
+	}
+	return sets, nil
+}
+
+// ChangeResourceRecordSets 提交变更批次
+func (c *Route53Client) ChangeResourceRecordSets(ctx context.Context, zoneID, comment string, changes []Route53Change) (*Route53ChangeInfo, error) {
+	type changeBatch struct {
+		Comment string          `xml:"Comment,omitempty"`
+		Changes []Route53Change `xml:"Changes>Change"`
+	}
+	body := struct {
+		XMLName     xml.Name    `xml:"ChangeResourceRecordSetsRequest"`
+		Xmlns       string      `xml:"xmlns,attr"`
+		ChangeBatch changeBatch `xml:"ChangeBatch"`
+	}{
+		Xmlns:       route53Namespace,
+		ChangeBatch: changeBatch{Comment: comment, Changes: changes},
+	}
+
+	var result struct {
+		ChangeInfo Route53ChangeInfo `xml:"ChangeInfo"`
+	}
+	if err := c.makeRequest(ctx, "POST", "/hostedzone/"+route53ZoneID(zoneID)+"/rrset/", nil, body, &result); err != nil {
+		return nil, err
+	}
+	return &result.ChangeInfo, nil
+}
+
+// GetChange 查询变更状态
+func (c *Route53Client) GetChange(ctx context.Context, changeID string) (*Route53ChangeInfo, error) {
+	changeID = strings.TrimPrefix(changeID, "/change/")
+
+	var result struct {
+		ChangeInfo Route53ChangeInfo `xml:"ChangeInfo"`
+	}
+	if err := c.makeRequest(ctx, "GET", "/change/"+changeID, nil, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result.ChangeInfo, nil
+}
+
+// WaitForChange 轮询变更状态直到INSYNC或超时
+func (c *Route53Client) WaitForChange(ctx context.Context, changeID string, timeout, interval time.Duration) (*Route53ChangeInfo, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		info, err := c.GetChange(ctx, changeID)
+		if err != nil {
+			return nil, err
+		}
+		if info.Status == "INSYNC" {
+			return info, nil
+		}
+		if time.Now().After(deadline) {
+			return info, fmt.Errorf("等待Route 53变更 %s 同步超时", changeID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return info, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// route53ZoneID 去掉托管区域ID的/hostedzone/前缀
+func route53ZoneID(id string) string {
+	return strings.TrimPrefix(id, "/hostedzone/")
+}
+
+// route53UnescapeName Route 53返回的名称中特殊字符为八进制转义，如\052表示*
+func route53UnescapeName(name string) string {
+	return strings.Replace(name, `\052`, "*", -1)
+}
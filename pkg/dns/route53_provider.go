@@ -0,0 +1,435 @@
+package dns
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func init() {
+	Register(ProviderRoute53, func(cfg Config) (Provider, error) {
+		accessKeyId := cfg.Get("ACCESS_KEY_ID")
+		secretAccessKey := cfg.Get("SECRET_ACCESS_KEY")
+		if accessKeyId == "" || secretAccessKey == "" {
+			return nil, fmt.Errorf("AWS AccessKey未配置")
+		}
+
+		client := NewRoute53Client(accessKeyId, secretAccessKey, cfg.Get("SESSION_TOKEN"), cfg.Get("BASE_URL"))
+		client.HTTP.Configure(cfg)
+		provider := NewRoute53Provider(client)
+		provider.WaitForSync, _ = strconv.ParseBool(cfg.Get("WAIT_FOR_SYNC"))
+		if timeout, err := strconv.Atoi(cfg.Get("WAIT_TIMEOUT")); err == nil && timeout > 0 {
+			provider.WaitTimeout = time.Duration(timeout) * time.Second
+		}
+		return provider, nil
+	})
+}
+
+// Route53Provider 将Route53Client适配为Provider
+//
+// Route 53以记录集(名称+类型+SetIdentifier)为单位管理，一个记录集可包含多个值，
+// 这里每个值对应一条统一记录，记录ID由记录集和值编码而成。
+// 延迟路由的区域对应Line，加权路由的权重对应Weight，记录集的SetIdentifier对应Record.SetIdentifier。
+// Route 53记录没有备注，Remark不参与记录集的计算。
+type Route53Provider struct {
+	client *Route53Client
+
+	WaitForSync bool          // 变更后是否等待状态变为INSYNC
+	WaitTimeout time.Duration // 等待同步的超时时间
+}
+
+// NewRoute53Provider 创建Route 53服务商
+func NewRoute53Provider(client *Route53Client) *Route53Provider {
+	return &Route53Provider{
+		client:      client,
+		WaitTimeout: 60 * time.Second,
+	}
+}
+
+// route53RecordKey 统一记录ID对应的记录集和值
+type route53RecordKey struct {
+	Name          string
+	Type          string
+	SetIdentifier string
+	Value         string
+}
+
+// encode 编码为可用于URL的记录ID
+func (k route53RecordKey) encode() string {
+	raw := strings.Join([]string{k.Name, k.Type, k.SetIdentifier, k.Value}, "\x00")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// sameSet 是否属于同一个记录集
+func (k route53RecordKey) sameSet(other route53RecordKey) bool {
+	return strings.EqualFold(k.Name, other.Name) && k.Type == other.Type && k.SetIdentifier == other.SetIdentifier
+}
+
+// decodeRoute53RecordID 解析记录ID
+func decodeRoute53RecordID(id string) (route53RecordKey, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil {
+		return route53RecordKey{}, errorf(ErrInvalidParam, "无效的Route 53记录ID: %s", id)
+	}
+	parts := strings.Split(string(raw), "\x00")
+	if len(parts) != 4 {
+		return route53RecordKey{}, errorf(ErrInvalidParam, "无效的Route 53记录ID: %s", id)
+	}
+	return route53RecordKey{Name: parts[0], Type: parts[1], SetIdentifier: parts[2], Value: parts[3]}, nil
+}
+
+// zone 根据域名或托管区域ID查找托管区域
+func (p *Route53Provider) zone(ctx context.Context, domain string) (*Route53HostedZone, error) {
+	var zone *Route53HostedZone
+	var err error
+	if strings.Contains(domain, ".") {
+		zone, err = p.client.GetHostedZoneByName(ctx, domain)
+	} else {
+		zone, err = p.client.GetHostedZone(ctx, domain)
+	}
+	if err != nil {
+		return nil, err
+	}
+	zone.Id = route53ZoneID(zone.Id)
+	zone.Name = strings.TrimSuffix(zone.Name, ".")
+	return zone, nil
+}
+
+// findSet 查找记录集，不存在时返回nil
+func (p *Route53Provider) findSet(ctx context.Context, zoneID string, key route53RecordKey) (*Route53ResourceRecordSet, error) {
+	sets, err := p.client.ListResourceRecordSets(ctx, zoneID, key.Name)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sets {
+		if sets[i].Type == key.Type && sets[i].SetIdentifier == key.SetIdentifier {
+			return &sets[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// commit 提交变更，按配置等待同步
+func (p *Route53Provider) commit(ctx context.Context, zoneID string, changes []Route53Change) error {
+	info, err := p.client.ChangeResourceRecordSets(ctx, zoneID, "", changes)
+	if err != nil {
+		return err
+	}
+	if p.WaitForSync {
+		_, err = p.client.WaitForChange(ctx, info.Id, p.WaitTimeout, 2*time.Second)
+	}
+	return err
+}
+
+// GetDomainList 获取域名列表
+func (p *Route53Provider) GetDomainList(ctx context.Context) ([]Domain, error) {
+	zones, err := p.client.ListHostedZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Domain, 0, len(zones))
+	for _, z := range zones {
+		grade := "public"
+		if z.Config.PrivateZone {
+			grade = "private"
+		}
+		result = append(result, Domain{
+			ID:          route53ZoneID(z.Id),
+			Name:        strings.TrimSuffix(z.Name, "."),
+			Grade:       grade,
+			RecordCount: z.ResourceRecordSetCount,
+			Remark:      z.Config.Comment,
+		})
+	}
+	return result, nil
+}
+
+// GetRecordList 获取记录列表
+func (p *Route53Provider) GetRecordList(ctx context.Context, domain, subDomain string) ([]Record, error) {
+	zone, err := p.zone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	name := ""
+	if subDomain != "" {
+		name = toFQDN(subDomain, zone.Name)
+	}
+	sets, err := p.client.ListResourceRecordSets(ctx, zone.Id, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Record
+	for _, set := range sets {
+		result = append(result, fromRoute53RecordSet(zone.Name, set)...)
+	}
+	return result, nil
+}
+
+// CreateRecord 创建记录，记录集已存在时追加记录值
+func (p *Route53Provider) CreateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	zone, err := p.zone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	key := route53Key(zone.Name, record)
+	existing, err := p.findSet(ctx, zone.Id, key)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := route53AddValue(existing, key, record)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.commit(ctx, zone.Id, []Route53Change{{Action: "UPSERT", ResourceRecordSet: *set}}); err != nil {
+		return nil, err
+	}
+
+	return route53Result(zone.Name, key, record), nil
+}
+
+// UpdateRecord 更新记录，记录集变化时在同一批次中移出旧值并写入新值
+func (p *Route53Provider) UpdateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	oldKey, err := decodeRoute53RecordID(record.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	zone, err := p.zone(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	oldSet, err := p.findSet(ctx, zone.Id, oldKey)
+	if err != nil {
+		return nil, err
+	}
+	if oldSet == nil {
+		return nil, errorf(ErrNotFound, "Route 53记录 %s 不存在", record.ID)
+	}
+
+	// 没有指定SetIdentifier的加权/延迟路由记录沿用原记录集的SetIdentifier
+	if record.SetIdentifier == "" && (record.Weight > 0 || route53Region(record.Line) != "") {
+		record.SetIdentifier = oldKey.SetIdentifier
+	}
+	key := route53Key(zone.Name, record)
+	var changes []Route53Change
+	if key.sameSet(oldKey) {
+		set, err := route53AddValue(route53RemoveValue(oldSet, oldKey.Value), key, record)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, Route53Change{Action: "UPSERT", ResourceRecordSet: *set})
+	} else {
+		changes = append(changes, route53RemoveChange(oldSet, oldKey.Value))
+
+		existing, err := p.findSet(ctx, zone.Id, key)
+		if err != nil {
+			return nil, err
+		}
+		set, err := route53AddValue(existing, key, record)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, Route53Change{Action: "UPSERT", ResourceRecordSet: *set})
+	}
+
+	if err := p.commit(ctx, zone.Id, changes); err != nil {
+		return nil, err
+	}
+
+	return route53Result(zone.Name, key, record), nil
+}
+
+// DeleteRecord 删除记录，记录集只剩这一个值时删除整个记录集
+func (p *Route53Provider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	key, err := decodeRoute53RecordID(recordID)
+	if err != nil {
+		return err
+	}
+
+	zone, err := p.zone(ctx, domain)
+	if err != nil {
+		return err
+	}
+
+	set, err := p.findSet(ctx, zone.Id, key)
+	if err != nil {
+		return err
+	}
+	if set == nil {
+		return errorf(ErrNotFound, "Route 53记录 %s 不存在", recordID)
+	}
+
+	return p.commit(ctx, zone.Id, []Route53Change{route53RemoveChange(set, key.Value)})
+}
+
+// SetRecordStatus Route 53记录没有启用/暂停状态
+func (p *Route53Provider) SetRecordStatus(ctx context.Context, domain, recordID, status string) error {
+	return errorf(ErrUnsupported, "Route 53不支持设置记录状态")
+}
+
+// route53Key 根据统一记录计算记录集和值
+func route53Key(zone string, record Record) route53RecordKey {
+	key := route53RecordKey{
+		Name:  toFQDN(record.Name, zone) + ".",
+		Type:  record.Type,
+		Value: route53Value(record),
+	}
+
+	key.SetIdentifier = record.SetIdentifier
+	if key.SetIdentifier == "" {
+		key.SetIdentifier = route53Region(record.Line)
+	}
+	return key
+}
+
+// route53Value 统一记录值转换为Route 53记录值
+func route53Value(record Record) string {
+	switch record.Type {
+	case "TXT", "SPF":
+		return quoteTXT(record.Value)
+	case "MX":
+		return fmt.Sprintf("%d %s", record.Priority, record.Value)
+	}
+	return record.Value
+}
+
+// route53Region 线路为空或默认时不使用延迟路由
+func route53Region(line string) string {
+	if line == "" || line == DefaultLineName || line == "default" {
+		return ""
+	}
+	return line
+}
+
+// route53AddValue 将记录值加入记录集，记录集不存在时新建
+func route53AddValue(set *Route53ResourceRecordSet, key route53RecordKey, record Record) (*Route53ResourceRecordSet, error) {
+	if set != nil && set.AliasTarget != nil {
+		return nil, errorf(ErrInvalidParam, "Route 53别名记录 %s 不支持修改", key.Name)
+	}
+	region := route53Region(record.Line)
+	// 指定了权重，或记录集已是加权路由(权重可以为0)时使用加权路由
+	weighted := region == "" && (record.Weight > 0 || set != nil && set.Weight != nil)
+	if weighted && key.SetIdentifier == "" {
+		return nil, errorf(ErrInvalidParam, "Route 53加权路由记录需要通过set_identifier指定SetIdentifier")
+	}
+	if !weighted && region == "" && key.SetIdentifier != "" {
+		return nil, errorf(ErrInvalidParam, "Route 53的SetIdentifier只用于加权或延迟路由，需要同时指定weight或line")
+	}
+
+	ttl := record.TTL
+	if ttl <= 0 {
+		ttl = 300
+	}
+
+	result := Route53ResourceRecordSet{
+		Name:          key.Name,
+		Type:          key.Type,
+		SetIdentifier: key.SetIdentifier,
+		Region:        region,
+		TTL:           &ttl,
+	}
+	if weighted {
+		weight := record.Weight
+		result.Weight = &weight
+	}
+
+	if set != nil {
+		for _, rr := range set.ResourceRecords {
+			if rr.Value != key.Value {
+				result.ResourceRecords = append(result.ResourceRecords, rr)
+			}
+		}
+	}
+	result.ResourceRecords = append(result.ResourceRecords, Route53ResourceRecord{Value: key.Value})
+	return &result, nil
+}
+
+// route53RemoveValue 返回去掉指定值后的记录集副本
+func route53RemoveValue(set *Route53ResourceRecordSet, value string) *Route53ResourceRecordSet {
+	result := *set
+	result.ResourceRecords = nil
+	for _, rr := range set.ResourceRecords {
+		if rr.Value != value {
+			result.ResourceRecords = append(result.ResourceRecords, rr)
+		}
+	}
+	return &result
+}
+
+// route53RemoveChange 移出记录值的变更，没有剩余值时删除记录集
+func route53RemoveChange(set *Route53ResourceRecordSet, value string) Route53Change {
+	remaining := route53RemoveValue(set, value)
+	if len(remaining.ResourceRecords) == 0 {
+		return Route53Change{Action: "DELETE", ResourceRecordSet: *set}
+	}
+	return Route53Change{Action: "UPSERT", ResourceRecordSet: *remaining}
+}
+
+// route53Result 构建写入后的统一记录
+func route53Result(zone string, key route53RecordKey, record Record) *Record {
+	record.ID = key.encode()
+	record.Domain = zone
+	record.Name = toRelativeName(key.Name, zone)
+	record.SetIdentifier = key.SetIdentifier
+	record.Remark = ""
+	record.Status = RecordStatusEnable
+	return &record
+}
+
+// fromRoute53RecordSet 将记录集展开为统一记录
+func fromRoute53RecordSet(zone string, set Route53ResourceRecordSet) []Record {
+	base := Record{
+		Domain: zone,
+		Name:   toRelativeName(set.Name, zone),
+		Type:   set.Type,
+		Line:   set.Region,
+		Status: RecordStatusEnable,
+
+		SetIdentifier: set.SetIdentifier,
+	}
+	if set.TTL != nil {
+		base.TTL = *set.TTL
+	}
+	if set.Weight != nil {
+		base.Weight = *set.Weight
+	}
+
+	key := route53RecordKey{Name: set.Name, Type: set.Type, SetIdentifier: set.SetIdentifier}
+
+	if set.AliasTarget != nil {
+		record := base
+		key.Value = set.AliasTarget.DNSName
+		record.ID = key.encode()
+		record.Value = strings.TrimSuffix(set.AliasTarget.DNSName, ".")
+		return []Record{record}
+	}
+
+	records := make([]Record, 0, len(set.ResourceRecords))
+	for _, rr := range set.ResourceRecords {
+		record := base
+		key.Value = rr.Value
+		record.ID = key.encode()
+		record.Value = rr.Value
+
+		switch set.Type {
+		case "TXT", "SPF":
+			record.Value = unquoteTXT(rr.Value)
+		case "MX":
+			if parts := strings.SplitN(rr.Value, " ", 2); len(parts) == 2 {
+				record.Priority, _ = strconv.ParseInt(parts[0], 10, 64)
+				record.Value = parts[1]
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
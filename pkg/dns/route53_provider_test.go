@@ -0,0 +1,74 @@
+package dns
+
+import "testing"
+
+func TestRoute53AddValueRouting(t *testing.T) {
+	tests := []struct {
+		name       string
+		set        *Route53ResourceRecordSet
+		record     Record
+		wantSetID  string
+		wantWeight bool
+		wantRegion string
+		wantErr    bool
+	}{
+		{name: "备注不影响路由", record: Record{Name: "www", Type: "A", Value: "1.2.3.4", Remark: "web"}},
+		{name: "加权路由", record: Record{Name: "www", Type: "A", Value: "1.2.3.4", Weight: 10, SetIdentifier: "a"}, wantSetID: "a", wantWeight: true},
+		{name: "加权路由缺少SetIdentifier", record: Record{Name: "www", Type: "A", Value: "1.2.3.4", Weight: 10, Remark: "a"}, wantErr: true},
+		{name: "延迟路由默认使用区域", record: Record{Name: "www", Type: "A", Value: "1.2.3.4", Line: "ap-northeast-1"}, wantSetID: "ap-northeast-1", wantRegion: "ap-northeast-1"},
+		{name: "默认线路", record: Record{Name: "www", Type: "A", Value: "1.2.3.4", Line: DefaultLineName}},
+		{name: "只有SetIdentifier", record: Record{Name: "www", Type: "A", Value: "1.2.3.4", SetIdentifier: "a"}, wantErr: true},
+		{
+			name:       "已有权重为0的加权记录集",
+			set:        &Route53ResourceRecordSet{Name: "www.example.com.", Type: "A", SetIdentifier: "a", Weight: new(int64)},
+			record:     Record{Name: "www", Type: "A", Value: "1.2.3.4", SetIdentifier: "a"},
+			wantSetID:  "a",
+			wantWeight: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := route53Key("example.com", tt.record)
+			set, err := route53AddValue(tt.set, key, tt.record)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("期望返回错误，实际为 %+v", set)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if set.SetIdentifier != tt.wantSetID {
+				t.Errorf("SetIdentifier = %q, 期望 %q", set.SetIdentifier, tt.wantSetID)
+			}
+			if (set.Weight != nil) != tt.wantWeight {
+				t.Errorf("Weight = %v, 期望加权路由 %v", set.Weight, tt.wantWeight)
+			}
+			if set.Region != tt.wantRegion {
+				t.Errorf("Region = %q, 期望 %q", set.Region, tt.wantRegion)
+			}
+		})
+	}
+}
+
+func TestRoute53RecordIDRoundTrip(t *testing.T) {
+	record := Record{Name: "www", Type: "A", Value: "1.2.3.4", Remark: "web"}
+	key := route53Key("example.com", record)
+	result := route53Result("example.com", key, record)
+	if result.Remark != "" {
+		t.Errorf("Route 53记录没有备注，Remark = %q", result.Remark)
+	}
+
+	decoded, err := decodeRoute53RecordID(result.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded != key {
+		t.Errorf("解析记录ID = %+v, 期望 %+v", decoded, key)
+	}
+	if decoded.SetIdentifier != "" {
+		t.Errorf("备注不应进入记录ID，SetIdentifier = %q", decoded.SetIdentifier)
+	}
+}
@@ -0,0 +1,390 @@
+package dns
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TencentCloudEndpoint 腾讯云DNSPod API 3.0地址
+const TencentCloudEndpoint = "https://dnspod.tencentcloudapi.com"
+
+// TencentCloudClient 腾讯云DNSPod API 3.0客户端，使用TC3-HMAC-SHA256签名
+type TencentCloudClient struct {
+	SecretId  string
+	SecretKey string
+	Region    string
+	Endpoint  string
+	HTTP      *HTTPClient
+}
+
+// TencentCloudDomain 域名信息
+type TencentCloudDomain struct {
+	DomainId     uint64   `json:"DomainId"`
+	Name         string   `json:"Name"`
+	Status       string   `json:"Status"` // ENABLE, PAUSE, SPAM
+	TTL          uint64   `json:"TTL"`
+	DNSStatus    string   `json:"DNSStatus"`
+	Grade        string   `json:"Grade"`
+	GradeTitle   string   `json:"GradeTitle"`
+	GroupId      uint64   `json:"GroupId"`
+	Remark       string   `json:"Remark"`
+	Punycode     string   `json:"Punycode"`
+	EffectiveDNS []string `json:"EffectiveDNS"`
+	RecordCount  uint64   `json:"RecordCount"`
+	Owner        string   `json:"Owner"`
+	CreatedOn    string   `json:"CreatedOn"`
+	UpdatedOn    string   `json:"UpdatedOn"`
+}
+
+// TencentCloudRecord 解析记录
+type TencentCloudRecord struct {
+	RecordId  uint64  `json:"RecordId"`
+	Name      string  `json:"Name"`
+	Type      string  `json:"Type"`
+	Value     string  `json:"Value"`
+	Status    string  `json:"Status"` // ENABLE, DISABLE
+	Line      string  `json:"Line"`
+	LineId    string  `json:"LineId"`
+	TTL       uint64  `json:"TTL"`
+	MX        uint64  `json:"MX"`
+	Weight    *uint64 `json:"Weight"`
+	Remark    string  `json:"Remark"`
+	UpdatedOn string  `json:"UpdatedOn"`
+}
+
+// TencentCloudError 腾讯云API错误
+type TencentCloudError struct {
+	Code    string `json:"Code"`
+	Message string `json:"Message"`
+}
+
+// NewTencentCloudClient 创建腾讯云DNSPod客户端，endpoint为空时使用官方地址
+func NewTencentCloudClient(secretId, secretKey, endpoint string) *TencentCloudClient {
+	if endpoint == "" {
+		endpoint = TencentCloudEndpoint
+	}
+	return &TencentCloudClient{
+		SecretId:  secretId,
+		SecretKey: secretKey,
+		Endpoint:  strings.TrimRight(endpoint, "/"),
+		HTTP:      NewHTTPClient(tencentCloudThrottled),
+	}
+}
+
+// sign 计算TC3-HMAC-SHA256签名，返回Authorization头
+func (c *TencentCloudClient) sign(host string, payload []byte, timestamp int64) string {
+	headers := map[string]string{
+		"content-type": "application/json; charset=utf-8",
+		"host":         host,
+	}
+	return signTC3(c.SecretId, c.SecretKey, "dnspod", headers, payload, timestamp)
+}
+
+// signTC3 按TC3-HMAC-SHA256对POST请求签名，service为产品名，headers为参与签名的请求头，返回Authorization头
+func signTC3(secretId, secretKey, service string, headers map[string]string, payload []byte, timestamp int64) string {
+	date := time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+	stringToSign, signedHeaders := tc3StringToSign(service, headers, payload, timestamp)
+
+	// 逐级派生签名密钥
+	key := hmacSHA256([]byte("TC3"+secretKey), date)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "tc3_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	return fmt.Sprintf("TC3-HMAC-SHA256 Credential=%s/%s/%s/tc3_request, SignedHeaders=%s, Signature=%s",
+		secretId, date, service, signedHeaders, signature)
+}
+
+// tc3StringToSign 构建规范请求串和待签名字符串，请求头的名称和值都转为小写，返回待签名字符串和SignedHeaders
+func tc3StringToSign(service string, headers map[string]string, payload []byte, timestamp int64) (string, string) {
+	date := time.Unix(timestamp, 0).UTC().Format("2006-01-02")
+
+	names := make([]string, 0, len(headers))
+	values := make(map[string]string, len(headers))
+	for k, v := range headers {
+		name := strings.ToLower(k)
+		names = append(names, name)
+		values[name] = strings.ToLower(strings.TrimSpace(v))
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders string
+	for _, name := range names {
+		canonicalHeaders += name + ":" + values[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	// 构建规范请求串
+	payloadHash := sha256.Sum256(payload)
+	canonicalRequest := strings.Join([]string{
+		"POST",
+		"/",
+		"",
+		canonicalHeaders,
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	// 构建待签名字符串
+	scope := date + "/" + service + "/tc3_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	return "TC3-HMAC-SHA256\n" + strconv.FormatInt(timestamp, 10) + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:]), signedHeaders
+}
+
+// makeRequest 发起API请求，result为Response字段的解析目标
+func (c *TencentCloudClient) makeRequest(ctx context.Context, action string, params map[string]interface{}, result interface{}) error {
+	payload, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	// Create开头的操作不是幂等的；每次尝试使用新的时间戳重新签名
+	_, body, err := c.HTTP.Do(ctx, !strings.HasPrefix(action, "Create"), func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, "POST", c.Endpoint+"/", bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+
+		timestamp := time.Now().Unix()
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+		req.Header.Set("X-TC-Action", action)
+		req.Header.Set("X-TC-Version", "2021-03-23")
+		req.Header.Set("X-TC-Timestamp", strconv.FormatInt(timestamp, 10))
+		if c.Region != "" {
+			req.Header.Set("X-TC-Region", c.Region)
+		}
+		req.Header.Set("Authorization", c.sign(req.URL.Host, payload, timestamp))
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var response struct {
+		Response json.RawMessage `json:"Response"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(body))
+	}
+
+	var common struct {
+		Error     *TencentCloudError `json:"Error"`
+		RequestId string             `json:"RequestId"`
+	}
+	if err := json.Unmarshal(response.Response, &common); err != nil {
+		return fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(body))
+	}
+	if common.Error != nil {
+		return &APIError{
+			Kind:      tencentCloudErrorKind(common.Error.Code),
+			Code:      common.Error.Code,
+			Message:   common.Error.Message,
+			RequestID: common.RequestId,
+		}
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(response.Response, result); err != nil {
+			return fmt.Errorf("解析API响应失败: %v, 响应内容: %s", err, string(body))
+		}
+	}
+	return nil
+}
+
+// tencentCloudThrottled 腾讯云限流时返回RequestLimitExceeded开头的错误码
+func tencentCloudThrottled(statusCode int, body []byte) bool {
+	var response struct {
+		Response struct {
+			Error *TencentCloudError `json:"Error"`
+		} `json:"Response"`
+	}
+	if json.Unmarshal(body, &response) != nil || response.Response.Error == nil {
+		return false
+	}
+	return tencentCloudErrorKind(response.Response.Error.Code) == ErrThrottled
+}
+
+// tencentCloudErrorKind 腾讯云错误码对应的错误类别，错误码为 类别.具体原因 的形式
+func tencentCloudErrorKind(code string) error {
+	switch {
+	case strings.HasPrefix(code, "RequestLimitExceeded"):
+		return ErrThrottled
+	case strings.HasPrefix(code, "AuthFailure"), strings.HasPrefix(code, "UnauthorizedOperation"):
+		return ErrAuthFailed
+	case strings.HasPrefix(code, "ResourceNotFound"), strings.Contains(code, "NotExist"):
+		return ErrNotFound // 如 InvalidParameterValue.DomainNotExists
+	case strings.Contains(code, "Exist"):
+		return ErrAlreadyExists // 如 InvalidParameter.DomainRecordExist
+	case strings.Contains(code, "Conflict"):
+		return ErrConflict
+	case strings.HasPrefix(code, "LimitExceeded"):
+		return ErrQuotaExceeded
+	case strings.HasPrefix(code, "InvalidParameter"), strings.HasPrefix(code, "MissingParameter"),
+		strings.HasPrefix(code, "UnknownParameter"):
+		return ErrInvalidParam
+	}
+	return nil
+}
+
+// setTencentCloudDomain 域名参数为纯数字时同时按DomainId传递，DomainId优先
+func setTencentCloudDomain(params map[string]interface{}, domain string) {
+	params["Domain"] = domain
+	if id, err := strconv.ParseUint(domain, 10, 64); err == nil {
+		params["DomainId"] = id
+	}
+}
+
+// GetDomainList 获取域名列表，按页读取直到结束
+func (c *TencentCloudClient) GetDomainList(ctx context.Context, keyword string) ([]TencentCloudDomain, error) {
+	var domains []TencentCloudDomain
+	for offset := 0; ; {
+		params := map[string]interface{}{
+			"Offset": offset,
+			"Limit":  3000,
+		}
+		if keyword != "" {
+			params["Keyword"] = keyword
+		}
+
+		var result struct {
+			DomainCountInfo struct {
+				AllTotal uint64 `json:"AllTotal"`
+			} `json:"DomainCountInfo"`
+			DomainList []TencentCloudDomain `json:"DomainList"`
+		}
+		if err := c.makeRequest(ctx, "DescribeDomainList", params, &result); err != nil {
+			return nil, err
+		}
+
+		domains = append(domains, result.DomainList...)
+		offset += len(result.DomainList)
+		if len(result.DomainList) == 0 || uint64(offset) >= result.DomainCountInfo.AllTotal {
+			break
+		}
+	}
+	return domains, nil
+}
+
+// GetRecordList 获取解析记录列表，按页读取直到结束
+func (c *TencentCloudClient) GetRecordList(ctx context.Context, domain, subDomain string) ([]TencentCloudRecord, error) {
+	var records []TencentCloudRecord
+	for offset := 0; ; {
+		params := map[string]interface{}{
+			"Offset": offset,
+			"Limit":  3000,
+		}
+		setTencentCloudDomain(params, domain)
+		if subDomain != "" {
+			params["Subdomain"] = subDomain
+		}
+
+		var result struct {
+			RecordCountInfo struct {
+				TotalCount uint64 `json:"TotalCount"`
+			} `json:"RecordCountInfo"`
+			RecordList []TencentCloudRecord `json:"RecordList"`
+		}
+		err := c.makeRequest(ctx, "DescribeRecordList", params, &result)
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.Code == "ResourceNotFound.NoDataOfRecord" {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		records = append(records, result.RecordList...)
+		offset += len(result.RecordList)
+		if len(result.RecordList) == 0 || uint64(offset) >= result.RecordCountInfo.TotalCount {
+			break
+		}
+	}
+	return records, nil
+}
+
+// recordParams 构建创建/修改记录的公共参数
+func (c *TencentCloudClient) recordParams(domain string, record TencentCloudRecord) map[string]interface{} {
+	params := map[string]interface{}{
+		"SubDomain":  record.Name,
+		"RecordType": record.Type,
+		"RecordLine": record.Line,
+		"Value":      record.Value,
+	}
+	setTencentCloudDomain(params, domain)
+	if record.LineId != "" {
+		params["RecordLineId"] = record.LineId
+	}
+	if record.MX > 0 {
+		params["MX"] = record.MX
+	}
+	if record.TTL > 0 {
+		params["TTL"] = record.TTL
+	}
+	if record.Weight != nil {
+		params["Weight"] = *record.Weight
+	}
+	if record.Status != "" {
+		params["Status"] = record.Status
+	}
+	if record.Remark != "" {
+		params["Remark"] = record.Remark
+	}
+	return params
+}
+
+// CreateRecord 创建解析记录，返回记录ID
+func (c *TencentCloudClient) CreateRecord(ctx context.Context, domain string, record TencentCloudRecord) (uint64, error) {
+	var result struct {
+		RecordId uint64 `json:"RecordId"`
+	}
+	if err := c.makeRequest(ctx, "CreateRecord", c.recordParams(domain, record), &result); err != nil {
+		return 0, err
+	}
+	return result.RecordId, nil
+}
+
+// UpdateRecord 修改解析记录
+func (c *TencentCloudClient) UpdateRecord(ctx context.Context, domain string, record TencentCloudRecord) error {
+	params := c.recordParams(domain, record)
+	params["RecordId"] = record.RecordId
+	return c.makeRequest(ctx, "ModifyRecord", params, nil)
+}
+
+// DeleteRecord 删除解析记录
+func (c *TencentCloudClient) DeleteRecord(ctx context.Context, domain string, recordId uint64) error {
+	params := map[string]interface{}{
+		"RecordId": recordId,
+	}
+	setTencentCloudDomain(params, domain)
+	return c.makeRequest(ctx, "DeleteRecord", params, nil)
+}
+
+// SetRecordStatus 设置解析记录状态，status为ENABLE或DISABLE
+func (c *TencentCloudClient) SetRecordStatus(ctx context.Context, domain string, recordId uint64, status string) error {
+	params := map[string]interface{}{
+		"RecordId": recordId,
+		"Status":   status,
+	}
+	setTencentCloudDomain(params, domain)
+	return c.makeRequest(ctx, "ModifyRecordStatus", params, nil)
+}
+
+// SetRecordRemark 设置解析记录备注
+func (c *TencentCloudClient) SetRecordRemark(ctx context.Context, domain string, recordId uint64, remark string) error {
+	params := map[string]interface{}{
+		"RecordId": recordId,
+		"Remark":   remark,
+	}
+	setTencentCloudDomain(params, domain)
+	return c.makeRequest(ctx, "ModifyRecordRemark", params, nil)
+}
@@ -0,0 +1,42 @@
+package dns
+
+import "testing"
+
+// 腾讯云API文档“签名方法 v3”中的示例：查询广州地域的云服务器实例，请求体与文档一样使用\u转义的中文
+var tc3Example = struct {
+	headers   map[string]string
+	payload   string
+	timestamp int64
+}{
+	headers: map[string]string{
+		"Content-Type": "application/json; charset=utf-8",
+		"Host":         "cvm.tencentcloudapi.com",
+		"X-TC-Action":  "DescribeInstances",
+	},
+	payload:   `{"Limit": 1, "Filters": [{"Values": ["\u672a\u547d\u540d"], "Name": "instance-name"}]}`,
+	timestamp: 1551113065,
+}
+
+func TestTC3StringToSignExample(t *testing.T) {
+	got, signedHeaders := tc3StringToSign("cvm", tc3Example.headers, []byte(tc3Example.payload), tc3Example.timestamp)
+
+	want := "TC3-HMAC-SHA256\n1551113065\n2019-02-25/cvm/tc3_request\n7019a55be8395899b900fb5564e4200d984910f34794a27cb3fb7d10ff6a1e84"
+	if got != want {
+		t.Errorf("待签名字符串 =\n%s\n期望\n%s", got, want)
+	}
+	if signedHeaders != "content-type;host;x-tc-action" {
+		t.Errorf("SignedHeaders = %s", signedHeaders)
+	}
+}
+
+func TestSignTC3(t *testing.T) {
+	// 文档中的SecretKey已打码，签名按文档的密钥派生步骤使用示例格式的密钥计算
+	got := signTC3("AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE", "Gu5t9xGARNpq86cd98joQYCN3EXAMPLE", "cvm",
+		tc3Example.headers, []byte(tc3Example.payload), tc3Example.timestamp)
+
+	want := "TC3-HMAC-SHA256 Credential=AKIDz8krbsJ5yKBZQpn74WFkmLPx3EXAMPLE/2019-02-25/cvm/tc3_request, " +
+		"SignedHeaders=content-type;host;x-tc-action, Signature=644be983de9a8a3f00db8eadaba61467c3b429e2215758ba897b738ca469fd26"
+	if got != want {
+		t.Errorf("Authorization =\n%s\n期望\n%s", got, want)
+	}
+}
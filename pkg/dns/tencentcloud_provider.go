@@ -0,0 +1,166 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(ProviderTencentCloud, func(cfg Config) (Provider, error) {
+		secretId := cfg.Get("SECRET_ID")
+		secretKey := cfg.Get("SECRET_KEY")
+		if secretId == "" || secretKey == "" {
+			return nil, fmt.Errorf("腾讯云SecretId或SecretKey未配置")
+		}
+		client := NewTencentCloudClient(secretId, secretKey, cfg.Get("ENDPOINT"))
+		client.Region = cfg.Get("REGION")
+		client.HTTP.Configure(cfg)
+		return NewTencentCloudProvider(client), nil
+	})
+}
+
+// TencentCloudProvider 将TencentCloudClient适配为Provider
+type TencentCloudProvider struct {
+	client *TencentCloudClient
+}
+
+// NewTencentCloudProvider 创建腾讯云DNSPod服务商
+func NewTencentCloudProvider(client *TencentCloudClient) *TencentCloudProvider {
+	return &TencentCloudProvider{client: client}
+}
+
+// GetDomainList 获取域名列表
+func (p *TencentCloudProvider) GetDomainList(ctx context.Context) ([]Domain, error) {
+	domains, err := p.client.GetDomainList(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Domain, 0, len(domains))
+	for _, d := range domains {
+		result = append(result, Domain{
+			ID:          strconv.FormatUint(d.DomainId, 10),
+			Name:        d.Name,
+			PunyCode:    d.Punycode,
+			Status:      strings.ToLower(d.Status),
+			Grade:       d.Grade,
+			RecordCount: int(d.RecordCount),
+			Remark:      d.Remark,
+		})
+	}
+	return result, nil
+}
+
+// GetRecordList 获取记录列表
+func (p *TencentCloudProvider) GetRecordList(ctx context.Context, domain, subDomain string) ([]Record, error) {
+	records, err := p.client.GetRecordList(ctx, domain, subDomain)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]Record, 0, len(records))
+	for _, r := range records {
+		result = append(result, fromTencentCloudRecord(domain, r))
+	}
+	return result, nil
+}
+
+// CreateRecord 创建记录
+func (p *TencentCloudProvider) CreateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	id, err := p.client.CreateRecord(ctx, domain, toTencentCloudRecord(record))
+	if err != nil {
+		return nil, err
+	}
+
+	record.ID = strconv.FormatUint(id, 10)
+	record.Domain = domain
+	if record.Status == "" {
+		record.Status = RecordStatusEnable
+	}
+	return &record, nil
+}
+
+// UpdateRecord 更新记录，备注为空时不修改，指定了RemarkSet时清除备注
+func (p *TencentCloudProvider) UpdateRecord(ctx context.Context, domain string, record Record) (*Record, error) {
+	r := toTencentCloudRecord(record)
+	id, err := strconv.ParseUint(record.ID, 10, 64)
+	if err != nil {
+		return nil, errorf(ErrInvalidParam, "无效的记录ID: %s", record.ID)
+	}
+	r.RecordId = id
+
+	if err := p.client.UpdateRecord(ctx, domain, r); err != nil {
+		return nil, err
+	}
+
+	record.Domain = domain
+	if record.Remark == "" && record.RemarkSet {
+		if err := p.client.SetRecordRemark(ctx, domain, id, ""); err != nil {
+			return &record, fmt.Errorf("记录已修改，清除备注失败: %w", err)
+		}
+	}
+	return &record, nil
+}
+
+// DeleteRecord 删除记录
+func (p *TencentCloudProvider) DeleteRecord(ctx context.Context, domain, recordID string) error {
+	id, err := strconv.ParseUint(recordID, 10, 64)
+	if err != nil {
+		return errorf(ErrInvalidParam, "无效的记录ID: %s", recordID)
+	}
+	return p.client.DeleteRecord(ctx, domain, id)
+}
+
+// SetRecordStatus 设置记录状态，腾讯云使用大写的ENABLE/DISABLE
+func (p *TencentCloudProvider) SetRecordStatus(ctx context.Context, domain, recordID, status string) error {
+	id, err := strconv.ParseUint(recordID, 10, 64)
+	if err != nil {
+		return errorf(ErrInvalidParam, "无效的记录ID: %s", recordID)
+	}
+	return p.client.SetRecordStatus(ctx, domain, id, strings.ToUpper(status))
+}
+
+// toTencentCloudRecord 转换为腾讯云记录结构
+func toTencentCloudRecord(record Record) TencentCloudRecord {
+	line := record.Line
+	if line == "" {
+		line = DefaultLineName
+	}
+	r := TencentCloudRecord{
+		Name:   record.Name,
+		Type:   record.Type,
+		Value:  record.Value,
+		Line:   line,
+		TTL:    uint64(record.TTL),
+		MX:     uint64(record.mxPriority()),
+		Status: strings.ToUpper(record.Status),
+		Remark: record.Remark,
+	}
+	if record.Weight > 0 {
+		weight := uint64(record.Weight)
+		r.Weight = &weight
+	}
+	return r
+}
+
+// fromTencentCloudRecord 转换为统一记录结构
+func fromTencentCloudRecord(domain string, r TencentCloudRecord) Record {
+	record := Record{
+		ID:       strconv.FormatUint(r.RecordId, 10),
+		Domain:   domain,
+		Name:     r.Name,
+		Type:     r.Type,
+		Value:    r.Value,
+		TTL:      int64(r.TTL),
+		Priority: int64(r.MX),
+		Line:     r.Line,
+		Status:   strings.ToLower(r.Status),
+		Remark:   r.Remark,
+	}
+	if r.Weight != nil {
+		record.Weight = int64(*r.Weight)
+	}
+	return record
+}
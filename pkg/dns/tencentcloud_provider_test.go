@@ -0,0 +1,20 @@
+package dns
+
+import "testing"
+
+func TestToTencentCloudRecordMX(t *testing.T) {
+	tests := []struct {
+		record Record
+		want   uint64
+	}{
+		{Record{Name: "@", Type: "MX", Value: "mx.example.com.", Priority: 10}, 10},
+		{Record{Name: "@", Type: "mx", Value: "mx.example.com.", Priority: 5}, 5},
+		{Record{Name: "www", Type: "A", Value: "1.2.3.4", Priority: 10}, 0},
+		{Record{Name: "_sip._tcp", Type: "SRV", Value: "0 5 5060 sip.example.com.", Priority: 10}, 0},
+	}
+	for _, tt := range tests {
+		if got := toTencentCloudRecord(tt.record).MX; got != tt.want {
+			t.Errorf("%s记录的MX = %d, 期望 %d", tt.record.Type, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sharedTransport 所有服务商共用的连接池
+var sharedTransport = http.DefaultTransport.(*http.Transport).Clone()
+
+// 未配置时的限流和重试参数
+const (
+	DefaultRateLimit  = 10 // 每秒请求数
+	DefaultMaxRetries = 3
+)
+
+// HTTPClient 服务商HTTP接口共用的客户端
+//
+// 按账号进行令牌桶限流；遇到限流、5xx和网络错误时按带抖动的指数退避重试。
+// 非幂等的请求(如创建记录)只在确定服务商没有处理时重试，即被限流或连接未建立。
+type HTTPClient struct {
+	Client     *http.Client
+	Limiter    *RateLimiter
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+
+	// Throttled 根据响应判断是否被服务商限流，HTTP 429总是视为限流
+	Throttled func(statusCode int, body []byte) bool
+}
+
+// NewHTTPClient 创建使用默认参数的HTTP客户端
+func NewHTTPClient(throttled func(statusCode int, body []byte) bool) *HTTPClient {
+	return &HTTPClient{
+		Client:     &http.Client{Transport: sharedTransport},
+		Limiter:    NewRateLimiter(DefaultRateLimit, DefaultRateLimit),
+		MaxRetries: DefaultMaxRetries,
+		BaseDelay:  200 * time.Millisecond,
+		MaxDelay:   5 * time.Second,
+		Throttled:  throttled,
+	}
+}
+
+// Configure 按账号配置设置限流和重试参数
+//
+// RATE_LIMIT 每秒请求数，0表示不限流；BURST 突发请求数，默认等于RATE_LIMIT；MAX_RETRIES 最大重试次数
+func (c *HTTPClient) Configure(cfg Config) {
+	if rate, err := strconv.ParseFloat(cfg.Get("RATE_LIMIT"), 64); err == nil {
+		burst, err := strconv.Atoi(cfg.Get("BURST"))
+		if err != nil || burst <= 0 {
+			burst = int(rate)
+		}
+		c.Limiter = NewRateLimiter(rate, burst)
+	}
+	if retries, err := strconv.Atoi(cfg.Get("MAX_RETRIES")); err == nil && retries >= 0 {
+		c.MaxRetries = retries
+	}
+}
+
+// Do 发送请求并读取响应体，返回的响应体已关闭
+//
+// 每次尝试都调用newRequest重新构建请求，以便重新签名；idempotent为false时，只在被限流或连接未建立时重试
+func (c *HTTPClient) Do(ctx context.Context, idempotent bool, newRequest func() (*http.Request, error)) (*http.Response, []byte, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return nil, nil, err
+		}
+
+		req, err := newRequest()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		resp, err := c.Client.Do(req)
+		if err != nil {
+			if ctx.Err() == nil && attempt < c.MaxRetries && (idempotent || isDialError(err)) {
+				if err := c.sleep(ctx, c.backoff(attempt)); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			return nil, nil, err
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			if ctx.Err() == nil && attempt < c.MaxRetries && idempotent {
+				if err := c.sleep(ctx, c.backoff(attempt)); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			return nil, nil, err
+		}
+
+		throttled := resp.StatusCode == http.StatusTooManyRequests || (c.Throttled != nil && c.Throttled(resp.StatusCode, body))
+		retry := throttled || (idempotent && resp.StatusCode >= 500)
+		if retry && attempt < c.MaxRetries {
+			delay := c.backoff(attempt)
+			if after := retryAfter(resp); after > delay {
+				delay = after
+			}
+			if err := c.sleep(ctx, delay); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		return resp, body, nil
+	}
+}
+
+// backoff 第attempt次重试前的等待时间，在指数退避的基础上加入随机抖动
+func (c *HTTPClient) backoff(attempt int) time.Duration {
+	delay := c.BaseDelay << uint(attempt)
+	if delay <= 0 || delay > c.MaxDelay {
+		delay = c.MaxDelay
+	}
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// sleep 等待一段时间，ctx结束时提前返回
+func (c *HTTPClient) sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryAfter 解析Retry-After头中的秒数
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// isDialError 连接未建立的错误，此时请求一定没有发出
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// RateLimiter 令牌桶限流器
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒补充的令牌数，不大于0时不限流
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 创建令牌桶，初始为满
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait 取得一个令牌，令牌不足时等待，ctx结束时归还预留的令牌并返回错误
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if l == nil || l.rate <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	// 预留令牌，令牌数可以为负，表示排在前面的等待者
+	l.tokens--
+	wait := time.Duration(0)
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		l.release()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// release 归还Wait中预留但没有使用的令牌
+func (l *RateLimiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
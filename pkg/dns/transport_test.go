@@ -0,0 +1,186 @@
+package dns
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestHTTPClient 不限流、重试间隔很短的客户端
+func newTestHTTPClient(throttled func(int, []byte) bool) *HTTPClient {
+	c := NewHTTPClient(throttled)
+	c.Limiter = nil
+	c.BaseDelay = time.Millisecond
+	c.MaxDelay = 5 * time.Millisecond
+	return c
+}
+
+// newRedirectedHTTPClient 将固定地址的API请求(如 https://dnsapi.cn)转发到测试服务器
+func newRedirectedHTTPClient(throttled func(int, []byte) bool, srv *httptest.Server) *HTTPClient {
+	c := newTestHTTPClient(throttled)
+	c.Client = &http.Client{Transport: redirectTransport{target: srv.URL}}
+	return c
+}
+
+type redirectTransport struct {
+	target string
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	target, err := url.Parse(t.target)
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = target.Scheme, target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestHTTPClientRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		idempotent bool
+		statuses   []int // 依次返回的状态码，用完后返回最后一个
+		body       string
+		wantStatus int
+		wantCalls  int32
+	}{
+		{name: "幂等请求5xx后重试", idempotent: true, statuses: []int{503, 502, 200}, wantStatus: 200, wantCalls: 3},
+		{name: "非幂等请求5xx不重试", idempotent: false, statuses: []int{500, 200}, wantStatus: 500, wantCalls: 1},
+		{name: "非幂等请求503不重试", idempotent: false, statuses: []int{503, 200}, wantStatus: 503, wantCalls: 1},
+		{name: "幂等请求429后重试", idempotent: true, statuses: []int{429, 200}, wantStatus: 200, wantCalls: 2},
+		{name: "非幂等请求429后重试", idempotent: false, statuses: []int{429, 429, 200}, wantStatus: 200, wantCalls: 3},
+		{name: "服务商的限流错误码", idempotent: false, statuses: []int{400, 200}, body: "Throttling", wantStatus: 200, wantCalls: 2},
+		{name: "4xx不重试", idempotent: true, statuses: []int{404}, wantStatus: 404, wantCalls: 1},
+		{name: "超过最大重试次数", idempotent: true, statuses: []int{503}, wantStatus: 503, wantCalls: DefaultMaxRetries + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(atomic.AddInt32(&calls, 1)) - 1
+				if n >= len(tt.statuses) {
+					n = len(tt.statuses) - 1
+				}
+				body := "ok"
+				if n < len(tt.statuses)-1 && tt.body != "" {
+					body = tt.body
+				}
+				w.WriteHeader(tt.statuses[n])
+				w.Write([]byte(body))
+			}))
+			defer srv.Close()
+
+			c := newTestHTTPClient(func(status int, body []byte) bool {
+				return status == 400 && strings.Contains(string(body), "Throttling")
+			})
+			resp, _, err := c.Do(context.Background(), tt.idempotent, func() (*http.Request, error) {
+				return http.NewRequest(http.MethodPost, srv.URL, nil)
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("状态码 = %d, 期望 %d", resp.StatusCode, tt.wantStatus)
+			}
+			if got := atomic.LoadInt32(&calls); got != tt.wantCalls {
+				t.Errorf("请求了 %d 次, 期望 %d 次", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHTTPClientRebuildsRequest(t *testing.T) {
+	var auths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		if len(auths) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer srv.Close()
+
+	n := 0
+	c := newTestHTTPClient(nil)
+	_, _, err := c.Do(context.Background(), false, func() (*http.Request, error) {
+		n++
+		req, err := http.NewRequest(http.MethodPost, srv.URL, nil)
+		req.Header.Set("Authorization", strings.Repeat("x", n))
+		return req, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(auths) != 2 || auths[0] == auths[1] {
+		t.Errorf("每次重试都应重新构建请求，收到的Authorization = %v", auths)
+	}
+}
+
+func TestHTTPClientCanceledDuringBackoff(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := newTestHTTPClient(nil)
+	c.BaseDelay = time.Second
+	c.MaxDelay = time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, _, err := c.Do(ctx, true, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("err = %v, 期望context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("ctx结束后应立即返回，耗时 %v", elapsed)
+	}
+}
+
+func TestRateLimiterReleasesOnCancel(t *testing.T) {
+	l := NewRateLimiter(1, 1)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Fatal("令牌不足且ctx结束时应返回错误")
+	}
+
+	// 取消的等待者归还令牌，不影响后面的请求
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.1 {
+		t.Errorf("取消后令牌数为 %.2f，预留的令牌没有归还", tokens)
+	}
+}
+
+func TestRateLimiterBurst(t *testing.T) {
+	l := NewRateLimiter(1000, 3)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("突发范围内的请求不应等待，耗时 %v", elapsed)
+	}
+
+	var nilLimiter *RateLimiter
+	if err := nilLimiter.Wait(context.Background()); err != nil {
+		t.Errorf("未配置限流时不应等待: %v", err)
+	}
+}
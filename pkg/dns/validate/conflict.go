@@ -0,0 +1,166 @@
+package validate
+
+import (
+	"net"
+	"strings"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// 冲突的严重程度，error会拒绝提交，warning只提示
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// 冲突规则
+const (
+	RuleApexCNAME      = "apex_cname"      // 根域名不能添加CNAME
+	RuleCNAMEExclusive = "cname_exclusive" // CNAME不能与同名的其他记录共存
+	RuleDuplicate      = "duplicate"       // 名称、类型、线路和值都相同的记录
+	RuleApexNS         = "apex_ns"         // 修改根域名的NS记录
+)
+
+// Conflict 拟提交的记录与现有记录的冲突
+type Conflict struct {
+	Severity string      `json:"severity"`
+	Rule     string      `json:"rule"`
+	Message  string      `json:"message"`
+	Existing *dns.Record `json:"existing,omitempty"` // 与之冲突的现有记录
+}
+
+// Conflicts 一条记录的全部冲突，作为错误时可以用errors.Is(err, dns.ErrConflict)判断
+type Conflicts []Conflict
+
+func (c Conflicts) Error() string {
+	msgs := make([]string, 0, len(c))
+	for _, conflict := range c {
+		msgs = append(msgs, conflict.Message)
+	}
+	return "记录冲突: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap 冲突错误都属于dns.ErrConflict
+func (c Conflicts) Unwrap() error {
+	return dns.ErrConflict
+}
+
+// Err 有error级别的冲突时返回全部冲突，否则返回nil
+func (c Conflicts) Err() error {
+	for _, conflict := range c {
+		if conflict.Severity == SeverityError {
+			return c
+		}
+	}
+	return nil
+}
+
+// CheckConflicts 检查拟提交的记录与现有记录集的冲突
+//
+// existing为同一域名的现有记录，与record的ID相同的记录视为被修改的记录，不参与比较。
+// 只比较同名且同线路的记录，不同线路的记录在各自的视图中解析，不会冲突
+func CheckConflicts(existing []dns.Record, record dns.Record) Conflicts {
+	var conflicts Conflicts
+
+	name := normalizeName(record.Name)
+	recordType := strings.ToUpper(record.Type)
+
+	if name == "@" {
+		switch recordType {
+		case "CNAME":
+			conflicts = append(conflicts, Conflict{
+				Severity: SeverityError,
+				Rule:     RuleApexCNAME,
+				Message:  "根域名(@)不能添加CNAME记录，它会与SOA和NS记录冲突，可以使用服务商的ALIAS/CNAME拉平",
+			})
+		case "NS":
+			conflicts = append(conflicts, Conflict{
+				Severity: SeverityWarning,
+				Rule:     RuleApexNS,
+				Message:  "修改根域名(@)的NS记录会改变域名的权威服务器，可能导致整个域名无法解析",
+			})
+		}
+	}
+
+	for i := range existing {
+		other := existing[i]
+		if record.ID != "" && other.ID == record.ID {
+			continue
+		}
+		if normalizeName(other.Name) != name || !SameLine(other.Line, record.Line) {
+			continue
+		}
+
+		otherType := strings.ToUpper(other.Type)
+		switch {
+		case recordType == "CNAME" && otherType == "CNAME":
+			conflicts = append(conflicts, Conflict{
+				Severity: SeverityError,
+				Rule:     RuleCNAMEExclusive,
+				Message:  "主机记录 " + record.Name + " 已有CNAME记录，同一名称只能有一条CNAME记录",
+				Existing: &other,
+			})
+		case recordType == "CNAME":
+			conflicts = append(conflicts, Conflict{
+				Severity: SeverityError,
+				Rule:     RuleCNAMEExclusive,
+				Message:  "主机记录 " + record.Name + " 已有" + otherType + "记录，不能再添加CNAME记录",
+				Existing: &other,
+			})
+		case otherType == "CNAME":
+			conflicts = append(conflicts, Conflict{
+				Severity: SeverityError,
+				Rule:     RuleCNAMEExclusive,
+				Message:  "主机记录 " + record.Name + " 已有CNAME记录，不能再添加" + recordType + "记录",
+				Existing: &other,
+			})
+		case otherType == recordType && SameValue(recordType, other, record):
+			conflicts = append(conflicts, Conflict{
+				Severity: SeverityError,
+				Rule:     RuleDuplicate,
+				Message:  "主机记录 " + record.Name + " 已有相同的" + recordType + "记录: " + other.Value,
+				Existing: &other,
+			})
+		}
+	}
+	return conflicts
+}
+
+// normalizeName 统一主机记录的写法，空字符串表示根域名，不区分大小写
+func normalizeName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "" {
+		return "@"
+	}
+	return name
+}
+
+// SameLine 判断是否为同一线路，空线路、默认和default都表示默认线路
+func SameLine(a, b string) bool {
+	isDefault := func(line string) bool {
+		return line == "" || line == dns.DefaultLineName || strings.EqualFold(line, "default")
+	}
+	if isDefault(a) || isDefault(b) {
+		return isDefault(a) && isDefault(b)
+	}
+	return strings.EqualFold(a, b)
+}
+
+// SameValue 判断记录值是否相同，域名类型的值不区分大小写和结尾的点
+func SameValue(recordType string, a, b dns.Record) bool {
+	if recordType == "MX" && a.Priority != 0 && b.Priority != 0 && a.Priority != b.Priority {
+		return false
+	}
+	switch recordType {
+	case "CNAME", "NS", "PTR", "ALIAS", "MX":
+		return strings.EqualFold(strings.TrimSuffix(a.Value, "."), strings.TrimSuffix(b.Value, "."))
+	case "TXT", "SPF":
+		return strings.Trim(a.Value, `"`) == strings.Trim(b.Value, `"`)
+	case "A", "AAAA":
+		ipA, ipB := net.ParseIP(strings.TrimSpace(a.Value)), net.ParseIP(strings.TrimSpace(b.Value))
+		if ipA != nil && ipB != nil {
+			return ipA.Equal(ipB)
+		}
+	}
+	return strings.EqualFold(strings.TrimSpace(a.Value), strings.TrimSpace(b.Value))
+}
@@ -0,0 +1,97 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+func TestCheckConflicts(t *testing.T) {
+	existing := []dns.Record{
+		{ID: "1", Name: "www", Type: "A", Value: "192.0.2.1"},
+		{ID: "2", Name: "blog", Type: "CNAME", Value: "www.example.com"},
+		{ID: "3", Name: "cdn", Type: "CNAME", Value: "cdn-telecom.example.net", Line: "电信"},
+		{ID: "4", Name: "@", Type: "MX", Value: "mail.example.com", Priority: 10},
+	}
+
+	tests := []struct {
+		name   string
+		record dns.Record
+		rules  []string // 期望的冲突规则，nil表示没有冲突
+		err    bool     // 是否有error级别的冲突
+	}{
+		{"新名称", dns.Record{Name: "api", Type: "A", Value: "192.0.2.2"}, nil, false},
+		{"同名的A记录可以有多个值", dns.Record{Name: "www", Type: "A", Value: "192.0.2.2"}, nil, false},
+		{"重复的A记录", dns.Record{Name: "WWW.", Type: "A", Value: "192.0.2.1"}, []string{RuleDuplicate}, true},
+		{"CNAME与同名A记录冲突", dns.Record{Name: "www", Type: "CNAME", Value: "example.net"}, []string{RuleCNAMEExclusive}, true},
+		{"A记录与同名CNAME冲突", dns.Record{Name: "blog", Type: "A", Value: "192.0.2.1"}, []string{RuleCNAMEExclusive}, true},
+		{"同名只能有一条CNAME", dns.Record{Name: "blog", Type: "CNAME", Value: "example.net"}, []string{RuleCNAMEExclusive}, true},
+		{"修改记录本身不冲突", dns.Record{ID: "2", Name: "blog", Type: "CNAME", Value: "example.net"}, nil, false},
+		{"修改为A记录时与其他CNAME比较", dns.Record{ID: "1", Name: "blog", Type: "A", Value: "192.0.2.1"}, []string{RuleCNAMEExclusive}, true},
+		{"不同线路的CNAME不冲突", dns.Record{Name: "cdn", Type: "A", Value: "192.0.2.1", Line: "联通"}, nil, false},
+		{"默认线路与指定线路不冲突", dns.Record{Name: "cdn", Type: "A", Value: "192.0.2.1"}, nil, false},
+		{"同一线路的CNAME冲突", dns.Record{Name: "cdn", Type: "A", Value: "192.0.2.1", Line: "电信"}, []string{RuleCNAMEExclusive}, true},
+		{"default与默认线路相同", dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", Line: "default"}, []string{RuleDuplicate}, true},
+		{"MX优先级不同不重复", dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 20}, nil, false},
+		{"MX值不区分大小写和结尾的点", dns.Record{Name: "", Type: "MX", Value: "MAIL.example.com.", Priority: 10}, []string{RuleDuplicate}, true},
+		{"根域名不能添加CNAME", dns.Record{Name: "@", Type: "CNAME", Value: "example.net"}, []string{RuleApexCNAME, RuleCNAMEExclusive}, true},
+		{"修改根域名NS只是警告", dns.Record{Name: "@", Type: "NS", Value: "ns1.example.net"}, []string{RuleApexNS}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conflicts := CheckConflicts(existing, tt.record)
+			var rules []string
+			for _, conflict := range conflicts {
+				rules = append(rules, conflict.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.rules) {
+				t.Errorf("冲突规则 = %v, 期望 %v", rules, tt.rules)
+			}
+
+			err := conflicts.Err()
+			if (err != nil) != tt.err {
+				t.Errorf("Err() = %v, 期望有错误: %v", err, tt.err)
+			}
+			if err != nil && !errors.Is(err, dns.ErrConflict) {
+				t.Errorf("冲突错误应属于ErrConflict: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckConflictsExisting(t *testing.T) {
+	existing := []dns.Record{{ID: "1", Name: "www", Type: "A", Value: "192.0.2.1"}}
+	conflicts := CheckConflicts(existing, dns.Record{Name: "www", Type: "CNAME", Value: "example.net"})
+	if len(conflicts) != 1 || conflicts[0].Existing == nil || conflicts[0].Existing.ID != "1" {
+		t.Fatalf("冲突 = %+v, 期望指向记录1", conflicts)
+	}
+
+	// 返回的是副本，修改不影响调用方的记录
+	conflicts[0].Existing.Value = "192.0.2.9"
+	if existing[0].Value != "192.0.2.1" {
+		t.Error("冲突中的现有记录不应与调用方共享")
+	}
+}
+
+func TestSameLine(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"", "", true},
+		{"", dns.DefaultLineName, true},
+		{"default", "", true},
+		{"电信", "电信", true},
+		{"CN", "cn", true},
+		{"", "电信", false},
+		{"电信", "联通", false},
+	}
+	for _, tt := range tests {
+		if got := SameLine(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameLine(%q, %q) = %v, 期望 %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
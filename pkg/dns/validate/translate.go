@@ -0,0 +1,112 @@
+package validate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// providerTypes 各服务商支持的记录类型，没有列出的服务商不限制记录类型
+var providerTypes = map[string][]string{
+	dns.ProviderDnsPod:       {"A", "AAAA", "CNAME", "MX", "TXT", "NS", "SRV", "CAA", "SPF", "显性URL", "隐性URL"},
+	dns.ProviderTencentCloud: {"A", "AAAA", "CNAME", "MX", "TXT", "NS", "SRV", "CAA", "SPF", "显性URL", "隐性URL"},
+	dns.ProviderAliyun:       {"A", "AAAA", "CNAME", "MX", "TXT", "NS", "SRV", "CAA", "REDIRECT_URL", "FORWARD_URL"},
+	dns.ProviderCloudflare:   {"A", "AAAA", "CNAME", "MX", "TXT", "NS", "SRV", "CAA", "PTR"},
+	dns.ProviderRoute53:      {"A", "AAAA", "CNAME", "MX", "TXT", "NS", "SRV", "CAA", "PTR", "SPF"},
+}
+
+// typeAliases 不同服务商中含义相同的记录类型，如DNSPod的显性URL对应阿里云的REDIRECT_URL
+var typeAliases = [][]string{
+	{"显性URL", "REDIRECT_URL"},
+	{"隐性URL", "FORWARD_URL"},
+	{"SPF", "TXT"}, // 不支持SPF类型的服务商以TXT记录保存SPF
+}
+
+// SupportsType 服务商类型是否支持记录类型
+func SupportsType(providerType, recordType string) bool {
+	types, ok := providerTypes[providerType]
+	if !ok {
+		return true
+	}
+	for _, t := range types {
+		if strings.EqualFold(t, recordType) {
+			return true
+		}
+	}
+	return false
+}
+
+// Translate 将源服务商的记录转换为目标服务商能保存的记录，返回转换后的记录和所做调整的说明
+//
+// 记录类型换为目标服务商的同义类型，TTL、MX优先级和权重调整到目标服务商的范围内；
+// 无法表示的记录(不支持的类型、超长的TXT等)返回dns.ErrUnsupported。线路由调用方按目标服务商的线路转换
+func Translate(sourceType, targetType string, record dns.Record) (dns.Record, []string, error) {
+	source, target := LimitsFor(sourceType), LimitsFor(targetType)
+	var notes []string
+	record.ID = ""
+
+	if !SupportsType(targetType, record.Type) {
+		translated := ""
+		for _, aliases := range typeAliases {
+			if !strings.EqualFold(aliases[0], record.Type) && !strings.EqualFold(aliases[1], record.Type) {
+				continue
+			}
+			for _, alias := range aliases {
+				if !strings.EqualFold(alias, record.Type) && SupportsType(targetType, alias) {
+					translated = alias
+				}
+			}
+		}
+		if translated == "" {
+			return record, nil, fmt.Errorf("%w: 目标服务商不支持%s记录", dns.ErrUnsupported, record.Type)
+		}
+		notes = append(notes, "记录类型从"+record.Type+"转换为"+translated)
+		record.Type = translated
+	}
+
+	if target.MaxTXTLength > 0 && strings.EqualFold(record.Type, "TXT") && len(record.Value) > target.MaxTXTLength {
+		return record, nil, fmt.Errorf("%w: TXT记录的值有%d字节，目标服务商最多%d字节", dns.ErrUnsupported, len(record.Value), target.MaxTXTLength)
+	}
+
+	switch ttl := record.TTL; {
+	case source.AutoTTL != 0 && ttl == source.AutoTTL && target.AutoTTL == 0:
+		record.TTL = clamp(600, target.MinTTL, target.MaxTTL)
+		notes = append(notes, "源服务商的自动TTL转换为"+strconv.FormatInt(record.TTL, 10))
+	case target.AutoTTL != 0 && ttl == target.AutoTTL:
+	case ttl < target.MinTTL || ttl > target.MaxTTL:
+		record.TTL = clamp(ttl, target.MinTTL, target.MaxTTL)
+		notes = append(notes, "TTL从"+strconv.FormatInt(ttl, 10)+"调整为"+strconv.FormatInt(record.TTL, 10))
+	}
+
+	if strings.EqualFold(record.Type, "MX") && record.Priority != 0 &&
+		(record.Priority < target.MinPriority || record.Priority > target.MaxPriority) {
+		priority := record.Priority
+		record.Priority = clamp(priority, target.MinPriority, target.MaxPriority)
+		notes = append(notes, "MX优先级从"+strconv.FormatInt(priority, 10)+"调整为"+strconv.FormatInt(record.Priority, 10))
+	}
+
+	if record.Weight > target.MaxWeight {
+		weight := record.Weight
+		if target.MaxWeight == 0 {
+			record.Weight = 0
+			notes = append(notes, "目标服务商不支持权重，忽略权重"+strconv.FormatInt(weight, 10))
+		} else {
+			record.Weight = target.MaxWeight
+			notes = append(notes, "权重从"+strconv.FormatInt(weight, 10)+"调整为"+strconv.FormatInt(record.Weight, 10))
+		}
+	}
+	return record, notes, nil
+}
+
+// clamp 将n限制在[lo, hi]范围内
+func clamp(n, lo, hi int64) int64 {
+	if n < lo {
+		return lo
+	}
+	if n > hi {
+		return hi
+	}
+	return n
+}
@@ -0,0 +1,136 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		target string
+		record dns.Record
+		want   dns.Record
+		notes  int
+	}{
+		{
+			name:   "无需调整",
+			source: dns.ProviderDnsPod, target: dns.ProviderAliyun,
+			record: dns.Record{ID: "1", Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600},
+			want:   dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600},
+		},
+		{
+			name:   "TTL超过上限",
+			source: dns.ProviderDnsPod, target: dns.ProviderAliyun,
+			record: dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 604800},
+			want:   dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 86400},
+			notes:  1,
+		},
+		{
+			name:   "TTL低于下限",
+			source: dns.ProviderDnsPod, target: dns.ProviderCloudflare,
+			record: dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 10},
+			want:   dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 60},
+			notes:  1,
+		},
+		{
+			name:   "自动TTL转换为600",
+			source: dns.ProviderCloudflare, target: dns.ProviderDnsPod,
+			record: dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 1},
+			want:   dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600},
+			notes:  1,
+		},
+		{
+			name:   "目标服务商的自动TTL保留",
+			source: dns.ProviderDnsPod, target: dns.ProviderCloudflare,
+			record: dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 1},
+			want:   dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 1},
+		},
+		{
+			name:   "MX优先级超过上限",
+			source: dns.ProviderCloudflare, target: dns.ProviderDnsPod,
+			record: dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 100, TTL: 600},
+			want:   dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 20, TTL: 600},
+			notes:  1,
+		},
+		{
+			name:   "MX优先级低于下限",
+			source: dns.ProviderTencentCloud, target: dns.ProviderDnsPod,
+			record: dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: -5, TTL: 600},
+			want:   dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 1, TTL: 600},
+			notes:  1,
+		},
+		{
+			name:   "权重超过上限",
+			source: dns.ProviderRoute53, target: dns.ProviderDnsPod,
+			record: dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Weight: 200},
+			want:   dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Weight: 100},
+			notes:  1,
+		},
+		{
+			name:   "目标服务商不支持权重",
+			source: dns.ProviderDnsPod, target: dns.ProviderCloudflare,
+			record: dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Weight: 50},
+			want:   dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600},
+			notes:  1,
+		},
+		{
+			name:   "显性URL转换为REDIRECT_URL",
+			source: dns.ProviderDnsPod, target: dns.ProviderAliyun,
+			record: dns.Record{Name: "go", Type: "显性URL", Value: "https://example.com", TTL: 600},
+			want:   dns.Record{Name: "go", Type: "REDIRECT_URL", Value: "https://example.com", TTL: 600},
+			notes:  1,
+		},
+		{
+			name:   "SPF转换为TXT",
+			source: dns.ProviderDnsPod, target: dns.ProviderAliyun,
+			record: dns.Record{Name: "@", Type: "SPF", Value: "v=spf1 -all", TTL: 600},
+			want:   dns.Record{Name: "@", Type: "TXT", Value: "v=spf1 -all", TTL: 600},
+			notes:  1,
+		},
+		{
+			name:   "多项调整",
+			source: dns.ProviderRoute53, target: dns.ProviderAliyun,
+			record: dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 100, TTL: 0, Weight: 255},
+			want:   dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 50, TTL: 1, Weight: 100},
+			notes:  3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notes, err := Translate(tt.source, tt.target, tt.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("转换后的记录 = %+v, 期望 %+v", got, tt.want)
+			}
+			if len(notes) != tt.notes {
+				t.Errorf("调整说明 = %v, 期望 %d 条", notes, tt.notes)
+			}
+		})
+	}
+}
+
+func TestTranslateUnsupported(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		record dns.Record
+	}{
+		{"不支持的记录类型", dns.ProviderCloudflare, dns.Record{Name: "go", Type: "显性URL", Value: "https://example.com", TTL: 600}},
+		{"TXT记录超过目标服务商上限", dns.ProviderAliyun, dns.Record{Name: "@", Type: "TXT", Value: strings.Repeat("a", 1000), TTL: 600}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := Translate(dns.ProviderRoute53, tt.target, tt.record); !errors.Is(err, dns.ErrUnsupported) {
+				t.Errorf("返回 %v, 期望ErrUnsupported", err)
+			}
+		})
+	}
+}
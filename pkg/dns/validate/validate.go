@@ -0,0 +1,323 @@
+// Package validate 按记录类型校验DNS记录，在提交到服务商或写入数据库前发现格式错误
+package validate
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// FieldError 单个字段的校验错误，Field为记录的JSON字段名
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Errors 一条记录的全部校验错误，可以用errors.Is(err, dns.ErrInvalidParam)判断
+type Errors []FieldError
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, fe := range e {
+		msgs = append(msgs, fe.Field+": "+fe.Message)
+	}
+	return "记录校验失败: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap 校验错误都属于参数错误
+func (e Errors) Unwrap() error {
+	return dns.ErrInvalidParam
+}
+
+// add 添加一个字段的错误
+func (e *Errors) add(field, message string) {
+	*e = append(*e, FieldError{Field: field, Message: message})
+}
+
+// Limits 服务商对记录的限制
+type Limits struct {
+	MinTTL       int64
+	MaxTTL       int64
+	AutoTTL      int64 // 表示自动TTL的特殊值，不受MinTTL限制，0表示没有
+	MinPriority  int64 // MX优先级
+	MaxPriority  int64
+	MaxWeight    int64 // 权重上限，0表示服务商不支持权重
+	MaxTXTLength int   // TXT记录值的最大字节数，0表示不限制
+}
+
+// defaultLimits 未知服务商类型或数据库记录使用协议本身的限制
+var defaultLimits = Limits{
+	MinTTL:      0,
+	MaxTTL:      2147483647,
+	MinPriority: 0,
+	MaxPriority: 65535,
+}
+
+// providerLimits 各服务商的限制，TTL下限为付费套餐的下限，免费套餐的下限由服务商校验
+var providerLimits = map[string]Limits{
+	dns.ProviderDnsPod:       {MinTTL: 1, MaxTTL: 604800, MinPriority: 1, MaxPriority: 20, MaxWeight: 100, MaxTXTLength: 512},
+	dns.ProviderAliyun:       {MinTTL: 1, MaxTTL: 86400, MinPriority: 1, MaxPriority: 50, MaxWeight: 100, MaxTXTLength: 512},
+	dns.ProviderTencentCloud: {MinTTL: 1, MaxTTL: 604800, MinPriority: 0, MaxPriority: 50, MaxWeight: 100, MaxTXTLength: 512},
+	dns.ProviderCloudflare:   {MinTTL: 60, MaxTTL: 86400, AutoTTL: 1, MinPriority: 0, MaxPriority: 65535, MaxTXTLength: 2048},
+	dns.ProviderRoute53:      {MinTTL: 0, MaxTTL: 2147483647, MinPriority: 0, MaxPriority: 65535, MaxWeight: 255, MaxTXTLength: 4000},
+	dns.ProviderPowerDNS:     defaultLimits,
+	dns.ProviderRFC2136:      defaultLimits,
+	dns.ProviderMemory:       {MinTTL: 1, MaxTTL: 604800, MinPriority: 0, MaxPriority: 65535, MaxWeight: 100},
+}
+
+// LimitsFor 获取服务商类型的限制，providerType为空或未知时返回协议本身的限制
+func LimitsFor(providerType string) Limits {
+	if limits, ok := providerLimits[providerType]; ok {
+		return limits
+	}
+	return defaultLimits
+}
+
+// Record 按记录类型校验记录，providerType为服务商类型，用于TTL等取值范围
+//
+// 记录没有错误时返回nil，否则返回Errors。MX优先级为0时视为未指定，由服务商使用默认值；
+// 不认识的记录类型(如DNSPod的URL转发)只校验主机记录和TTL
+func Record(providerType string, record dns.Record) error {
+	limits := LimitsFor(providerType)
+	var errs Errors
+
+	recordType := strings.ToUpper(strings.TrimSpace(record.Type))
+	if recordType == "" {
+		errs.add("type", "记录类型不能为空")
+	}
+	if msg := checkName(record.Name); msg != "" {
+		errs.add("name", msg)
+	}
+	if record.Value == "" {
+		errs.add("value", "记录值不能为空")
+	} else if msg := checkValue(recordType, record, limits); msg != "" {
+		errs.add("value", msg)
+	}
+
+	if record.TTL != limits.AutoTTL || limits.AutoTTL == 0 {
+		if record.TTL < limits.MinTTL || record.TTL > limits.MaxTTL {
+			msg := "TTL必须在" + strconv.FormatInt(limits.MinTTL, 10) + "到" + strconv.FormatInt(limits.MaxTTL, 10) + "之间"
+			if limits.AutoTTL != 0 {
+				msg += "，或为" + strconv.FormatInt(limits.AutoTTL, 10) + "表示自动"
+			}
+			errs.add("ttl", msg)
+		}
+	}
+
+	if recordType == "MX" && record.Priority != 0 &&
+		(record.Priority < limits.MinPriority || record.Priority > limits.MaxPriority) {
+		errs.add("priority", "MX优先级必须在"+strconv.FormatInt(limits.MinPriority, 10)+"到"+strconv.FormatInt(limits.MaxPriority, 10)+"之间")
+	}
+	if record.Priority < 0 {
+		errs.add("priority", "优先级不能为负数")
+	}
+
+	if record.Weight < 0 {
+		errs.add("weight", "权重不能为负数")
+	} else if record.Weight > 0 && record.Weight > limits.MaxWeight {
+		if limits.MaxWeight == 0 {
+			errs.add("weight", "服务商不支持权重")
+		} else {
+			errs.add("weight", "权重不能大于"+strconv.FormatInt(limits.MaxWeight, 10))
+		}
+	}
+
+	if recordType == "SRV" && record.Name != "" && !strings.HasPrefix(record.Name, "_") {
+		errs.add("name", "SRV记录的主机记录应为 _服务._协议 的形式，如 _sip._tcp")
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// checkName 校验主机记录，@表示域名本身，*表示泛解析
+func checkName(name string) string {
+	if name == "" {
+		return "主机记录不能为空"
+	}
+	if name == "@" || name == "*" {
+		return ""
+	}
+
+	labels := strings.Split(strings.TrimSuffix(name, "."), ".")
+	for i, label := range labels {
+		if label == "*" && i == 0 {
+			continue
+		}
+		if msg := checkLabel(label); msg != "" {
+			return msg
+		}
+	}
+	if len(name) > 253 {
+		return "主机记录不能超过253个字符"
+	}
+	return ""
+}
+
+// checkValue 按记录类型校验记录值
+func checkValue(recordType string, record dns.Record, limits Limits) string {
+	value := strings.TrimSpace(record.Value)
+	switch recordType {
+	case "A":
+		if ip := net.ParseIP(value); ip == nil || ip.To4() == nil || strings.Contains(value, ":") {
+			return "A记录的值必须是IPv4地址"
+		}
+	case "AAAA":
+		if ip := net.ParseIP(value); ip == nil || !strings.Contains(value, ":") {
+			return "AAAA记录的值必须是IPv6地址"
+		}
+	case "CNAME", "NS", "PTR", "ALIAS", "MX":
+		if msg := checkHostname(value); msg != "" {
+			return recordType + "记录的值" + msg
+		}
+	case "TXT":
+		return checkTXT(record.Value, limits.MaxTXTLength)
+	case "SRV":
+		return checkSRV(value)
+	case "CAA":
+		return checkCAA(value)
+	}
+	return ""
+}
+
+// checkHostname 校验目标主机名，允许结尾的点；不能是IP地址
+func checkHostname(host string) string {
+	host = strings.TrimSuffix(host, ".")
+	if host == "" {
+		return "不能为空"
+	}
+	if net.ParseIP(host) != nil {
+		return "必须是域名，不能是IP地址"
+	}
+	if len(host) > 253 {
+		return "不能超过253个字符"
+	}
+	for _, label := range strings.Split(host, ".") {
+		if msg := checkLabel(label); msg != "" {
+			return "不是合法的域名: " + msg
+		}
+	}
+	return ""
+}
+
+// checkLabel 校验域名中的一段，允许下划线(如 _dmarc)和非ASCII字符(国际化域名)
+func checkLabel(label string) string {
+	if label == "" {
+		return "不能包含连续的点"
+	}
+	if len(label) > 63 {
+		return "每一段不能超过63个字符: " + label
+	}
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return "不能以连字符开头或结尾: " + label
+	}
+	for _, r := range label {
+		if r == '-' || r == '_' || r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			continue
+		}
+		if r >= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)) {
+			continue
+		}
+		return "包含非法字符: " + label
+	}
+	return ""
+}
+
+// checkTXT 校验TXT记录值
+//
+// 服务商会将超过255字节的值拆分为多个字符串；已按 "..." "..." 形式拆分的值，每个字符串不能超过255字节
+func checkTXT(value string, maxLength int) string {
+	if maxLength > 0 && len(value) > maxLength {
+		return "TXT记录的值不能超过" + strconv.Itoa(maxLength) + "字节"
+	}
+	for _, r := range value {
+		if r < 0x20 && r != '\t' {
+			return "TXT记录的值不能包含控制字符"
+		}
+	}
+
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, `"`) {
+		return ""
+	}
+	var chunk strings.Builder
+	inQuote, escaped := false, false
+	for _, r := range trimmed {
+		switch {
+		case escaped:
+			chunk.WriteRune(r)
+			escaped = false
+		case r == '\\' && inQuote:
+			escaped = true
+		case r == '"':
+			if inQuote && chunk.Len() > 255 {
+				return "TXT记录的每个字符串不能超过255字节"
+			}
+			chunk.Reset()
+			inQuote = !inQuote
+		case inQuote:
+			chunk.WriteRune(r)
+		case r != ' ' && r != '\t':
+			return "TXT记录的多个字符串之间只能有空格"
+		}
+	}
+	if inQuote {
+		return "TXT记录的引号不匹配"
+	}
+	return ""
+}
+
+// checkSRV 校验SRV记录值，格式为 优先级 权重 端口 目标
+func checkSRV(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) != 4 {
+		return "SRV记录的值格式为 优先级 权重 端口 目标，如 10 5 5060 sip.example.com"
+	}
+	names := []string{"优先级", "权重", "端口"}
+	for i, name := range names {
+		n, err := strconv.Atoi(fields[i])
+		if err != nil || n < 0 || n > 65535 {
+			return "SRV记录的" + name + "必须是0到65535之间的整数"
+		}
+	}
+	if fields[3] == "." {
+		return "" // 目标为 . 表示服务不可用
+	}
+	if msg := checkHostname(fields[3]); msg != "" {
+		return "SRV记录的目标" + msg
+	}
+	return ""
+}
+
+// checkCAA 校验CAA记录值，格式为 标志 标签 值，如 0 issue "letsencrypt.org"
+func checkCAA(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) < 3 {
+		return `CAA记录的值格式为 标志 标签 值，如 0 issue "letsencrypt.org"`
+	}
+	flags, err := strconv.Atoi(fields[0])
+	if err != nil || flags < 0 || flags > 255 {
+		return "CAA记录的标志必须是0到255之间的整数"
+	}
+	tag := fields[1]
+	for _, r := range tag {
+		if r >= unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return "CAA记录的标签只能包含字母和数字"
+		}
+	}
+	switch strings.ToLower(tag) {
+	case "issue", "issuewild", "iodef", "issuemail", "issuevmc", "contactemail", "contactphone":
+	default:
+		return "不支持的CAA标签: " + tag
+	}
+	caaValue := strings.TrimSpace(strings.SplitN(value, tag, 2)[1])
+	if strings.HasPrefix(caaValue, `"`) && (len(caaValue) < 2 || !strings.HasSuffix(caaValue, `"`)) {
+		return "CAA记录的值引号不匹配"
+	}
+	return ""
+}
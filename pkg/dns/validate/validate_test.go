@@ -0,0 +1,105 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+)
+
+// errorFields 校验错误涉及的字段，没有错误时返回nil
+func errorFields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("校验错误的类型为 %T: %v", err, err)
+	}
+	if !errors.Is(err, dns.ErrInvalidParam) {
+		t.Errorf("校验错误应属于ErrInvalidParam: %v", err)
+	}
+	fields := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		fields = append(fields, fe.Field)
+	}
+	return fields
+}
+
+func TestLimitsFor(t *testing.T) {
+	if got := LimitsFor(dns.ProviderDnsPod); got.MaxTTL != 604800 || got.MaxPriority != 20 {
+		t.Errorf("DNSPod的限制 = %+v", got)
+	}
+	if got := LimitsFor(dns.ProviderCloudflare); got.AutoTTL != 1 || got.MinTTL != 60 {
+		t.Errorf("Cloudflare的限制 = %+v", got)
+	}
+	for _, providerType := range []string{"", "unknown"} {
+		if got := LimitsFor(providerType); got != defaultLimits {
+			t.Errorf("LimitsFor(%q) = %+v, 期望协议本身的限制", providerType, got)
+		}
+	}
+}
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		record   dns.Record
+		fields   []string // 期望出错的字段，nil表示校验通过
+	}{
+		{"A记录", dns.ProviderDnsPod, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600}, nil},
+		{"A记录的值为IPv6", dns.ProviderDnsPod, dns.Record{Name: "www", Type: "A", Value: "2001:db8::1", TTL: 600}, []string{"value"}},
+		{"A记录的值为IPv4映射的IPv6", "", dns.Record{Name: "www", Type: "A", Value: "::ffff:192.0.2.1", TTL: 600}, []string{"value"}},
+		{"AAAA记录", dns.ProviderDnsPod, dns.Record{Name: "www", Type: "AAAA", Value: "2001:db8::1", TTL: 600}, nil},
+		{"AAAA记录的值为IPv4", dns.ProviderDnsPod, dns.Record{Name: "www", Type: "AAAA", Value: "192.0.2.1", TTL: 600}, []string{"value"}},
+		{"记录类型小写", "", dns.Record{Name: "www", Type: "a", Value: "192.0.2.1", TTL: 600}, nil},
+		{"CNAME记录", dns.ProviderAliyun, dns.Record{Name: "blog", Type: "CNAME", Value: "www.example.com.", TTL: 600}, nil},
+		{"CNAME记录的值为IP", dns.ProviderAliyun, dns.Record{Name: "blog", Type: "CNAME", Value: "192.0.2.1", TTL: 600}, []string{"value"}},
+		{"CNAME记录的值有连续的点", dns.ProviderAliyun, dns.Record{Name: "blog", Type: "CNAME", Value: "www..example.com", TTL: 600}, []string{"value"}},
+		{"MX记录", dns.ProviderDnsPod, dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 10, TTL: 600}, nil},
+		{"MX优先级为0表示未指定", dns.ProviderDnsPod, dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", TTL: 600}, nil},
+		{"MX优先级超出DNSPod范围", dns.ProviderDnsPod, dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 30, TTL: 600}, []string{"priority"}},
+		{"MX优先级在阿里云范围内", dns.ProviderAliyun, dns.Record{Name: "@", Type: "MX", Value: "mail.example.com", Priority: 30, TTL: 600}, nil},
+		{"优先级为负数", "", dns.Record{Name: "@", Type: "TXT", Value: "hello", Priority: -1, TTL: 600}, []string{"priority"}},
+		{"TXT记录", dns.ProviderAliyun, dns.Record{Name: "@", Type: "TXT", Value: "v=spf1 -all", TTL: 600}, nil},
+		{"TXT记录超过服务商上限", dns.ProviderAliyun, dns.Record{Name: "@", Type: "TXT", Value: strings.Repeat("a", 513), TTL: 600}, []string{"value"}},
+		{"TXT记录不受协议限制", "", dns.Record{Name: "@", Type: "TXT", Value: strings.Repeat("a", 1000), TTL: 600}, nil},
+		{"TXT记录拆分的字符串", "", dns.Record{Name: "@", Type: "TXT", Value: `"` + strings.Repeat("a", 255) + `" "b"`, TTL: 600}, nil},
+		{"TXT记录单个字符串超过255字节", "", dns.Record{Name: "@", Type: "TXT", Value: `"` + strings.Repeat("a", 256) + `" "b"`, TTL: 600}, []string{"value"}},
+		{"TXT记录引号不匹配", "", dns.Record{Name: "@", Type: "TXT", Value: `"abc`, TTL: 600}, []string{"value"}},
+		{"TXT记录包含控制字符", "", dns.Record{Name: "@", Type: "TXT", Value: "a\nb", TTL: 600}, []string{"value"}},
+		{"SRV记录", "", dns.Record{Name: "_sip._tcp", Type: "SRV", Value: "10 5 5060 sip.example.com", TTL: 600}, nil},
+		{"SRV记录端口超出范围", "", dns.Record{Name: "_sip._tcp", Type: "SRV", Value: "10 5 70000 sip.example.com", TTL: 600}, []string{"value"}},
+		{"SRV记录的主机记录", "", dns.Record{Name: "sip", Type: "SRV", Value: "10 5 5060 sip.example.com", TTL: 600}, []string{"name"}},
+		{"CAA记录", "", dns.Record{Name: "@", Type: "CAA", Value: `0 issue "letsencrypt.org"`, TTL: 600}, nil},
+		{"CAA记录的标签不支持", "", dns.Record{Name: "@", Type: "CAA", Value: `0 foo "bar"`, TTL: 600}, []string{"value"}},
+		{"不认识的记录类型只校验名称和TTL", dns.ProviderDnsPod, dns.Record{Name: "go", Type: "显性URL", Value: "https://example.com", TTL: 600}, nil},
+		{"泛解析", "", dns.Record{Name: "*.dev", Type: "A", Value: "192.0.2.1", TTL: 600}, nil},
+		{"国际化主机记录", "", dns.Record{Name: "中文", Type: "A", Value: "192.0.2.1", TTL: 600}, nil},
+		{"主机记录包含非法字符", "", dns.Record{Name: "a b", Type: "A", Value: "192.0.2.1", TTL: 600}, []string{"name"}},
+		{"主机记录以连字符开头", "", dns.Record{Name: "-www", Type: "A", Value: "192.0.2.1", TTL: 600}, []string{"name"}},
+		{"缺少全部字段", "", dns.Record{TTL: 600}, []string{"type", "name", "value"}},
+		{"TTL超过DNSPod上限", dns.ProviderDnsPod, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 604801}, []string{"ttl"}},
+		{"TTL低于Cloudflare下限", dns.ProviderCloudflare, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 30}, []string{"ttl"}},
+		{"Cloudflare自动TTL", dns.ProviderCloudflare, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 1}, nil},
+		{"其他服务商TTL为1", dns.ProviderDnsPod, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 1}, nil},
+		{"Route 53的TTL为0", dns.ProviderRoute53, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 0}, nil},
+		{"权重", dns.ProviderDnsPod, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Weight: 100}, nil},
+		{"权重超过上限", dns.ProviderDnsPod, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Weight: 101}, []string{"weight"}},
+		{"Route 53的权重上限", dns.ProviderRoute53, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Weight: 255}, nil},
+		{"Cloudflare不支持权重", dns.ProviderCloudflare, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Weight: 1}, []string{"weight"}},
+		{"权重为负数", dns.ProviderDnsPod, dns.Record{Name: "www", Type: "A", Value: "192.0.2.1", TTL: 600, Weight: -1}, []string{"weight"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorFields(t, Record(tt.provider, tt.record))
+			if !reflect.DeepEqual(got, tt.fields) {
+				t.Errorf("出错的字段 = %v, 期望 %v", got, tt.fields)
+			}
+		})
+	}
+}
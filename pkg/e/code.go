@@ -13,4 +13,15 @@ const (
 	ERROR_AUTH_CHECK_TOKEN_TIMEOUT = 20002
 	ERROR_AUTH_TOKEN               = 20003
 	ERROR_AUTH                     = 20004
+
+	ERROR_DNS_TIMEOUT        = 30001
+	ERROR_DNS_CANCELED       = 30002
+	ERROR_DNS_NOT_FOUND      = 30003
+	ERROR_DNS_ALREADY_EXISTS = 30004
+	ERROR_DNS_CONFLICT       = 30005
+	ERROR_DNS_AUTH_FAILED    = 30006
+	ERROR_DNS_THROTTLED      = 30007
+	ERROR_DNS_INVALID_PARAM  = 30008
+	ERROR_DNS_QUOTA_EXCEEDED = 30009
+	ERROR_DNS_UNSUPPORTED    = 30010
 )
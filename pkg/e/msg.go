@@ -11,6 +11,16 @@ var MsgFlags = map[int]string{
 	ERROR_AUTH_CHECK_TOKEN_TIMEOUT: "Token已超时",
 	ERROR_AUTH_TOKEN:               "Token生成失败",
 	ERROR_AUTH:                     "Token错误",
+	ERROR_DNS_TIMEOUT:              "DNS服务商请求超时",
+	ERROR_DNS_CANCELED:             "请求已取消",
+	ERROR_DNS_NOT_FOUND:            "域名或DNS记录不存在",
+	ERROR_DNS_ALREADY_EXISTS:       "DNS记录已经存在",
+	ERROR_DNS_CONFLICT:             "与现有DNS记录冲突",
+	ERROR_DNS_AUTH_FAILED:          "DNS服务商认证失败",
+	ERROR_DNS_THROTTLED:            "DNS服务商请求过于频繁",
+	ERROR_DNS_INVALID_PARAM:        "DNS记录参数错误",
+	ERROR_DNS_QUOTA_EXCEEDED:       "超出DNS服务商配额",
+	ERROR_DNS_UNSUPPORTED:          "DNS服务商不支持该操作",
 }
 
 func GetMsg(code int) string {
@@ -2,6 +2,9 @@ package setting
 
 import (
 	"log"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/go-ini/ini"
@@ -23,15 +26,35 @@ var (
 	DnsPodToken string
 	DomainName  string
 
+	// 腾讯云DNSPod API 3.0配置
+	TencentCloudSecretId  string
+	TencentCloudSecretKey string
+
 	// 阿里云DNS配置
 	AliyunAccessKeyId     string
 	AliyunAccessKeySecret string
 	AliyunRegionId        string
+
+	// DNS服务商账号配置
+	DnsProviders       []DnsProvider
+	DnsDefaultProvider string
+
+	// 定时检查数据库记录与服务商是否一致的间隔，0表示不检查
+	DnsDriftCheckInterval time.Duration
+	// /dns/records_db接口默认是否写透到服务商，请求中的write_through参数优先
+	DnsWriteThrough bool
 )
 
+// DnsProvider DNS服务商账号，对应[provider.<name>]配置段
+type DnsProvider struct {
+	Name    string
+	Type    string
+	Options map[string]string
+}
+
 func init() {
 	var err error
-	Cfg, err = ini.Load("conf/app.ini")
+	Cfg, err = ini.Load(configFile())
 	if err != nil {
 		log.Fatalf("Fail to parse 'conf/app.ini': %v", err)
 	}
@@ -41,6 +64,27 @@ func init() {
 	LoadApp()
 	LoadDns()
 	LoadAliyunDns()
+	LoadDnsProviders()
+}
+
+// configFile 配置文件路径，当前目录下没有conf/app.ini时向上级目录查找，便于在包目录中运行测试
+func configFile() string {
+	const name = "conf/app.ini"
+	dir, err := os.Getwd()
+	if err != nil {
+		return name
+	}
+	for {
+		file := filepath.Join(dir, name)
+		if _, err := os.Stat(file); err == nil {
+			return file
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return name
+		}
+		dir = parent
+	}
 }
 
 func LoadBase() {
@@ -76,6 +120,11 @@ func LoadDns() {
 
 	DnsPodToken = sec.Key("DNSPOD_TOKEN").MustString("")
 	DomainName = sec.Key("DOMAIN_NAME").MustString("")
+	DnsDefaultProvider = sec.Key("DEFAULT_PROVIDER").MustString("dns_pod")
+	DnsDriftCheckInterval = time.Duration(sec.Key("DRIFT_CHECK_INTERVAL").MustInt(0)) * time.Minute
+	DnsWriteThrough = sec.Key("WRITE_THROUGH").MustBool(false)
+	TencentCloudSecretId = sec.Key("TENCENTCLOUD_SECRET_ID").MustString("")
+	TencentCloudSecretKey = sec.Key("TENCENTCLOUD_SECRET_KEY").MustString("")
 }
 
 func LoadAliyunDns() {
@@ -88,3 +137,62 @@ func LoadAliyunDns() {
 	AliyunAccessKeySecret = sec.Key("ALIYUN_ACCESS_KEY_SECRET").MustString("")
 	AliyunRegionId = sec.Key("ALIYUN_REGION_ID").MustString("cn-hangzhou")
 }
+
+// LoadDnsProviders 加载[provider.*]配置段中的服务商账号
+//
+// [dns]和[aliyun_dns]中的配置分别注册为dns_pod、tencentcloud和aliyun账号，同名的[provider.*]优先
+func LoadDnsProviders() {
+	DnsProviders = nil
+	names := make(map[string]bool)
+
+	for _, sec := range Cfg.Sections() {
+		if !strings.HasPrefix(sec.Name(), "provider.") {
+			continue
+		}
+
+		name := strings.TrimPrefix(sec.Name(), "provider.")
+		typ := sec.Key("TYPE").MustString("")
+		if name == "" || typ == "" {
+			log.Fatalf("Fail to load section '%s': TYPE is required", sec.Name())
+		}
+
+		options := make(map[string]string)
+		for _, key := range sec.Keys() {
+			options[strings.ToUpper(key.Name())] = key.String()
+		}
+
+		DnsProviders = append(DnsProviders, DnsProvider{Name: name, Type: typ, Options: options})
+		names[name] = true
+	}
+
+	if DnsPodToken != "" && !names["dns_pod"] {
+		DnsProviders = append(DnsProviders, DnsProvider{
+			Name:    "dns_pod",
+			Type:    "dns_pod",
+			Options: map[string]string{"TOKEN": DnsPodToken},
+		})
+	}
+
+	if TencentCloudSecretId != "" && !names["tencentcloud"] {
+		DnsProviders = append(DnsProviders, DnsProvider{
+			Name: "tencentcloud",
+			Type: "tencentcloud",
+			Options: map[string]string{
+				"SECRET_ID":  TencentCloudSecretId,
+				"SECRET_KEY": TencentCloudSecretKey,
+			},
+		})
+	}
+
+	if AliyunAccessKeyId != "" && !names["aliyun"] {
+		DnsProviders = append(DnsProviders, DnsProvider{
+			Name: "aliyun",
+			Type: "aliyun",
+			Options: map[string]string{
+				"ACCESS_KEY_ID":     AliyunAccessKeyId,
+				"ACCESS_KEY_SECRET": AliyunAccessKeySecret,
+				"REGION_ID":         AliyunRegionId,
+			},
+		})
+	}
+}
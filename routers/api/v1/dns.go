@@ -1,73 +1,73 @@
 package v1
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/EDDYCJY/go-gin-example/models"
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+	"github.com/EDDYCJY/go-gin-example/pkg/dns/validate"
 	"github.com/EDDYCJY/go-gin-example/pkg/e"
 	"github.com/EDDYCJY/go-gin-example/pkg/setting"
 	"github.com/gin-gonic/gin"
 )
 
+// 获取已配置的DNS服务商账号
+func GetDnsProviders(c *gin.Context) {
+	dnsService := models.NewDnsService()
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "success",
+		"data": map[string]interface{}{
+			"lists":   dnsService.GetAccounts(),
+			"default": dnsService.Manager.DefaultName(),
+		},
+	})
+}
+
 // 获取域名列表
 func GetDomains(c *gin.Context) {
-	// 检查配置中的Token是否设置
-	if setting.DnsPodToken == "" && setting.AliyunAccessKeyId == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "DNSPod Token或阿里云AccessKey未配置",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
+	provider := c.Query("provider")
+	keyword := c.Query("keyword")
 
 	dnsService := models.NewDnsService()
 
-	// 检查使用哪个提供商
-	provider := c.Query("provider")
-	if provider == "aliyun" && dnsService.Manager.UseAliyunDns() {
-		// 阿里云域名列表需要特殊处理
-		pageNumber := 1
-		pageSize := 20
-		if pageStr := c.Query("page"); pageStr != "" {
-			if p, err := strconv.Atoi(pageStr); err == nil && p > 0 {
-				pageNumber = p
-			}
-		}
-		if sizeStr := c.Query("size"); sizeStr != "" {
-			if s, err := strconv.Atoi(sizeStr); err == nil && s > 0 {
-				pageSize = s
-			}
-		}
-
-		// 使用阿里云DNS获取域名列表
-		_, err := dnsService.Manager.GetAliyunDomainList(pageNumber, pageSize)
+	// 传入page或keyword时按页返回，否则返回全部域名
+	if c.Query("page") != "" || keyword != "" {
+		page, pageSize := dnsPageParams(c, maxDnsDomainPageSize)
+		domains, total, err := dnsService.GetDomainPage(c.Request.Context(), keyword, page, pageSize, provider)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": e.ERROR,
+			status, code := dnsErrorStatus(err)
+			c.JSON(status, gin.H{
+				"code": code,
 				"msg":  err.Error(),
-				"data": make(map[string]interface{}),
+				"data": dnsErrorData(err),
 			})
 			return
 		}
 
-		// 暂时返回空列表，因为阿里云域名结构与DNSPod不同
 		c.JSON(http.StatusOK, gin.H{
 			"code": e.SUCCESS,
 			"msg":  "success",
-			"data": make([]interface{}, 0),
+			"data": map[string]interface{}{
+				"lists":     domains,
+				"total":     total,
+				"page":      page,
+				"page_size": pageSize,
+			},
 		})
 		return
 	}
 
-	// 使用DNSPod
-	domains, err := dnsService.GetDomainList()
+	domains, err := dnsService.GetDomainList(c.Request.Context(), provider)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
-			"data": make(map[string]interface{}),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -79,18 +79,14 @@ func GetDomains(c *gin.Context) {
 	})
 }
 
+// 按页获取服务商数据时每页的最大数量，与阿里云的上限一致
+const (
+	maxDnsPageSize       = 500
+	maxDnsDomainPageSize = 100
+)
+
 // 获取DNS记录列表
 func GetDnsRecords(c *gin.Context) {
-	// 检查配置中的Token是否设置
-	if setting.DnsPodToken == "" && setting.AliyunAccessKeyId == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "DNSPod Token或阿里云AccessKey未配置",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
-
 	provider := c.Query("provider")
 	domain := c.Query("domain")
 	if domain == "" {
@@ -109,14 +105,16 @@ func GetDnsRecords(c *gin.Context) {
 
 	dnsService := models.NewDnsService()
 
-	if provider == "aliyun" && dnsService.Manager.UseAliyunDns() {
-		// 使用阿里云DNS
-		records, err := dnsService.GetAliyunRecordList(domain, subDomain)
+	// 传入page时按页返回，否则返回全部记录
+	if c.Query("page") != "" {
+		page, pageSize := dnsPageParams(c, maxDnsPageSize)
+		records, total, err := dnsService.GetRecordPage(c.Request.Context(), domain, subDomain, page, pageSize, provider)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": e.ERROR,
+			status, code := dnsErrorStatus(err)
+			c.JSON(status, gin.H{
+				"code": code,
 				"msg":  err.Error(),
-				"data": make(map[string]interface{}),
+				"data": dnsErrorData(err),
 			})
 			return
 		}
@@ -124,18 +122,23 @@ func GetDnsRecords(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"code": e.SUCCESS,
 			"msg":  "success",
-			"data": records,
+			"data": map[string]interface{}{
+				"lists":     records,
+				"total":     total,
+				"page":      page,
+				"page_size": pageSize,
+			},
 		})
 		return
 	}
 
-	// 使用DNSPod
-	records, err := dnsService.GetRecordList(domain, subDomain, provider)
+	records, err := dnsService.GetRecordList(c.Request.Context(), domain, subDomain, provider)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
-			"data": make(map[string]interface{}),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -147,18 +150,78 @@ func GetDnsRecords(c *gin.Context) {
 	})
 }
 
-// 创建DNS记录
-func CreateDnsRecord(c *gin.Context) {
-	// 检查配置中的Token是否设置
-	if setting.DnsPodToken == "" && setting.AliyunAccessKeyId == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "DNSPod Token或阿里云AccessKey未配置",
+// 获取域名可用的解析线路
+func GetDnsLines(c *gin.Context) {
+	provider := c.Query("provider")
+	domain := c.Query("domain")
+	if domain == "" {
+		domain = setting.DomainName // 使用配置中的默认域名
+	}
+
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "域名不能为空",
 			"data": make(map[string]interface{}),
 		})
 		return
 	}
 
+	dnsService := models.NewDnsService()
+	lines, err := dnsService.GetLineList(c.Request.Context(), domain, provider)
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "success",
+		"data": lines,
+	})
+}
+
+// 获取单条DNS记录
+func GetDnsRecord(c *gin.Context) {
+	provider := c.Query("provider")
+	recordID := c.Param("id")
+	domainID := c.Query("domain_id")
+
+	if recordID == "" || domainID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "参数不完整",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	dnsService := models.NewDnsService()
+	record, err := dnsService.GetRecord(c.Request.Context(), recordID, domainID, provider)
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "success",
+		"data": record,
+	})
+}
+
+// 创建DNS记录
+func CreateDnsRecord(c *gin.Context) {
 	provider := c.Query("provider")
 	domainID := c.Query("domain_id")
 	subDomain := c.Query("sub_domain")
@@ -170,6 +233,12 @@ func CreateDnsRecord(c *gin.Context) {
 	if err != nil {
 		ttl = 600 // 默认TTL
 	}
+	mx, _ := strconv.ParseInt(c.Query("mx"), 10, 64)         // MX优先级，仅MX记录有效
+	weight, _ := strconv.ParseInt(c.Query("weight"), 10, 64) // 权重，0表示不设置
+	remark := c.Query("remark")
+	proxied, _ := strconv.ParseBool(c.Query("proxied")) // 仅Cloudflare有效
+	setIdentifier := c.Query("set_identifier")          // 仅Route 53加权/延迟路由有效
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))  // 只校验和检查冲突，不提交
 
 	if domainID == "" || subDomain == "" || recordType == "" || value == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -181,34 +250,46 @@ func CreateDnsRecord(c *gin.Context) {
 	}
 
 	dnsService := models.NewDnsService()
+	proposed := dns.Record{
+		Name:     subDomain,
+		Type:     recordType,
+		Value:    value,
+		TTL:      ttl,
+		Priority: mx,
+		Weight:   weight,
+		Line:     recordLine,
+		Remark:   remark,
+		Proxied:  proxied,
+
+		SetIdentifier: setIdentifier,
+	}
 
-	if provider == "aliyun" && dnsService.Manager.UseAliyunDns() {
-		// 使用阿里云DNS
-		record, err := dnsService.CreateAliyunRecord(domainID, subDomain, recordType, value, ttl)
+	if dryRun {
+		check, err := dnsService.CheckRecord(c.Request.Context(), domainID, proposed, provider)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": e.ERROR,
+			status, code := dnsErrorStatus(err)
+			c.JSON(status, gin.H{
+				"code": code,
 				"msg":  err.Error(),
-				"data": make(map[string]interface{}),
+				"data": dnsErrorData(err),
 			})
 			return
 		}
-
 		c.JSON(http.StatusOK, gin.H{
 			"code": e.SUCCESS,
-			"msg":  "阿里云DNS记录创建成功",
-			"data": record,
+			"msg":  "检查完成，未提交到服务商",
+			"data": dnsDryRunData(check.Record, check.Conflicts),
 		})
 		return
 	}
 
-	// 使用DNSPod
-	record, err := dnsService.CreateRecord(domainID, subDomain, recordType, value, recordLine, provider)
+	record, err := dnsService.CreateRecord(c.Request.Context(), domainID, proposed, provider)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
-			"data": make(map[string]interface{}),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -222,16 +303,6 @@ func CreateDnsRecord(c *gin.Context) {
 
 // 更新DNS记录
 func UpdateDnsRecord(c *gin.Context) {
-	// 检查配置中的Token是否设置
-	if setting.DnsPodToken == "" && setting.AliyunAccessKeyId == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "DNSPod Token或阿里云AccessKey未配置",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
-
 	provider := c.Query("provider")
 	recordID := c.Param("id")
 	domainID := c.Query("domain_id")
@@ -244,6 +315,12 @@ func UpdateDnsRecord(c *gin.Context) {
 	if err != nil {
 		ttl = 600 // 默认TTL
 	}
+	mx, _ := strconv.ParseInt(c.Query("mx"), 10, 64)         // MX优先级，仅MX记录有效
+	weight, _ := strconv.ParseInt(c.Query("weight"), 10, 64) // 权重，0表示不设置
+	remark, hasRemark := c.GetQuery("remark")                // 未指定时不修改备注，remark=表示清除备注
+	proxied, _ := strconv.ParseBool(c.Query("proxied"))      // 仅Cloudflare有效
+	setIdentifier := c.Query("set_identifier")               // 仅Route 53加权/延迟路由有效
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))       // 只校验和检查冲突，不提交
 
 	if recordID == "" || domainID == "" || subDomain == "" || recordType == "" || value == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -255,34 +332,48 @@ func UpdateDnsRecord(c *gin.Context) {
 	}
 
 	dnsService := models.NewDnsService()
+	proposed := dns.Record{
+		ID:       recordID,
+		Name:     subDomain,
+		Type:     recordType,
+		Value:    value,
+		TTL:      ttl,
+		Priority: mx,
+		Weight:   weight,
+		Line:     recordLine,
+		Remark:   remark,
+		Proxied:  proxied,
+
+		SetIdentifier: setIdentifier,
+		RemarkSet:     hasRemark,
+	}
 
-	if provider == "aliyun" && dnsService.Manager.UseAliyunDns() {
-		// 使用阿里云DNS
-		record, err := dnsService.UpdateAliyunRecord(recordID, subDomain, recordType, value, ttl)
+	if dryRun {
+		check, err := dnsService.CheckRecord(c.Request.Context(), domainID, proposed, provider)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": e.ERROR,
+			status, code := dnsErrorStatus(err)
+			c.JSON(status, gin.H{
+				"code": code,
 				"msg":  err.Error(),
-				"data": make(map[string]interface{}),
+				"data": dnsErrorData(err),
 			})
 			return
 		}
-
 		c.JSON(http.StatusOK, gin.H{
 			"code": e.SUCCESS,
-			"msg":  "阿里云DNS记录更新成功",
-			"data": record,
+			"msg":  "检查完成，未提交到服务商",
+			"data": dnsDryRunData(check.Record, check.Conflicts),
 		})
 		return
 	}
 
-	// 使用DNSPod
-	record, err := dnsService.UpdateRecord(recordID, domainID, subDomain, recordType, value, recordLine, provider)
+	record, err := dnsService.UpdateRecord(c.Request.Context(), domainID, proposed, provider)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
-			"data": make(map[string]interface{}),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -296,16 +387,6 @@ func UpdateDnsRecord(c *gin.Context) {
 
 // 删除DNS记录
 func DeleteDnsRecord(c *gin.Context) {
-	// 检查配置中的Token是否设置
-	if setting.DnsPodToken == "" && setting.AliyunAccessKeyId == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "DNSPod Token或阿里云AccessKey未配置",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
-
 	provider := c.Query("provider")
 	recordID := c.Param("id")
 	domainID := c.Query("domain_id")
@@ -320,34 +401,13 @@ func DeleteDnsRecord(c *gin.Context) {
 	}
 
 	dnsService := models.NewDnsService()
-
-	if provider == "aliyun" && dnsService.Manager.UseAliyunDns() {
-		// 使用阿里云DNS
-		err := dnsService.DeleteAliyunRecord(recordID)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": e.ERROR,
-				"msg":  err.Error(),
-				"data": make(map[string]interface{}),
-			})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"code": e.SUCCESS,
-			"msg":  "阿里云DNS记录删除成功",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
-
-	// 使用DNSPod
-	err := dnsService.DeleteRecord(recordID, domainID, provider)
+	err := dnsService.DeleteRecord(c.Request.Context(), recordID, domainID, provider)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
-			"data": make(map[string]interface{}),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -361,16 +421,6 @@ func DeleteDnsRecord(c *gin.Context) {
 
 // 设置DNS记录状态
 func SetDnsRecordStatus(c *gin.Context) {
-	// 检查配置中的Token是否设置
-	if setting.DnsPodToken == "" && setting.AliyunAccessKeyId == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "DNSPod Token或阿里云AccessKey未配置",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
-
 	provider := c.Query("provider")
 	recordID := c.Param("id")
 	domainID := c.Query("domain_id")
@@ -386,7 +436,7 @@ func SetDnsRecordStatus(c *gin.Context) {
 	}
 
 	// 验证状态参数
-	if status != "enable" && status != "disable" {
+	if status != dns.RecordStatusEnable && status != dns.RecordStatusDisable {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": e.INVALID_PARAMS,
 			"msg":  "状态参数必须是enable或disable",
@@ -396,39 +446,13 @@ func SetDnsRecordStatus(c *gin.Context) {
 	}
 
 	dnsService := models.NewDnsService()
-
-	if provider == "aliyun" && dnsService.Manager.UseAliyunDns() {
-		// 使用阿里云DNS，需要转换状态
-		aliyunStatus := "ENABLE"
-		if status == "disable" {
-			aliyunStatus = "DISABLE"
-		}
-
-		err := dnsService.SetAliyunRecordStatus(recordID, aliyunStatus)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"code": e.ERROR,
-				"msg":  err.Error(),
-				"data": make(map[string]interface{}),
-			})
-			return
-		}
-
-		c.JSON(http.StatusOK, gin.H{
-			"code": e.SUCCESS,
-			"msg":  "阿里云DNS记录状态设置成功",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
-
-	// 使用DNSPod
-	err := dnsService.SetRecordStatus(recordID, domainID, status, provider)
+	record, err := dnsService.SetRecordStatus(c.Request.Context(), recordID, domainID, status, provider)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
-			"data": make(map[string]interface{}),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -436,6 +460,85 @@ func SetDnsRecordStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
 		"msg":  "DNS记录状态设置成功",
-		"data": make(map[string]interface{}),
+		"data": record,
 	})
 }
+
+// StatusClientClosedRequest 客户端在响应前断开连接，沿用nginx的499
+const StatusClientClosedRequest = 499
+
+// dnsErrorStatus 根据服务商调用的错误确定HTTP状态码和业务码
+//
+// 服务商认证失败是本服务的账号配置问题，返回502而不是401，以免与本服务的Token鉴权混淆
+func dnsErrorStatus(err error) (int, int) {
+	switch {
+	case errors.Is(err, dns.ErrTimeout):
+		return http.StatusGatewayTimeout, e.ERROR_DNS_TIMEOUT
+	case errors.Is(err, dns.ErrCanceled):
+		return StatusClientClosedRequest, e.ERROR_DNS_CANCELED
+	case errors.Is(err, dns.ErrNotFound):
+		return http.StatusNotFound, e.ERROR_DNS_NOT_FOUND
+	case errors.Is(err, dns.ErrAlreadyExists):
+		return http.StatusConflict, e.ERROR_DNS_ALREADY_EXISTS
+	case errors.Is(err, dns.ErrConflict):
+		return http.StatusConflict, e.ERROR_DNS_CONFLICT
+	case errors.Is(err, dns.ErrAuthFailed):
+		return http.StatusBadGateway, e.ERROR_DNS_AUTH_FAILED
+	case errors.Is(err, dns.ErrThrottled):
+		return http.StatusTooManyRequests, e.ERROR_DNS_THROTTLED
+	case errors.Is(err, dns.ErrInvalidParam):
+		return http.StatusBadRequest, e.ERROR_DNS_INVALID_PARAM
+	case errors.Is(err, dns.ErrQuotaExceeded):
+		return http.StatusForbidden, e.ERROR_DNS_QUOTA_EXCEEDED
+	case errors.Is(err, dns.ErrUnsupported):
+		return http.StatusNotImplemented, e.ERROR_DNS_UNSUPPORTED
+	}
+	return http.StatusInternalServerError, e.ERROR
+}
+
+// dnsErrorData 服务商返回的错误码和请求ID，便于向服务商排查问题；记录校验失败或冲突时返回各字段的错误和冲突的记录
+func dnsErrorData(err error) map[string]interface{} {
+	data := make(map[string]interface{})
+	var apiErr *dns.APIError
+	if errors.As(err, &apiErr) {
+		data["provider_code"] = apiErr.Code
+		data["request_id"] = apiErr.RequestID
+	}
+	var fieldErrs validate.Errors
+	if errors.As(err, &fieldErrs) {
+		data["errors"] = fieldErrs
+	}
+	var conflicts validate.Conflicts
+	if errors.As(err, &conflicts) {
+		data["conflicts"] = conflicts
+	}
+	return data
+}
+
+// dnsDryRunData 试运行的结果，valid为false表示提交时会因为冲突被拒绝
+func dnsDryRunData(record interface{}, conflicts validate.Conflicts) map[string]interface{} {
+	if conflicts == nil {
+		conflicts = validate.Conflicts{}
+	}
+	return map[string]interface{}{
+		"record":    record,
+		"conflicts": conflicts,
+		"valid":     conflicts.Err() == nil,
+	}
+}
+
+// dnsPageParams 解析page和page_size参数，page从1开始，page_size默认为PAGE_SIZE且不超过max
+func dnsPageParams(c *gin.Context, max int) (int, int) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = setting.PageSize
+	}
+	if pageSize > max {
+		pageSize = max
+	}
+	return page, pageSize
+}
@@ -2,32 +2,28 @@ package v1
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/EDDYCJY/go-gin-example/models"
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+	"github.com/EDDYCJY/go-gin-example/pkg/dns/validate"
 	"github.com/EDDYCJY/go-gin-example/pkg/e"
-	"github.com/EDDYCJY/go-gin-example/pkg/setting"
 	"github.com/gin-gonic/gin"
 )
 
 // 批量创建DNS记录
 func BatchCreateDnsRecords(c *gin.Context) {
 	provider := c.Query("provider")
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run")) // 只校验和检查冲突，不提交
 
-	// 检查配置中的Token是否设置
-	if provider == "dns_pod" && setting.DnsPodToken == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "DNSPod Token未配置",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
-
-	if provider == "aliyun" && (setting.AliyunAccessKeyId == "" || setting.AliyunAccessKeySecret == "") {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "阿里云AccessKey未配置",
-			"data": make(map[string]interface{}),
+	// 检查服务商是否已配置
+	dnsService := models.NewDnsService()
+	if _, err := dnsService.Manager.Provider(provider); err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -40,7 +36,12 @@ func BatchCreateDnsRecords(c *gin.Context) {
 		Value    string `json:"value"`
 		Line     string `json:"line"`
 		TTL      int64  `json:"ttl"`
+		Priority int64  `json:"priority"` // MX优先级
+		Weight   int64  `json:"weight"`
 		Remark   string `json:"remark"`
+		Proxied  bool   `json:"proxied"`
+
+		SetIdentifier string `json:"set_identifier"` // Route 53加权/延迟路由
 	}
 
 	if err := c.ShouldBindJSON(&records); err != nil {
@@ -61,10 +62,16 @@ func BatchCreateDnsRecords(c *gin.Context) {
 		return
 	}
 
-	dnsService := models.NewDnsService()
 	var results []map[string]interface{}
+	pending := make(map[string][]dns.Record) // 试运行时同一批次中已检查的记录，按域名分组
 
 	for _, record := range records {
+		// 客户端已断开连接，剩余的记录不再处理
+		if c.Request.Context().Err() != nil {
+			abortDnsBatch(c, results)
+			return
+		}
+
 		if record.Name == "" || record.Type == "" || record.Value == "" {
 			results = append(results, map[string]interface{}{
 				"success": false,
@@ -74,47 +81,50 @@ func BatchCreateDnsRecords(c *gin.Context) {
 			continue
 		}
 
+		ttl := record.TTL
+		if ttl == 0 {
+			ttl = 600 // 默认TTL
+		}
+		line := record.Line
+		if line == "" {
+			line = "默认"
+		}
+
 		var result map[string]interface{}
 
-		if provider == "aliyun" && dnsService.Manager.UseAliyunDns() {
-			// 使用阿里云DNS
-			ttl := record.TTL
-			if ttl == 0 {
-				ttl = 600 // 默认TTL
-			}
-			aliyunRecord, err := dnsService.CreateAliyunRecord(record.DomainID, record.Name, record.Type, record.Value, ttl)
-			if err != nil {
-				result = map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-					"name":    record.Name,
-				}
-			} else {
-				result = map[string]interface{}{
-					"success": true,
-					"data":    aliyunRecord,
-					"name":    record.Name,
-				}
+		proposed := dns.Record{
+			Name:     record.Name,
+			Type:     record.Type,
+			Value:    record.Value,
+			TTL:      ttl,
+			Priority: record.Priority,
+			Weight:   record.Weight,
+			Line:     line,
+			Remark:   record.Remark,
+			Proxied:  record.Proxied,
+
+			SetIdentifier: record.SetIdentifier,
+		}
+		if dryRun {
+			results = append(results, dnsBatchDryRun(c, dnsService, pending, record.DomainID, proposed, provider, "name", record.Name))
+			continue
+		}
+
+		created, err := dnsService.CreateRecord(c.Request.Context(), record.DomainID, proposed, provider)
+		if err != nil {
+			_, code := dnsErrorStatus(err)
+			result = map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+				"code":    code,
+				"name":    record.Name,
 			}
+			dnsBatchErrorDetails(result, err)
 		} else {
-			// 使用DNSPod (默认)
-			line := record.Line
-			if line == "" {
-				line = "默认"
-			}
-			dnsPodRecord, err := dnsService.CreateRecord(record.DomainID, record.Name, record.Type, record.Value, line, provider)
-			if err != nil {
-				result = map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-					"name":    record.Name,
-				}
-			} else {
-				result = map[string]interface{}{
-					"success": true,
-					"data":    dnsPodRecord,
-					"name":    record.Name,
-				}
+			result = map[string]interface{}{
+				"success": true,
+				"data":    created,
+				"name":    record.Name,
 			}
 		}
 
@@ -123,7 +133,7 @@ func BatchCreateDnsRecords(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
-		"msg":  "批量创建完成",
+		"msg":  dnsBatchMsg("批量创建完成", dryRun),
 		"data": map[string]interface{}{
 			"results": results,
 			"total":   len(results),
@@ -135,36 +145,35 @@ func BatchCreateDnsRecords(c *gin.Context) {
 // 批量更新DNS记录
 func BatchUpdateDnsRecords(c *gin.Context) {
 	provider := c.Query("provider")
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run")) // 只校验和检查冲突，不提交
 
-	// 检查配置中的Token是否设置
-	if provider == "dns_pod" && setting.DnsPodToken == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "DNSPod Token未配置",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
-
-	if provider == "aliyun" && (setting.AliyunAccessKeyId == "" || setting.AliyunAccessKeySecret == "") {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "阿里云AccessKey未配置",
-			"data": make(map[string]interface{}),
+	// 检查服务商是否已配置
+	dnsService := models.NewDnsService()
+	if _, err := dnsService.Manager.Provider(provider); err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
 
 	// 从请求体获取批量数据
 	var updates []struct {
-		ID       string `json:"id"`
-		DomainID string `json:"domain_id"`
-		Name     string `json:"name"`
-		Type     string `json:"type"`
-		Value    string `json:"value"`
-		Line     string `json:"line"`
-		TTL      int64  `json:"ttl"`
-		Remark   string `json:"remark"`
+		ID       string  `json:"id"`
+		DomainID string  `json:"domain_id"`
+		Name     string  `json:"name"`
+		Type     string  `json:"type"`
+		Value    string  `json:"value"`
+		Line     string  `json:"line"`
+		TTL      int64   `json:"ttl"`
+		Priority int64   `json:"priority"` // MX优先级
+		Weight   int64   `json:"weight"`
+		Remark   *string `json:"remark"` // 未指定时不修改备注，空字符串表示清除备注
+		Proxied  bool    `json:"proxied"`
+
+		SetIdentifier string `json:"set_identifier"` // Route 53加权/延迟路由
 	}
 
 	if err := c.ShouldBindJSON(&updates); err != nil {
@@ -185,10 +194,16 @@ func BatchUpdateDnsRecords(c *gin.Context) {
 		return
 	}
 
-	dnsService := models.NewDnsService()
 	var results []map[string]interface{}
+	pending := make(map[string][]dns.Record) // 试运行时同一批次中已检查的记录，按域名分组
 
 	for _, update := range updates {
+		// 客户端已断开连接，剩余的记录不再处理
+		if c.Request.Context().Err() != nil {
+			abortDnsBatch(c, results)
+			return
+		}
+
 		if update.ID == "" || update.Name == "" || update.Type == "" || update.Value == "" {
 			results = append(results, map[string]interface{}{
 				"success": false,
@@ -198,47 +213,54 @@ func BatchUpdateDnsRecords(c *gin.Context) {
 			continue
 		}
 
+		ttl := update.TTL
+		if ttl == 0 {
+			ttl = 600 // 默认TTL
+		}
+		line := update.Line
+		if line == "" {
+			line = "默认"
+		}
+
 		var result map[string]interface{}
 
-		if provider == "aliyun" && dnsService.Manager.UseAliyunDns() {
-			// 使用阿里云DNS
-			ttl := update.TTL
-			if ttl == 0 {
-				ttl = 600 // 默认TTL
-			}
-			aliyunRecord, err := dnsService.UpdateAliyunRecord(update.ID, update.Name, update.Type, update.Value, ttl)
-			if err != nil {
-				result = map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-					"id":      update.ID,
-				}
-			} else {
-				result = map[string]interface{}{
-					"success": true,
-					"data":    aliyunRecord,
-					"id":      update.ID,
-				}
+		proposed := dns.Record{
+			ID:       update.ID,
+			Name:     update.Name,
+			Type:     update.Type,
+			Value:    update.Value,
+			TTL:      ttl,
+			Priority: update.Priority,
+			Weight:   update.Weight,
+			Line:     line,
+			Proxied:  update.Proxied,
+
+			SetIdentifier: update.SetIdentifier,
+			RemarkSet:     update.Remark != nil,
+		}
+		if update.Remark != nil {
+			proposed.Remark = *update.Remark
+		}
+		if dryRun {
+			results = append(results, dnsBatchDryRun(c, dnsService, pending, update.DomainID, proposed, provider, "id", update.ID))
+			continue
+		}
+
+		updated, err := dnsService.UpdateRecord(c.Request.Context(), update.DomainID, proposed, provider)
+		if err != nil {
+			_, code := dnsErrorStatus(err)
+			result = map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+				"code":    code,
+				"id":      update.ID,
 			}
+			dnsBatchErrorDetails(result, err)
 		} else {
-			// 使用DNSPod (默认)
-			line := update.Line
-			if line == "" {
-				line = "默认"
-			}
-			dnsPodRecord, err := dnsService.UpdateRecord(update.ID, update.DomainID, update.Name, update.Type, update.Value, line, provider)
-			if err != nil {
-				result = map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-					"id":      update.ID,
-				}
-			} else {
-				result = map[string]interface{}{
-					"success": true,
-					"data":    dnsPodRecord,
-					"id":      update.ID,
-				}
+			result = map[string]interface{}{
+				"success": true,
+				"data":    updated,
+				"id":      update.ID,
 			}
 		}
 
@@ -247,7 +269,7 @@ func BatchUpdateDnsRecords(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": e.SUCCESS,
-		"msg":  "批量更新完成",
+		"msg":  dnsBatchMsg("批量更新完成", dryRun),
 		"data": map[string]interface{}{
 			"results": results,
 			"total":   len(results),
@@ -260,21 +282,14 @@ func BatchUpdateDnsRecords(c *gin.Context) {
 func BatchDeleteDnsRecords(c *gin.Context) {
 	provider := c.Query("provider")
 
-	// 检查配置中的Token是否设置
-	if provider == "dns_pod" && setting.DnsPodToken == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "DNSPod Token未配置",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
-
-	if provider == "aliyun" && (setting.AliyunAccessKeyId == "" || setting.AliyunAccessKeySecret == "") {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "阿里云AccessKey未配置",
-			"data": make(map[string]interface{}),
+	// 检查服务商是否已配置
+	dnsService := models.NewDnsService()
+	if _, err := dnsService.Manager.Provider(provider); err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -303,10 +318,15 @@ func BatchDeleteDnsRecords(c *gin.Context) {
 		return
 	}
 
-	dnsService := models.NewDnsService()
 	var results []map[string]interface{}
 
 	for _, delete := range deletes {
+		// 客户端已断开连接，剩余的记录不再处理
+		if c.Request.Context().Err() != nil {
+			abortDnsBatch(c, results)
+			return
+		}
+
 		if delete.ID == "" {
 			results = append(results, map[string]interface{}{
 				"success": false,
@@ -318,35 +338,19 @@ func BatchDeleteDnsRecords(c *gin.Context) {
 
 		var result map[string]interface{}
 
-		if provider == "aliyun" && dnsService.Manager.UseAliyunDns() {
-			// 使用阿里云DNS
-			err := dnsService.DeleteAliyunRecord(delete.ID)
-			if err != nil {
-				result = map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-					"id":      delete.ID,
-				}
-			} else {
-				result = map[string]interface{}{
-					"success": true,
-					"id":      delete.ID,
-				}
+		err := dnsService.DeleteRecord(c.Request.Context(), delete.ID, delete.DomainID, provider)
+		if err != nil {
+			_, code := dnsErrorStatus(err)
+			result = map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+				"code":    code,
+				"id":      delete.ID,
 			}
 		} else {
-			// 使用DNSPod (默认)
-			err := dnsService.DeleteRecord(delete.ID, delete.DomainID, provider)
-			if err != nil {
-				result = map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-					"id":      delete.ID,
-				}
-			} else {
-				result = map[string]interface{}{
-					"success": true,
-					"id":      delete.ID,
-				}
+			result = map[string]interface{}{
+				"success": true,
+				"id":      delete.ID,
 			}
 		}
 
@@ -369,7 +373,7 @@ func BatchUpdateDnsRecordStatus(c *gin.Context) {
 	provider := c.Query("provider")
 	status := c.Query("status")
 
-	if status != "enable" && status != "disable" {
+	if status != dns.RecordStatusEnable && status != dns.RecordStatusDisable {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": e.INVALID_PARAMS,
 			"msg":  "状态参数必须是enable或disable",
@@ -378,21 +382,14 @@ func BatchUpdateDnsRecordStatus(c *gin.Context) {
 		return
 	}
 
-	// 检查配置中的Token是否设置
-	if provider == "dns_pod" && setting.DnsPodToken == "" {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "DNSPod Token未配置",
-			"data": make(map[string]interface{}),
-		})
-		return
-	}
-
-	if provider == "aliyun" && (setting.AliyunAccessKeyId == "" || setting.AliyunAccessKeySecret == "") {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
-			"msg":  "阿里云AccessKey未配置",
-			"data": make(map[string]interface{}),
+	// 检查服务商是否已配置
+	dnsService := models.NewDnsService()
+	if _, err := dnsService.Manager.Provider(provider); err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -421,10 +418,15 @@ func BatchUpdateDnsRecordStatus(c *gin.Context) {
 		return
 	}
 
-	dnsService := models.NewDnsService()
 	var results []map[string]interface{}
 
 	for _, update := range statusUpdates {
+		// 客户端已断开连接，剩余的记录不再处理
+		if c.Request.Context().Err() != nil {
+			abortDnsBatch(c, results)
+			return
+		}
+
 		if update.ID == "" {
 			results = append(results, map[string]interface{}{
 				"success": false,
@@ -436,39 +438,20 @@ func BatchUpdateDnsRecordStatus(c *gin.Context) {
 
 		var result map[string]interface{}
 
-		if provider == "aliyun" && dnsService.Manager.UseAliyunDns() {
-			// 使用阿里云DNS，需要转换状态
-			aliyunStatus := "ENABLE"
-			if status == "disable" {
-				aliyunStatus = "DISABLE"
-			}
-			err := dnsService.SetAliyunRecordStatus(update.ID, aliyunStatus)
-			if err != nil {
-				result = map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-					"id":      update.ID,
-				}
-			} else {
-				result = map[string]interface{}{
-					"success": true,
-					"id":      update.ID,
-				}
+		record, err := dnsService.SetRecordStatus(c.Request.Context(), update.ID, update.DomainID, status, provider)
+		if err != nil {
+			_, code := dnsErrorStatus(err)
+			result = map[string]interface{}{
+				"success": false,
+				"error":   err.Error(),
+				"code":    code,
+				"id":      update.ID,
 			}
 		} else {
-			// 使用DNSPod (默认)
-			err := dnsService.SetRecordStatus(update.ID, update.DomainID, status, provider)
-			if err != nil {
-				result = map[string]interface{}{
-					"success": false,
-					"error":   err.Error(),
-					"id":      update.ID,
-				}
-			} else {
-				result = map[string]interface{}{
-					"success": true,
-					"id":      update.ID,
-				}
+			result = map[string]interface{}{
+				"success": true,
+				"data":    record,
+				"id":      update.ID,
 			}
 		}
 
@@ -486,6 +469,61 @@ func BatchUpdateDnsRecordStatus(c *gin.Context) {
 	})
 }
 
+// 辅助函数：试运行时检查一条记录，同一批次中先检查的记录也参与冲突检查
+func dnsBatchDryRun(c *gin.Context, dnsService *models.DnsService, pending map[string][]dns.Record, domainID string, proposed dns.Record, provider, key, value string) map[string]interface{} {
+	check, err := dnsService.CheckRecord(c.Request.Context(), domainID, proposed, provider)
+	if err != nil {
+		_, code := dnsErrorStatus(err)
+		result := map[string]interface{}{
+			"success": false,
+			"error":   err.Error(),
+			"code":    code,
+			key:       value,
+		}
+		dnsBatchErrorDetails(result, err)
+		return result
+	}
+
+	conflicts := append(check.Conflicts, validate.CheckConflicts(pending[domainID], check.Record)...)
+	pending[domainID] = append(pending[domainID], check.Record)
+	return map[string]interface{}{
+		"success": conflicts.Err() == nil,
+		"data":    dnsDryRunData(check.Record, conflicts),
+		key:       value,
+	}
+}
+
+// 辅助函数：记录校验失败或冲突时在结果中返回各字段的错误和冲突的记录
+func dnsBatchErrorDetails(result map[string]interface{}, err error) {
+	data := dnsErrorData(err)
+	for _, key := range []string{"errors", "conflicts"} {
+		if v, ok := data[key]; ok {
+			result[key] = v
+		}
+	}
+}
+
+// 辅助函数：试运行时在提示信息中注明未提交
+func dnsBatchMsg(msg string, dryRun bool) string {
+	if dryRun {
+		return msg + "（试运行，未提交到服务商）"
+	}
+	return msg
+}
+
+// 辅助函数：客户端断开连接时中止批量操作，返回已处理的结果
+func abortDnsBatch(c *gin.Context, results []map[string]interface{}) {
+	c.JSON(StatusClientClosedRequest, gin.H{
+		"code": e.ERROR_DNS_CANCELED,
+		"msg":  e.GetMsg(e.ERROR_DNS_CANCELED),
+		"data": map[string]interface{}{
+			"results": results,
+			"total":   len(results),
+			"success": countSuccess(results),
+		},
+	})
+}
+
 // 辅助函数：计算成功数量
 func countSuccess(results []map[string]interface{}) int {
 	count := 0
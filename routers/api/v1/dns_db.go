@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/EDDYCJY/go-gin-example/models"
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
 	"github.com/EDDYCJY/go-gin-example/pkg/e"
 	"github.com/EDDYCJY/go-gin-example/pkg/setting"
 	"github.com/EDDYCJY/go-gin-example/pkg/util"
@@ -16,7 +17,7 @@ func GetDnsDomains(c *gin.Context) {
 	name := c.Query("name")
 	maps := make(map[string]interface{})
 	if name != "" {
-		maps["name"] = name
+		maps["name"] = dns.ToUnicode(name) // 数据库中保存Unicode形式，也接受ASCII形式
 	}
 
 	page := util.GetPage(c)
@@ -83,8 +84,9 @@ func AddDnsDomain(c *gin.Context) {
 
 	err := models.AddDnsDomain(domain)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err) // 名称不是合法的域名时返回400
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
 			"data": make(map[string]interface{}),
 		})
@@ -152,8 +154,9 @@ func UpdateDnsDomain(c *gin.Context) {
 
 	err = models.UpdateDnsDomain(id, updateData)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err) // 名称不是合法的域名时返回400
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
 			"data": make(map[string]interface{}),
 		})
@@ -219,7 +222,7 @@ func GetDnsRecordsDb(c *gin.Context) {
 
 	name := c.Query("name")
 	if name != "" {
-		maps["name"] = name
+		maps["name"] = dns.ToUnicode(name)
 	}
 
 	recordType := c.Query("type")
@@ -276,6 +279,21 @@ func AddDnsRecordDb(c *gin.Context) {
 	recordType := c.Query("type")
 	value := c.Query("value")
 	provider := c.Query("provider")
+	writeThrough := dnsWriteThrough(c)
+
+	// 写透模式下使用域名所属的服务商账号
+	if writeThrough {
+		domain, err := models.GetDnsDomain(domainID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.ERROR,
+				"msg":  "域名不存在",
+				"data": make(map[string]interface{}),
+			})
+			return
+		}
+		provider = domain.Provider
+	}
 
 	if name == "" || recordType == "" || value == "" || provider == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
@@ -293,6 +311,8 @@ func AddDnsRecordDb(c *gin.Context) {
 	if err != nil {
 		ttl = 600
 	}
+	priority, _ := strconv.Atoi(c.Query("priority"))
+	weight, _ := strconv.Atoi(c.Query("weight"))
 	remark := c.Query("remark")
 	remoteID := c.Query("remote_id")
 
@@ -304,17 +324,38 @@ func AddDnsRecordDb(c *gin.Context) {
 		Status:   status,
 		Line:     line,
 		TTL:      ttl,
+		Priority: priority,
+		Weight:   weight,
 		Remark:   remark,
 		Provider: provider,
 		RemoteID: remoteID,
 	}
 
-	err = models.AddDnsRecord(record)
+	dnsService := models.NewDnsService()
+	if err := dnsService.ValidateRecord(record.Record(), provider); err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+	if !checkDnsRecordDbConflicts(c, record) {
+		return
+	}
+
+	if writeThrough {
+		err = dnsService.AddDnsRecordThrough(c.Request.Context(), record)
+	} else {
+		err = models.AddDnsRecord(record)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err) // 名称不是合法的域名时返回400
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
-			"data": make(map[string]interface{}),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -326,6 +367,49 @@ func AddDnsRecordDb(c *gin.Context) {
 	})
 }
 
+// dnsWriteThrough 是否写透到服务商，请求中的write_through参数优先于配置中的WRITE_THROUGH
+func dnsWriteThrough(c *gin.Context) bool {
+	if writeThrough, err := strconv.ParseBool(c.Query("write_through")); err == nil {
+		return writeThrough
+	}
+	return setting.DnsWriteThrough
+}
+
+// checkDnsRecordDbConflicts 检查记录与同一域名下其他记录的冲突
+//
+// dry_run为true时返回检查结果；有冲突时返回409。已写入响应时返回false
+func checkDnsRecordDbConflicts(c *gin.Context, record *models.DnsRecord) bool {
+	conflicts, err := models.CheckDnsRecordConflicts(record)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": make(map[string]interface{}),
+		})
+		return false
+	}
+
+	if dryRun, _ := strconv.ParseBool(c.Query("dry_run")); dryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"code": e.SUCCESS,
+			"msg":  "检查完成，未写入数据库",
+			"data": dnsDryRunData(record, conflicts),
+		})
+		return false
+	}
+
+	if err := conflicts.Err(); err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return false
+	}
+	return true
+}
+
 // 更新DNS解析记录（数据库）
 func UpdateDnsRecordDb(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
@@ -338,7 +422,8 @@ func UpdateDnsRecordDb(c *gin.Context) {
 		return
 	}
 
-	if !models.ExistDnsRecordByID(id) {
+	existing, err := models.GetDnsRecord(id)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code": e.ERROR,
 			"msg":  "DNS记录不存在",
@@ -354,7 +439,7 @@ func UpdateDnsRecordDb(c *gin.Context) {
 	status := c.Query("status")
 	line := c.Query("line")
 	ttlStr := c.Query("ttl")
-	remark := c.Query("remark")
+	remark, hasRemark := c.GetQuery("remark") // 未指定时不修改备注，remark=表示清除备注
 	provider := c.Query("provider")
 	remoteID := c.Query("remote_id")
 
@@ -384,7 +469,17 @@ func UpdateDnsRecordDb(c *gin.Context) {
 			updateData["ttl"] = ttl
 		}
 	}
-	if remark != "" {
+	if priorityStr := c.Query("priority"); priorityStr != "" {
+		if priority, err := strconv.Atoi(priorityStr); err == nil {
+			updateData["priority"] = priority
+		}
+	}
+	if weightStr := c.Query("weight"); weightStr != "" {
+		if weight, err := strconv.Atoi(weightStr); err == nil {
+			updateData["weight"] = weight
+		}
+	}
+	if hasRemark {
 		updateData["remark"] = remark
 	}
 	if provider != "" {
@@ -394,10 +489,90 @@ func UpdateDnsRecordDb(c *gin.Context) {
 		updateData["remote_id"] = remoteID
 	}
 
+	// 校验修改后的完整记录，未修改的字段使用数据库中的值
+	merged := *existing
+	if domainID, ok := updateData["domain_id"].(int); ok {
+		merged.DomainID = domainID
+	}
+	if name != "" {
+		merged.Name, merged.PunyCode = name, ""
+	}
+	if recordType != "" {
+		merged.Type = recordType
+	}
+	if value != "" {
+		merged.Value = value
+	}
+	if ttl, ok := updateData["ttl"].(int); ok {
+		merged.TTL = ttl
+	}
+	if priority, ok := updateData["priority"].(int); ok {
+		merged.Priority = priority
+	}
+	if weight, ok := updateData["weight"].(int); ok {
+		merged.Weight = weight
+	}
+	if line != "" {
+		merged.Line = line
+	}
+	if provider != "" {
+		merged.Provider = provider
+	}
+
+	// 写透模式下使用域名所属的服务商账号
+	writeThrough := dnsWriteThrough(c)
+	if writeThrough {
+		domain, err := models.GetDnsDomain(existing.DomainID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"code": e.ERROR,
+				"msg":  "域名不存在",
+				"data": make(map[string]interface{}),
+			})
+			return
+		}
+		merged.Provider = domain.Provider
+	}
+
+	dnsService := models.NewDnsService()
+	if err := dnsService.ValidateRecord(merged.Record(), merged.Provider); err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+	if !checkDnsRecordDbConflicts(c, &merged) {
+		return
+	}
+
+	if writeThrough {
+		updated, err := dnsService.UpdateDnsRecordThrough(c.Request.Context(), id, updateData)
+		if err != nil {
+			status, code := dnsErrorStatus(err)
+			c.JSON(status, gin.H{
+				"code": code,
+				"msg":  err.Error(),
+				"data": dnsErrorData(err),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"code": e.SUCCESS,
+			"msg":  "DNS记录更新成功",
+			"data": updated,
+		})
+		return
+	}
+
 	err = models.UpdateDnsRecord(id, updateData)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err) // 名称不是合法的域名时返回400
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
 			"data": make(map[string]interface{}),
 		})
@@ -432,12 +607,18 @@ func DeleteDnsRecordDb(c *gin.Context) {
 		return
 	}
 
-	err = models.DeleteDnsRecord(id)
+	if dnsWriteThrough(c) {
+		dnsService := models.NewDnsService()
+		err = dnsService.DeleteDnsRecordThrough(c.Request.Context(), id)
+	} else {
+		err = models.DeleteDnsRecord(id)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code": e.ERROR,
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
 			"msg":  err.Error(),
-			"data": make(map[string]interface{}),
+			"data": dnsErrorData(err),
 		})
 		return
 	}
@@ -0,0 +1,143 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/EDDYCJY/go-gin-example/models"
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+	"github.com/EDDYCJY/go-gin-example/pkg/e"
+	"github.com/gin-gonic/gin"
+)
+
+// 在服务商添加域名
+func CreateDomain(c *gin.Context) {
+	provider := c.Query("provider")
+	name := c.Query("name")
+	remark := c.Query("remark")
+
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "域名不能为空",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	dnsService := models.NewDnsService()
+	domain, err := dnsService.CreateDomain(c.Request.Context(), name, remark, provider)
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "域名添加成功",
+		"data": domain,
+	})
+}
+
+// 修改域名状态、备注或锁定状态
+func UpdateDomain(c *gin.Context) {
+	provider := c.Query("provider")
+	domainID := c.Param("id")
+	status := c.Query("status")
+	remark, hasRemark := c.GetQuery("remark")
+	lock := c.Query("lock")
+	lockCode := c.Query("lock_code")
+	lockDays, daysErr := strconv.Atoi(c.DefaultQuery("lock_days", "30"))
+
+	if status == "" && !hasRemark && lock == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "至少需要提供status、remark或lock中的一个",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	if status != "" && status != dns.RecordStatusEnable && status != dns.RecordStatusDisable {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "状态参数必须是enable或disable",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	locked, lockErr := strconv.ParseBool(lock)
+	if lock != "" && (lockErr != nil || (locked && (daysErr != nil || lockDays <= 0)) || (!locked && lockCode == "")) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "lock为true时lock_days必须大于0，为false时需要提供lock_code",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	dnsService := models.NewDnsService()
+	data := make(map[string]interface{})
+
+	var err error
+	if status != "" {
+		err = dnsService.SetDomainStatus(c.Request.Context(), domainID, status, provider)
+	}
+	if err == nil && hasRemark {
+		err = dnsService.SetDomainRemark(c.Request.Context(), domainID, remark, provider)
+	}
+	if err == nil && lock != "" {
+		if locked {
+			var code string
+			code, err = dnsService.LockDomain(c.Request.Context(), domainID, lockDays, provider)
+			data["lock_code"] = code
+		} else {
+			err = dnsService.UnlockDomain(c.Request.Context(), domainID, lockCode, provider)
+		}
+	}
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "域名修改成功",
+		"data": data,
+	})
+}
+
+// 在服务商删除域名
+func DeleteDomain(c *gin.Context) {
+	provider := c.Query("provider")
+	domainID := c.Param("id")
+
+	dnsService := models.NewDnsService()
+	err := dnsService.DeleteDomain(c.Request.Context(), domainID, provider)
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "域名删除成功",
+		"data": make(map[string]interface{}),
+	})
+}
@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/EDDYCJY/go-gin-example/models"
+	"github.com/EDDYCJY/go-gin-example/pkg/e"
+	"github.com/EDDYCJY/go-gin-example/pkg/setting"
+	"github.com/EDDYCJY/go-gin-example/pkg/util"
+	"github.com/gin-gonic/gin"
+)
+
+// 比较域名在数据库中的记录与服务商的记录
+func GetDnsDomainDrift(c *gin.Context) {
+	cached, _ := strconv.ParseBool(c.Query("cached")) // 返回最近一次的检查结果，不调用服务商
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "无效的域名ID",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	if cached {
+		drift, err := models.GetDnsDrift(id)
+		if gorm.IsRecordNotFoundError(err) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"code": e.ERROR,
+				"msg":  "该域名还没有检查结果",
+				"data": make(map[string]interface{}),
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"code": e.ERROR,
+				"msg":  err.Error(),
+				"data": make(map[string]interface{}),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"code": e.SUCCESS,
+			"msg":  "success",
+			"data": drift,
+		})
+		return
+	}
+
+	dnsService := models.NewDnsService()
+	report, err := dnsService.CheckDrift(c.Request.Context(), id)
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.ERROR,
+			"msg":  "域名不存在",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	msg := "数据库与服务商一致"
+	if !report.InSync {
+		msg = "数据库与服务商不一致"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  msg,
+		"data": report,
+	})
+}
+
+// 获取各域名最近一次的差异检查结果
+func GetDnsDrifts(c *gin.Context) {
+	maps := make(map[string]interface{})
+	if domainID, err := strconv.Atoi(c.Query("domain_id")); err == nil && domainID > 0 {
+		maps["domain_id"] = domainID
+	}
+	if inSync, err := strconv.ParseBool(c.Query("in_sync")); err == nil {
+		maps["in_sync"] = inSync
+	}
+
+	page := util.GetPage(c)
+	pageSize := setting.PageSize
+
+	drifts, err := models.GetDnsDriftList(page, pageSize, maps)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	total, err := models.GetDnsDriftTotal(maps)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "success",
+		"data": map[string]interface{}{
+			"lists": drifts,
+			"total": total,
+		},
+	})
+}
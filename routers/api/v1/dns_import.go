@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/EDDYCJY/go-gin-example/models"
+	"github.com/EDDYCJY/go-gin-example/pkg/e"
+	"github.com/gin-gonic/gin"
+)
+
+// 从服务商导入一个域名的解析记录到数据库
+func ImportDnsDomain(c *gin.Context) {
+	prune, _ := strconv.ParseBool(c.Query("prune")) // 删除服务商已没有的记录
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "无效的域名ID",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	dnsService := models.NewDnsService()
+	result, err := dnsService.ImportDomain(c.Request.Context(), id, prune)
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.ERROR,
+			"msg":  "域名不存在",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "导入成功",
+		"data": result,
+	})
+}
+
+// 从服务商导入账号下的全部域名和解析记录到数据库
+func ImportDnsDomains(c *gin.Context) {
+	provider := c.Query("provider")
+	prune, _ := strconv.ParseBool(c.Query("prune")) // 删除服务商已没有的记录
+
+	dnsService := models.NewDnsService()
+	result, err := dnsService.ImportAllDomains(c.Request.Context(), prune, provider)
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	msg := "导入成功"
+	if result.Failed > 0 {
+		msg = "部分域名导入失败"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  msg,
+		"data": result,
+	})
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/EDDYCJY/go-gin-example/models"
+	"github.com/EDDYCJY/go-gin-example/pkg/e"
+	"github.com/gin-gonic/gin"
+)
+
+// 将域名的记录从一个服务商账号迁移到另一个账号
+func CreateDnsMigration(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))             // 只转换和检查，不修改目标服务商
+	updateDomain, _ := strconv.ParseBool(c.Query("update_domain")) // 成功后更新dns_domains表
+
+	opts := models.MigrationOptions{
+		Domain:       c.Query("domain"),
+		Source:       c.Query("source"),
+		Target:       c.Query("target"),
+		DryRun:       dryRun,
+		UpdateDomain: updateDomain,
+	}
+	if opts.Domain == "" || opts.Target == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "domain和target不能为空",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	dnsService := models.NewDnsService()
+
+	// 试运行直接返回转换结果
+	if dryRun {
+		report, err := dnsService.Migrate(c.Request.Context(), opts)
+		if err != nil {
+			status, code := dnsErrorStatus(err)
+			c.JSON(status, gin.H{
+				"code": code,
+				"msg":  err.Error(),
+				"data": dnsErrorData(err),
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"code": e.SUCCESS,
+			"msg":  "检查完成，未修改目标服务商",
+			"data": report,
+		})
+		return
+	}
+
+	migration, err := dnsService.StartMigration(opts)
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "迁移任务已创建",
+		"data": migration,
+	})
+}
+
+// 获取迁移任务的状态和结果
+func GetDnsMigration(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "无效的迁移任务ID",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	migration, err := models.GetDnsMigration(id)
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.ERROR,
+			"msg":  "迁移任务不存在",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "success",
+		"data": migration,
+	})
+}
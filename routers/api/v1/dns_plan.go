@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jinzhu/gorm"
+
+	"github.com/EDDYCJY/go-gin-example/models"
+	"github.com/EDDYCJY/go-gin-example/pkg/e"
+	"github.com/gin-gonic/gin"
+)
+
+// 生成将服务商的记录同步为数据库记录的计划
+func PlanDnsDomain(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "无效的域名ID",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	dnsService := models.NewDnsService()
+	plan, err := dnsService.PlanDomain(c.Request.Context(), id)
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.ERROR,
+			"msg":  "域名不存在",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	msg := "计划已生成"
+	if len(plan.Ops) == 0 {
+		msg = "服务商与数据库一致，没有需要执行的操作"
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  msg,
+		"data": plan,
+	})
+}
+
+// 执行保存的计划
+func ApplyDnsPlan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "无效的域名ID",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+	planID, err := strconv.Atoi(c.Query("plan_id"))
+	if err != nil || planID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "plan_id不能为空",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	dnsService := models.NewDnsService()
+	plan, err := dnsService.ApplyPlan(c.Request.Context(), id, planID)
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.ERROR,
+			"msg":  "计划或域名不存在",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+	if err != nil {
+		status, code := dnsErrorStatus(err)
+		c.JSON(status, gin.H{
+			"code": code,
+			"msg":  err.Error(),
+			"data": dnsErrorData(err),
+		})
+		return
+	}
+
+	// 与批量接口相同，部分操作失败时返回200，由results中每个操作的success判断
+	msg := "计划执行成功"
+	if plan.Status != models.PlanStatusApplied {
+		msg = "计划执行失败，之后的操作未执行: " + plan.Error
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  msg,
+		"data": plan,
+	})
+}
+
+// 获取计划及其执行结果
+func GetDnsPlan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": e.INVALID_PARAMS,
+			"msg":  "无效的计划ID",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	plan, err := models.GetDnsPlan(id)
+	if gorm.IsRecordNotFoundError(err) {
+		c.JSON(http.StatusNotFound, gin.H{
+			"code": e.ERROR,
+			"msg":  "计划不存在",
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"code": e.ERROR,
+			"msg":  err.Error(),
+			"data": make(map[string]interface{}),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": e.SUCCESS,
+		"msg":  "success",
+		"data": plan,
+	})
+}
@@ -0,0 +1,422 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/EDDYCJY/go-gin-example/pkg/dns"
+	"github.com/EDDYCJY/go-gin-example/pkg/e"
+	"github.com/EDDYCJY/go-gin-example/pkg/setting"
+)
+
+// 测试使用模拟服务商，不需要网络和凭证：memory为正常账号，broken的写操作总是失败
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	setting.DnsProviders = []setting.DnsProvider{
+		{Name: "memory", Type: dns.ProviderMemory, Options: map[string]string{"DOMAINS": "example.com,example.org"}},
+		{Name: "broken", Type: dns.ProviderMemory, Options: map[string]string{"DOMAINS": "example.com", "FAIL_ON": "CreateRecord,DeleteRecord"}},
+	}
+	setting.DnsDefaultProvider = "memory"
+	setting.DomainName = ""
+	os.Exit(m.Run())
+}
+
+// dnsTestResponse 接口的统一响应
+type dnsTestResponse struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+func newDnsTestRouter() *gin.Engine {
+	r := gin.New()
+	r.GET("/dns/records", GetDnsRecords)
+	r.GET("/dns/records/:id", GetDnsRecord)
+	r.POST("/dns/records", CreateDnsRecord)
+	r.PUT("/dns/records/:id", UpdateDnsRecord)
+	r.DELETE("/dns/records/:id", DeleteDnsRecord)
+	r.PUT("/dns/records/:id/status", SetDnsRecordStatus)
+	r.POST("/dns/records/batch", BatchCreateDnsRecords)
+	r.DELETE("/dns/records/batch", BatchDeleteDnsRecords)
+	return r
+}
+
+// doDns 发起请求，query为查询参数，body不为nil时作为JSON请求体
+func doDns(t *testing.T, r *gin.Engine, method, path string, query url.Values, body interface{}) (int, dnsTestResponse) {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+
+	req := httptest.NewRequest(method, path+"?"+query.Encode(), reader)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp dnsTestResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s 返回的不是JSON: %s", method, path, w.Body.String())
+	}
+	return w.Code, resp
+}
+
+func decodeDnsData(t *testing.T, resp dnsTestResponse, v interface{}) {
+	t.Helper()
+	if err := json.Unmarshal(resp.Data, v); err != nil {
+		t.Fatalf("解析data失败: %v, data = %s", err, resp.Data)
+	}
+}
+
+func TestDnsRecordLifecycle(t *testing.T) {
+	r := newDnsTestRouter()
+
+	// 创建
+	status, resp := doDns(t, r, http.MethodPost, "/dns/records", url.Values{
+		"domain_id":   {"example.com"},
+		"sub_domain":  {"www"},
+		"record_type": {"A"},
+		"value":       {"192.0.2.1"},
+		"remark":      {"web server"},
+	}, nil)
+	if status != http.StatusOK || resp.Code != e.SUCCESS {
+		t.Fatalf("创建记录: %d %+v", status, resp)
+	}
+	var created dns.Record
+	decodeDnsData(t, resp, &created)
+	if created.ID == "" || created.Value != "192.0.2.1" || created.Remark != "web server" {
+		t.Fatalf("创建的记录 = %+v", created)
+	}
+
+	// 查询
+	status, resp = doDns(t, r, http.MethodGet, "/dns/records/"+created.ID, url.Values{"domain_id": {"example.com"}}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("查询记录: %d %+v", status, resp)
+	}
+
+	// 同名CNAME与A记录冲突
+	status, resp = doDns(t, r, http.MethodPost, "/dns/records", url.Values{
+		"domain_id":   {"example.com"},
+		"sub_domain":  {"www"},
+		"record_type": {"CNAME"},
+		"value":       {"example.net"},
+	}, nil)
+	if status != http.StatusConflict || resp.Code != e.ERROR_DNS_CONFLICT {
+		t.Errorf("CNAME冲突: %d %+v", status, resp)
+	}
+
+	// 修改
+	status, resp = doDns(t, r, http.MethodPut, "/dns/records/"+created.ID, url.Values{
+		"domain_id":   {"example.com"},
+		"sub_domain":  {"www"},
+		"record_type": {"A"},
+		"value":       {"192.0.2.2"},
+		"ttl":         {"300"},
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("修改记录: %d %+v", status, resp)
+	}
+	var updated dns.Record
+	decodeDnsData(t, resp, &updated)
+	if updated.Value != "192.0.2.2" || updated.TTL != 300 || updated.Remark != "web server" {
+		t.Errorf("修改后的记录 = %+v, 未传remark时应保留备注", updated)
+	}
+
+	// remark=清除备注
+	status, resp = doDns(t, r, http.MethodPut, "/dns/records/"+created.ID, url.Values{
+		"domain_id":   {"example.com"},
+		"sub_domain":  {"www"},
+		"record_type": {"A"},
+		"value":       {"192.0.2.2"},
+		"ttl":         {"300"},
+		"remark":      {""},
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("清除备注: %d %+v", status, resp)
+	}
+	updated = dns.Record{}
+	decodeDnsData(t, resp, &updated)
+	if updated.Remark != "" {
+		t.Errorf("remark=后的备注 = %q", updated.Remark)
+	}
+
+	// 暂停
+	status, resp = doDns(t, r, http.MethodPut, "/dns/records/"+created.ID+"/status", url.Values{
+		"domain_id": {"example.com"},
+		"status":    {dns.RecordStatusDisable},
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("暂停记录: %d %+v", status, resp)
+	}
+
+	// 列表
+	status, resp = doDns(t, r, http.MethodGet, "/dns/records", url.Values{"domain": {"example.com"}, "sub_domain": {"www"}}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("获取记录列表: %d %+v", status, resp)
+	}
+	var records []dns.Record
+	decodeDnsData(t, resp, &records)
+	if len(records) != 1 || records[0].Value != "192.0.2.2" || records[0].Status != dns.RecordStatusDisable {
+		t.Errorf("记录列表 = %+v", records)
+	}
+
+	// 删除后再查询返回404
+	status, resp = doDns(t, r, http.MethodDelete, "/dns/records/"+created.ID, url.Values{"domain_id": {"example.com"}}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("删除记录: %d %+v", status, resp)
+	}
+	status, resp = doDns(t, r, http.MethodGet, "/dns/records/"+created.ID, url.Values{"domain_id": {"example.com"}}, nil)
+	if status != http.StatusNotFound || resp.Code != e.ERROR_DNS_NOT_FOUND {
+		t.Errorf("查询已删除的记录: %d %+v", status, resp)
+	}
+}
+
+func TestDnsRecordErrors(t *testing.T) {
+	r := newDnsTestRouter()
+
+	tests := []struct {
+		name   string
+		query  url.Values
+		status int
+		code   int
+	}{
+		{
+			name:   "参数不完整",
+			query:  url.Values{"domain_id": {"example.com"}, "record_type": {"A"}},
+			status: http.StatusBadRequest,
+			code:   e.INVALID_PARAMS,
+		},
+		{
+			name:   "记录值格式错误",
+			query:  url.Values{"domain_id": {"example.com"}, "sub_domain": {"bad"}, "record_type": {"A"}, "value": {"not-an-ip"}},
+			status: http.StatusBadRequest,
+			code:   e.ERROR_DNS_INVALID_PARAM,
+		},
+		{
+			name:   "域名不存在",
+			query:  url.Values{"domain_id": {"missing.com"}, "sub_domain": {"www"}, "record_type": {"A"}, "value": {"192.0.2.1"}},
+			status: http.StatusNotFound,
+			code:   e.ERROR_DNS_NOT_FOUND,
+		},
+		{
+			name:   "账号未配置",
+			query:  url.Values{"provider": {"unknown"}, "domain_id": {"example.com"}, "sub_domain": {"www"}, "record_type": {"A"}, "value": {"192.0.2.1"}},
+			status: http.StatusBadRequest,
+			code:   e.ERROR_DNS_INVALID_PARAM,
+		},
+		{
+			name:   "服务商返回错误",
+			query:  url.Values{"provider": {"broken"}, "domain_id": {"example.com"}, "sub_domain": {"www"}, "record_type": {"A"}, "value": {"192.0.2.1"}},
+			status: http.StatusInternalServerError,
+			code:   e.ERROR,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := doDns(t, r, http.MethodPost, "/dns/records", tt.query, nil)
+			if status != tt.status || resp.Code != tt.code {
+				t.Errorf("返回 %d %+v, 期望 %d 业务码 %d", status, resp, tt.status, tt.code)
+			}
+		})
+	}
+}
+
+func TestDnsBatchRecords(t *testing.T) {
+	r := newDnsTestRouter()
+
+	records := []map[string]interface{}{
+		{"domain_id": "example.org", "name": "a", "type": "A", "value": "192.0.2.1"},
+		{"domain_id": "example.org", "name": "b", "type": "A", "value": "192.0.2.2"},
+		{"domain_id": "example.org", "name": "c", "type": "A"},
+	}
+	status, resp := doDns(t, r, http.MethodPost, "/dns/records/batch", nil, records)
+	if status != http.StatusOK {
+		t.Fatalf("批量创建: %d %+v", status, resp)
+	}
+	var created struct {
+		Results []struct {
+			Success bool       `json:"success"`
+			Data    dns.Record `json:"data"`
+		} `json:"results"`
+		Total   int `json:"total"`
+		Success int `json:"success"`
+	}
+	decodeDnsData(t, resp, &created)
+	if created.Total != 3 || created.Success != 2 || created.Results[2].Success {
+		t.Fatalf("批量创建结果 = %+v", created)
+	}
+
+	var deletes []map[string]interface{}
+	for _, result := range created.Results[:2] {
+		deletes = append(deletes, map[string]interface{}{"domain_id": "example.org", "id": result.Data.ID})
+	}
+	status, resp = doDns(t, r, http.MethodDelete, "/dns/records/batch", nil, deletes)
+	if status != http.StatusOK {
+		t.Fatalf("批量删除: %d %+v", status, resp)
+	}
+	var deleted struct {
+		Success int `json:"success"`
+	}
+	decodeDnsData(t, resp, &deleted)
+	if deleted.Success != 2 {
+		t.Errorf("批量删除成功 %d 条，期望2条: %s", deleted.Success, resp.Data)
+	}
+
+	// 账号未配置时与单条接口一样返回400
+	status, resp = doDns(t, r, http.MethodPost, "/dns/records/batch", url.Values{"provider": {"unknown"}}, records)
+	if status != http.StatusBadRequest || resp.Code != e.ERROR_DNS_INVALID_PARAM {
+		t.Errorf("账号未配置: %d %+v", status, resp)
+	}
+}
+
+func TestDnsRecordPage(t *testing.T) {
+	r := newDnsTestRouter()
+
+	var ids []string
+	for _, value := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		status, resp := doDns(t, r, http.MethodPost, "/dns/records", url.Values{
+			"domain_id":   {"example.org"},
+			"sub_domain":  {"paged"},
+			"record_type": {"A"},
+			"value":       {value},
+		}, nil)
+		if status != http.StatusOK {
+			t.Fatalf("创建记录: %d %+v", status, resp)
+		}
+		var created dns.Record
+		decodeDnsData(t, resp, &created)
+		ids = append(ids, created.ID)
+	}
+	defer func() {
+		for _, id := range ids {
+			doDns(t, r, http.MethodDelete, "/dns/records/"+id, url.Values{"domain_id": {"example.org"}}, nil)
+		}
+	}()
+
+	var page struct {
+		Lists    []dns.Record `json:"lists"`
+		Total    int          `json:"total"`
+		Page     int          `json:"page"`
+		PageSize int          `json:"page_size"`
+	}
+	status, resp := doDns(t, r, http.MethodGet, "/dns/records", url.Values{
+		"domain":     {"example.org"},
+		"sub_domain": {"paged"},
+		"page":       {"2"},
+		"page_size":  {"2"},
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("按页查询: %d %+v", status, resp)
+	}
+	decodeDnsData(t, resp, &page)
+	if page.Total != 3 || page.Page != 2 || page.PageSize != 2 || len(page.Lists) != 1 {
+		t.Errorf("第2页 = %+v", page)
+	}
+
+	// 超出最后一页返回空列表
+	status, resp = doDns(t, r, http.MethodGet, "/dns/records", url.Values{
+		"domain":     {"example.org"},
+		"sub_domain": {"paged"},
+		"page":       {"3"},
+		"page_size":  {"2"},
+	}, nil)
+	decodeDnsData(t, resp, &page)
+	if status != http.StatusOK || page.Total != 3 || len(page.Lists) != 0 {
+		t.Errorf("第3页: %d %+v", status, page)
+	}
+}
+
+func TestDnsErrorStatus(t *testing.T) {
+	tests := []struct {
+		kind   error
+		status int
+		code   int
+	}{
+		{dns.ErrNotFound, http.StatusNotFound, e.ERROR_DNS_NOT_FOUND},
+		{dns.ErrAlreadyExists, http.StatusConflict, e.ERROR_DNS_ALREADY_EXISTS},
+		{dns.ErrConflict, http.StatusConflict, e.ERROR_DNS_CONFLICT},
+		{dns.ErrAuthFailed, http.StatusBadGateway, e.ERROR_DNS_AUTH_FAILED},
+		{dns.ErrThrottled, http.StatusTooManyRequests, e.ERROR_DNS_THROTTLED},
+		{dns.ErrInvalidParam, http.StatusBadRequest, e.ERROR_DNS_INVALID_PARAM},
+		{dns.ErrQuotaExceeded, http.StatusForbidden, e.ERROR_DNS_QUOTA_EXCEEDED},
+		{dns.ErrUnsupported, http.StatusNotImplemented, e.ERROR_DNS_UNSUPPORTED},
+		{dns.ErrTimeout, http.StatusGatewayTimeout, e.ERROR_DNS_TIMEOUT},
+		{dns.ErrCanceled, StatusClientClosedRequest, e.ERROR_DNS_CANCELED},
+		{nil, http.StatusInternalServerError, e.ERROR},
+	}
+	for _, tt := range tests {
+		// 服务商错误经过models层包装后仍能识别类别
+		err := fmt.Errorf("修改记录失败: %w", &dns.APIError{Kind: tt.kind, Code: "Vendor.Code", Message: "test", RequestID: "req-1"})
+		status, code := dnsErrorStatus(err)
+		if status != tt.status || code != tt.code {
+			t.Errorf("%v 对应 %d/%d, 期望 %d/%d", tt.kind, status, code, tt.status, tt.code)
+		}
+		data := dnsErrorData(err)
+		if data["provider_code"] != "Vendor.Code" || data["request_id"] != "req-1" {
+			t.Errorf("%v 的错误数据 = %v", tt.kind, data)
+		}
+	}
+
+	if status, code := dnsErrorStatus(errors.New("unknown")); status != http.StatusInternalServerError || code != e.ERROR {
+		t.Errorf("普通错误对应 %d/%d", status, code)
+	}
+	if data := dnsErrorData(errors.New("unknown")); len(data) != 0 {
+		t.Errorf("普通错误的错误数据 = %v", data)
+	}
+}
+
+func TestDnsRecordIDN(t *testing.T) {
+	r := newDnsTestRouter()
+
+	// 主机记录、域名和CNAME记录值都转换为ASCII形式
+	status, resp := doDns(t, r, http.MethodPost, "/dns/records", url.Values{
+		"domain_id":   {"EXAMPLE.com."},
+		"sub_domain":  {"中文"},
+		"record_type": {"CNAME"},
+		"value":       {"例子.com"},
+	}, nil)
+	if status != http.StatusOK {
+		t.Fatalf("创建记录: %d %+v", status, resp)
+	}
+	var created dns.Record
+	decodeDnsData(t, resp, &created)
+	defer doDns(t, r, http.MethodDelete, "/dns/records/"+created.ID, url.Values{"domain_id": {"example.com"}}, nil)
+	if created.Name != "xn--fiq228c" || created.Value != "xn--fsqu00a.com" {
+		t.Errorf("创建的记录 = %+v", created)
+	}
+
+	// 按Unicode形式的主机记录查询
+	status, resp = doDns(t, r, http.MethodGet, "/dns/records", url.Values{"domain": {"example.com"}, "sub_domain": {"中文"}}, nil)
+	var records []dns.Record
+	decodeDnsData(t, resp, &records)
+	if status != http.StatusOK || len(records) != 1 || records[0].ID != created.ID {
+		t.Errorf("按Unicode主机记录查询: %d %+v", status, records)
+	}
+
+	status, resp = doDns(t, r, http.MethodPost, "/dns/records", url.Values{
+		"domain_id":   {"example.com"},
+		"sub_domain":  {"xn--zz"},
+		"record_type": {"A"},
+		"value":       {"192.0.2.1"},
+	}, nil)
+	if status != http.StatusBadRequest || resp.Code != e.ERROR_DNS_INVALID_PARAM {
+		t.Errorf("无效的主机记录: %d %+v", status, resp)
+	}
+}
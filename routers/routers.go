@@ -23,9 +23,15 @@ func InitRouter() *gin.Engine {
 		apiV1.PUT("/tags/:id", v1.EditTag)
 		apiV1.DELETE("/tags/:id", v1.DeleteTag)
 
-		// DNSPod API路由
+		// DNS服务商API路由，provider参数为账号名称
+		apiV1.GET("/dns/providers", v1.GetDnsProviders)
 		apiV1.GET("/domains", v1.GetDomains)
+		apiV1.POST("/domains", v1.CreateDomain)
+		apiV1.PUT("/domains/:id", v1.UpdateDomain)
+		apiV1.DELETE("/domains/:id", v1.DeleteDomain)
+		apiV1.GET("/dns/lines", v1.GetDnsLines)
 		apiV1.GET("/dns/records", v1.GetDnsRecords)
+		apiV1.GET("/dns/records/:id", v1.GetDnsRecord)
 		apiV1.POST("/dns/records", v1.CreateDnsRecord)
 		apiV1.PUT("/dns/records/:id", v1.UpdateDnsRecord)
 		apiV1.DELETE("/dns/records/:id", v1.DeleteDnsRecord)
@@ -36,6 +42,15 @@ func InitRouter() *gin.Engine {
 		apiV1.POST("/dns/domains", v1.AddDnsDomain)
 		apiV1.PUT("/dns/domains/:id", v1.UpdateDnsDomain)
 		apiV1.DELETE("/dns/domains/:id", v1.DeleteDnsDomain)
+		apiV1.POST("/dns/domains/import", v1.ImportDnsDomains)
+		apiV1.POST("/dns/domains/:id/import", v1.ImportDnsDomain)
+		apiV1.GET("/dns/domains/:id/drift", v1.GetDnsDomainDrift)
+		apiV1.GET("/dns/drifts", v1.GetDnsDrifts)
+		apiV1.POST("/dns/domains/:id/plan", v1.PlanDnsDomain)
+		apiV1.POST("/dns/domains/:id/apply", v1.ApplyDnsPlan)
+		apiV1.GET("/dns/plans/:id", v1.GetDnsPlan)
+		apiV1.POST("/dns/migrations", v1.CreateDnsMigration)
+		apiV1.GET("/dns/migrations/:id", v1.GetDnsMigration)
 		apiV1.GET("/dns/records_db", v1.GetDnsRecordsDb)
 		apiV1.POST("/dns/records_db", v1.AddDnsRecordDb)
 		apiV1.PUT("/dns/records_db/:id", v1.UpdateDnsRecordDb)